@@ -0,0 +1,50 @@
+package main
+
+import (
+	"ainopay-server/internal/config"
+	"ainopay-server/internal/database"
+	"ainopay-server/internal/repositories"
+	"flag"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// rebuild-summaries recomputes the daily payment summaries dashboards read
+// from, for one user or for all users. Payments cannot change while it runs.
+func main() {
+	userFlag := flag.String("user", "", "only rebuild the summaries of this user ID")
+	flag.Parse()
+
+	var userID *uuid.UUID
+	if *userFlag != "" {
+		id, err := uuid.Parse(*userFlag)
+		if err != nil {
+			log.Fatal("Invalid user ID:", err)
+		}
+		userID = &id
+	}
+
+	// Load configuration
+	cfg := config.Load()
+
+	// Connect to database
+	if err := database.Connect(&cfg.Database); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	// Run migrations
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return repositories.NewPaymentSummaryRepository(tx).Rebuild(userID)
+	})
+	if err != nil {
+		log.Fatal("Failed to rebuild payment summaries:", err)
+	}
+
+	log.Println("Payment summaries rebuilt successfully")
+}
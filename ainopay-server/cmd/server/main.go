@@ -3,11 +3,20 @@ package main
 import (
 	"ainopay-server/internal/config"
 	"ainopay-server/internal/database"
+	"ainopay-server/internal/graph"
 	"ainopay-server/internal/handlers"
+	handlersv2 "ainopay-server/internal/handlers/v2"
 	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/providers"
+	"ainopay-server/internal/realtime"
 	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/rpc"
 	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
 	"log"
+	"net"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -38,6 +47,9 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
 
 	// Set Gin mode
 	gin.SetMode(cfg.Server.GinMode)
@@ -60,25 +72,91 @@ func main() {
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(database.DB)
 	paymentRepo := repositories.NewPaymentRepository(database.DB)
+	summaryRepo := repositories.NewPaymentSummaryRepository(database.DB)
 	categoryRepo := repositories.NewCategoryRepository(database.DB)
 	paymentMethodRepo := repositories.NewPaymentMethodRepository(database.DB)
 	refreshTokenRepo := repositories.NewRefreshTokenRepository(database.DB)
 	passwordResetRepo := repositories.NewPasswordResetRepository(database.DB)
+	invoiceRepo := repositories.NewInvoiceRepository(database.DB)
+	reconciliationRepo := repositories.NewReconciliationRepository(database.DB)
+	webhookRepo := repositories.NewWebhookRepository(database.DB)
+	outboxRepo := repositories.NewOutboxRepository(database.DB)
+	ledgerRepo := repositories.NewLedgerRepository(database.DB)
+	accountRepo := repositories.NewAccountRepository(database.DB)
+	spendingLimitRepo := repositories.NewSpendingLimitRepository(database.DB)
+	riskRuleRepo := repositories.NewRiskRuleRepository(database.DB)
+	categorizationRuleRepo := repositories.NewCategorizationRuleRepository(database.DB)
+	taxCodeRepo := repositories.NewTaxCodeRepository(database.DB)
+	reportSubscriptionRepo := repositories.NewReportSubscriptionRepository(database.DB)
+	notificationRepo := repositories.NewNotificationRepository(database.DB)
 
 	// Start cleanup of expired refresh tokens
 	refreshTokenRepo.CleanupExpiredTokens()
 
 	// Initialize services
 	emailService := services.NewEmailService()
-	authService := services.NewAuthService(userRepo, refreshTokenRepo, passwordResetRepo, emailService, cfg)
-	paymentService := services.NewPaymentService(paymentRepo)
+	outboxService := services.NewOutboxService(outboxRepo, cfg)
+	authService := services.NewAuthService(userRepo, refreshTokenRepo, passwordResetRepo, summaryRepo, emailService, outboxService, cfg)
+	webhookService := services.NewWebhookService(webhookRepo, cfg)
+	ledgerService := services.NewLedgerService(ledgerRepo, paymentRepo)
+	accountService := services.NewAccountService(accountRepo, ledgerService)
+	spendingLimitService := services.NewSpendingLimitService(spendingLimitRepo, userRepo)
+	riskService := services.NewRiskService(riskRuleRepo, userRepo, cfg)
+	categorizationService := services.NewCategorizationService(categorizationRuleRepo, paymentRepo)
+	taxService := services.NewTaxService(taxCodeRepo)
+	paymentService := services.NewPaymentService(paymentRepo, outboxService, ledgerService, accountService, spendingLimitService, riskService, categorizationService, taxService, cfg)
+	invoiceService := services.NewInvoiceService(invoiceRepo, paymentRepo)
+	reconciliationService := services.NewReconciliationService(reconciliationRepo, paymentRepo, paymentService)
+	reportService := services.NewReportService(paymentRepo, summaryRepo, userRepo)
+	reportSubscriptionService := services.NewReportSubscriptionService(reportSubscriptionRepo, userRepo, reportService, emailService)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, emailService)
+	realtimeService := services.NewRealtimeService(realtime.NewMemoryBroker())
+	gatewayService := services.NewGatewayService(paymentRepo, paymentService, providers.NewDefaultRegistry(cfg), cfg)
+
+	// Register outbox event handlers
+	for _, eventType := range models.WebhookEventTypes {
+		outboxService.Subscribe(eventType, webhookService.HandleOutboxEvent)
+	}
+	outboxService.Subscribe(models.EventPasswordResetRequested, authService.HandlePasswordResetRequested)
+	outboxService.Subscribe(models.EventPaymentCreated, notificationService.HandlePaymentCreated)
+	outboxService.Subscribe(models.EventPaymentUpdated, notificationService.HandlePaymentUpdated)
+	outboxService.Subscribe(models.EventPaymentStatusChanged, notificationService.HandlePaymentStatusChanged)
+	for _, eventType := range models.WebhookEventTypes {
+		outboxService.Subscribe(eventType, realtimeService.HandlePaymentEvent)
+	}
+
+	// Start relaying outbox events, delivery of queued webhooks and summary emails
+	outboxService.StartRelay()
+	webhookService.StartWorker()
+	reportSubscriptionService.StartScheduler()
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	paymentHandler := handlers.NewPaymentHandler(paymentService)
 	categoryHandler := handlers.NewCategoryHandler(categoryRepo)
-	dashboardHandler := handlers.NewDashboardHandler(paymentService, paymentRepo)
+	dashboardHandler := handlers.NewDashboardHandler(paymentService, reportService, paymentRepo)
 	paymentMethodHandler := handlers.NewPaymentMethodHandler(paymentMethodRepo)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+	statementHandler := handlers.NewStatementHandler()
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	gatewayHandler := handlers.NewGatewayHandler(gatewayService)
+	ledgerHandler := handlers.NewLedgerHandler(ledgerService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	spendingLimitHandler := handlers.NewSpendingLimitHandler(spendingLimitService)
+	riskHandler := handlers.NewRiskHandler(riskService, paymentService)
+	categorizationHandler := handlers.NewCategorizationHandler(categorizationService, paymentService)
+	reportHandler := handlers.NewReportHandler(reportService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	reportSubscriptionHandler := handlers.NewReportSubscriptionHandler(reportSubscriptionService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	eventHandler := handlers.NewEventHandler(realtimeService, reportService)
+	graphqlHandler := handlers.NewGraphQLHandler(graph.NewServer(paymentService, reportService, paymentRepo, categoryRepo, paymentMethodRepo, accountRepo, taxCodeRepo))
+
+	// Initialize v2 handlers
+	paymentHandlerV2 := handlersv2.NewPaymentHandler(paymentService, paymentRepo)
+	categoryHandlerV2 := handlersv2.NewCategoryHandler(categoryRepo, paymentMethodRepo)
+	dashboardHandlerV2 := handlersv2.NewDashboardHandler(reportService)
 
 	// Setup router
 	router := gin.Default()
@@ -90,7 +168,14 @@ func main() {
 	// Global middleware
 	router.Use(middleware.CORSMiddleware(cfg.CORS.AllowedOrigins))
 	router.Use(middleware.LoggerMiddleware())
-	router.Use(middleware.RateLimitMiddleware(rateLimiter))
+	router.Use(middleware.RateLimitMiddlewareWith(rateLimiter, func(c *gin.Context, statusCode int, message string) {
+		// Answer in the error envelope of the API version
+		if path := c.Request.URL.Path; path == "/api/v2" || strings.HasPrefix(path, "/api/v2/") {
+			handlersv2.ErrorResponse(c, statusCode, message)
+			return
+		}
+		utils.ErrorResponse(c, statusCode, message)
+	}))
 
 	// Health check
 	router.GET("/health", func(c *gin.Context) {
@@ -101,7 +186,12 @@ func main() {
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 
-	// API routes
+	// API routes (v1)
+	// v1 routes superseded by /api/v2 announce their deprecation
+	deprecatedBy := func(successor string) gin.HandlerFunc {
+		return middleware.DeprecationMiddleware(cfg, successor)
+	}
+
 	api := router.Group("/api")
 	{
 		// Auth routes (public)
@@ -113,44 +203,266 @@ func main() {
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
 			auth.GET("/me", middleware.AuthMiddleware(cfg), authHandler.GetMe)
+			auth.PATCH("/me", middleware.AuthMiddleware(cfg), authHandler.UpdateMe)
 		}
 
+		// Payment provider callbacks (public, verified by provider signature)
+		api.POST("/callbacks/:provider", gatewayHandler.Callback)
+
+		// Real-time event stream, which also accepts the token in the query
+		// since EventSource cannot set headers
+		api.GET("/events", middleware.QueryTokenMiddleware(), middleware.AuthMiddleware(cfg), middleware.TimezoneMiddleware(authService.GetLocation), eventHandler.Stream)
+
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg))
+		protected.Use(middleware.AuthMiddleware(cfg), middleware.TimezoneMiddleware(authService.GetLocation))
 		{
 			// Payment routes
 			payments := protected.Group("/payments")
 			{
-				payments.POST("", paymentHandler.Create)
+				payments.POST("", deprecatedBy("/api/v2/payments"), paymentHandler.Create)
 				payments.GET("/export", paymentHandler.Export)
-				payments.GET("", paymentHandler.GetAll)
-				payments.GET("/:id", paymentHandler.GetByID)
-				payments.PUT("/:id", paymentHandler.Update)
-				payments.DELETE("/:id", paymentHandler.Delete)
+				payments.POST("/import", paymentHandler.Import)
+				payments.GET("/duplicates", paymentHandler.GetDuplicates)
+				payments.GET("", deprecatedBy("/api/v2/payments"), paymentHandler.GetAll)
+				payments.GET("/:id", deprecatedBy("/api/v2/payments/{id}"), paymentHandler.GetByID)
+				payments.PUT("/:id", deprecatedBy("/api/v2/payments/{id}"), paymentHandler.Update)
+				payments.DELETE("/:id", deprecatedBy("/api/v2/payments/{id}"), paymentHandler.Delete)
+				payments.POST("/:id/charge", gatewayHandler.Charge)
+				payments.POST("/:id/sync", gatewayHandler.Sync)
+				payments.POST("/:id/refund", gatewayHandler.Refund)
+			}
+
+			// Invoice routes
+			invoices := protected.Group("/invoices")
+			{
+				invoices.POST("", invoiceHandler.Create)
+				invoices.GET("/overdue", invoiceHandler.GetOverdue)
+				invoices.GET("", invoiceHandler.GetAll)
+				invoices.GET("/:id", invoiceHandler.GetByID)
+				invoices.PUT("/:id", invoiceHandler.Update)
+				invoices.DELETE("/:id", invoiceHandler.Delete)
+				invoices.POST("/:id/payments", invoiceHandler.ApplyPayment)
+				invoices.DELETE("/:id/payments/:payment_id", invoiceHandler.RemovePayment)
+			}
+
+			// Statement routes
+			statementRoutes := protected.Group("/statements")
+			{
+				statementRoutes.POST("/parse", statementHandler.Parse)
+			}
+
+			// Reconciliation routes
+			reconciliations := protected.Group("/reconciliations")
+			{
+				reconciliations.POST("", reconciliationHandler.Create)
+				reconciliations.GET("", reconciliationHandler.GetAll)
+				reconciliations.GET("/:id", reconciliationHandler.GetByID)
+				reconciliations.POST("/:id/complete", reconciliationHandler.Complete)
+				reconciliations.POST("/:id/lines/:line_id/confirm", reconciliationHandler.ConfirmLine)
+				reconciliations.POST("/:id/lines/:line_id/reject", reconciliationHandler.RejectLine)
+				reconciliations.POST("/:id/lines/:line_id/create-payment", reconciliationHandler.CreatePayment)
+			}
+
+			// Webhook routes
+			webhooks := protected.Group("/webhooks")
+			{
+				webhooks.POST("", webhookHandler.Create)
+				webhooks.GET("", webhookHandler.GetAll)
+				webhooks.GET("/deliveries", webhookHandler.GetDeliveries)
+				webhooks.GET("/deliveries/dead-letters", webhookHandler.GetDeadLetters)
+				webhooks.GET("/deliveries/:delivery_id", webhookHandler.GetDelivery)
+				webhooks.POST("/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+				webhooks.GET("/:id", webhookHandler.GetByID)
+				webhooks.PUT("/:id", webhookHandler.Update)
+				webhooks.DELETE("/:id", webhookHandler.Delete)
 			}
 
 			// Category routes
 			categories := protected.Group("/categories")
 			{
-				categories.GET("", categoryHandler.GetAll)
+				categories.GET("", deprecatedBy("/api/v2/categories"), categoryHandler.GetAll)
 			}
 
 			// Payment method routes
 			paymentMethods := protected.Group("/payment-methods")
 			{
-				paymentMethods.GET("", paymentMethodHandler.GetAll)
+				paymentMethods.GET("", deprecatedBy("/api/v2/payment-methods"), paymentMethodHandler.GetAll)
+			}
+
+			// Tax code routes
+			protected.GET("/tax-codes", taxHandler.GetCodes)
+
+			// Account routes
+			accounts := protected.Group("/accounts")
+			{
+				accounts.POST("", accountHandler.Create)
+				accounts.GET("", accountHandler.GetAll)
+				accounts.GET("/:id", accountHandler.GetByID)
+				accounts.PUT("/:id", accountHandler.Update)
+				accounts.DELETE("/:id", accountHandler.Delete)
+				accounts.GET("/:id/balance-history", accountHandler.GetBalanceHistory)
+			}
+
+			// Transfer routes
+			transfers := protected.Group("/transfers")
+			{
+				transfers.POST("", accountHandler.CreateTransfer)
+				transfers.GET("", accountHandler.GetTransfers)
+				transfers.DELETE("/:id", accountHandler.DeleteTransfer)
+			}
+
+			// Ledger routes
+			ledger := protected.Group("/ledger")
+			{
+				ledger.GET("/accounts", ledgerHandler.GetAccounts)
+				ledger.POST("/accounts", ledgerHandler.CreateAccount)
+				ledger.GET("/entries", ledgerHandler.GetEntries)
+				ledger.POST("/entries", ledgerHandler.CreateEntry)
+				ledger.GET("/entries/:id", ledgerHandler.GetEntry)
+				ledger.GET("/trial-balance", ledgerHandler.GetTrialBalance)
+				ledger.GET("/check", ledgerHandler.Check)
+				ledger.POST("/sync", ledgerHandler.Sync)
 			}
 
+			// Spending limit routes
+			spendingLimits := protected.Group("/spending-limits")
+			{
+				spendingLimits.POST("", spendingLimitHandler.Create)
+				spendingLimits.GET("", spendingLimitHandler.GetAll)
+				spendingLimits.GET("/usage", spendingLimitHandler.GetUsage)
+				spendingLimits.PUT("/:id", spendingLimitHandler.Update)
+				spendingLimits.DELETE("/:id", spendingLimitHandler.Delete)
+			}
+
+			// Categorization rule routes
+			categorizationRules := protected.Group("/categorization-rules")
+			{
+				categorizationRules.POST("", categorizationHandler.Create)
+				categorizationRules.GET("", categorizationHandler.GetAll)
+				categorizationRules.POST("/preview", categorizationHandler.Preview)
+				categorizationRules.POST("/apply", categorizationHandler.Apply)
+				categorizationRules.PUT("/:id", categorizationHandler.Update)
+				categorizationRules.DELETE("/:id", categorizationHandler.Delete)
+			}
+
+			// Admin routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.AdminMiddleware())
+			{
+				userLimits := admin.Group("/users/:user_id/spending-limits")
+				{
+					userLimits.POST("", spendingLimitHandler.Create)
+					userLimits.GET("", spendingLimitHandler.GetAll)
+					userLimits.GET("/usage", spendingLimitHandler.GetUsage)
+					userLimits.PUT("/:id", spendingLimitHandler.Update)
+					userLimits.DELETE("/:id", spendingLimitHandler.Delete)
+				}
+
+				riskRules := admin.Group("/risk-rules")
+				{
+					riskRules.POST("", riskHandler.CreateRule)
+					riskRules.GET("", riskHandler.GetRules)
+					riskRules.GET("/:id", riskHandler.GetRule)
+					riskRules.PUT("/:id", riskHandler.UpdateRule)
+					riskRules.DELETE("/:id", riskHandler.DeleteRule)
+				}
+
+				taxCodes := admin.Group("/tax-codes")
+				{
+					taxCodes.POST("", taxHandler.CreateCode)
+					taxCodes.GET("", taxHandler.GetAllCodes)
+					taxCodes.PUT("/:id", taxHandler.UpdateCode)
+					taxCodes.DELETE("/:id", taxHandler.DeleteCode)
+				}
+
+				adminPayments := admin.Group("/payments")
+				{
+					adminPayments.GET("/review", riskHandler.GetReviewQueue)
+					adminPayments.POST("/:id/approve", riskHandler.Approve)
+					adminPayments.POST("/:id/reject", riskHandler.Reject)
+				}
+			}
+
+			// Payment provider routes
+			protected.GET("/payment-providers", gatewayHandler.GetProviders)
+
 			// Dashboard routes
 			dashboard := protected.Group("/dashboard")
 			{
-				dashboard.GET("/stats", dashboardHandler.GetStats)
+				dashboard.GET("/stats", deprecatedBy("/api/v2/dashboard/stats"), dashboardHandler.GetStats)
 				dashboard.GET("/recent", dashboardHandler.GetRecent)
 				dashboard.GET("/chart", dashboardHandler.GetChartData)
+				dashboard.GET("/cash-flow", dashboardHandler.GetCashFlow)
+			}
+
+			// Report routes
+			reports := protected.Group("/reports")
+			{
+				reports.GET("/breakdown/:group", reportHandler.GetBreakdown)
+				reports.GET("/breakdown/:group/export", reportHandler.ExportBreakdown)
+				reports.GET("/forecast", reportHandler.GetForecast)
+				reports.GET("/tax-summary", reportHandler.GetTaxSummary)
+				reports.GET("/tax-summary/export", reportHandler.ExportTaxSummary)
 			}
+
+			// Report subscription routes
+			reportSubscriptions := protected.Group("/report-subscriptions")
+			{
+				reportSubscriptions.POST("", reportSubscriptionHandler.Create)
+				reportSubscriptions.GET("", reportSubscriptionHandler.GetAll)
+				reportSubscriptions.GET("/preview", reportSubscriptionHandler.Preview)
+				reportSubscriptions.PUT("/:id", reportSubscriptionHandler.Update)
+				reportSubscriptions.DELETE("/:id", reportSubscriptionHandler.Delete)
+			}
+
+			// Notification routes
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.GetAll)
+				notifications.GET("/unread-count", notificationHandler.GetUnreadCount)
+				notifications.POST("/read-all", notificationHandler.MarkAllRead)
+				notifications.GET("/preferences", notificationHandler.GetPreferences)
+				notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+				notifications.POST("/:id/read", notificationHandler.MarkRead)
+			}
+
+			// GraphQL
+			protected.POST("/graphql", graphqlHandler.Query)
+		}
+	}
+
+	// API v2 routes, with their own request and response types and errors
+	// in the v2 envelope
+	apiV2 := router.Group("/api/v2")
+	apiV2.Use(middleware.AuthMiddlewareWith(cfg, handlersv2.ErrorResponse), middleware.TimezoneMiddlewareWith(authService.GetLocation, handlersv2.ErrorResponse))
+	{
+		paymentsV2 := apiV2.Group("/payments")
+		{
+			paymentsV2.POST("", paymentHandlerV2.Create)
+			paymentsV2.GET("", paymentHandlerV2.GetAll)
+			paymentsV2.GET("/:id", paymentHandlerV2.GetByID)
+			paymentsV2.PATCH("/:id", paymentHandlerV2.Update)
+			paymentsV2.DELETE("/:id", paymentHandlerV2.Delete)
 		}
+
+		apiV2.GET("/categories", categoryHandlerV2.GetCategories)
+		apiV2.GET("/payment-methods", categoryHandlerV2.GetPaymentMethods)
+		apiV2.GET("/dashboard/stats", dashboardHandlerV2.GetStats)
+	}
+
+	// Start the gRPC server for internal clients on its own port
+	grpcServer := rpc.NewServer(cfg, paymentService, reportService, paymentRepo, categoryRepo, paymentMethodRepo, authService.GetLocation)
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+	if err != nil {
+		log.Fatal("Failed to listen for gRPC:", err)
 	}
+	go func() {
+		log.Printf("gRPC server starting on port %s", cfg.GRPC.Port)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatal("Failed to start gRPC server:", err)
+		}
+	}()
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Server.Port)
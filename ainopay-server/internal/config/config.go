@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -8,11 +9,21 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// DefaultSimulatedSecret signs the simulated provider's callbacks outside
+// release mode when SIMULATED_PROVIDER_SECRET is not set
+const DefaultSimulatedSecret = "simulated-provider-secret"
+
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	JWT      JWTConfig
 	CORS     CORSConfig
+	Payment  PaymentConfig
+	Webhook  WebhookConfig
+	Provider ProviderConfig
+	Outbox   OutboxConfig
+	GRPC     GRPCConfig
+	API      APIConfig
 }
 
 type ServerConfig struct {
@@ -38,13 +49,46 @@ type CORSConfig struct {
 	AllowedOrigins string
 }
 
+type WebhookConfig struct {
+	MaxAttempts  string
+	RetryBackoff string
+}
+
+type OutboxConfig struct {
+	MaxAttempts  string
+	RetryBackoff string
+}
+
+type GRPCConfig struct {
+	Port    string
+	APIKeys string // comma separated keys of internal clients, empty disables API-key auth
+}
+
+// APIConfig holds the deprecation schedule of the v1 REST API, as YYYY-MM-DD dates
+type APIConfig struct {
+	V1DeprecationDate string
+	V1SunsetDate      string // v1 may be removed after this date
+}
+
+type ProviderConfig struct {
+	CallbackBaseURL      string
+	SimulatedSecret      string // empty disables the simulated provider
+	SimulatedSettleAfter string
+}
+
+type PaymentConfig struct {
+	DuplicateWindow     string
+	DuplicateSimilarity string
+	RiskReviewScore     string
+}
+
 func Load() *Config {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using environment variables")
 	}
 
-	return &Config{
+	cfg := &Config{
 		Server: ServerConfig{
 			Port:    getEnv("PORT", "8080"),
 			GinMode: getEnv("GIN_MODE", "debug"),
@@ -64,7 +108,59 @@ func Load() *Config {
 		CORS: CORSConfig{
 			AllowedOrigins: getEnv("ALLOWED_ORIGINS", "http://localhost:3000"),
 		},
+		Payment: PaymentConfig{
+			DuplicateWindow:     getEnv("DUPLICATE_WINDOW", "72h"),
+			DuplicateSimilarity: getEnv("DUPLICATE_SIMILARITY", "0.6"),
+			RiskReviewScore:     getEnv("RISK_REVIEW_SCORE", "50"),
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:  getEnv("WEBHOOK_MAX_ATTEMPTS", "8"),
+			RetryBackoff: getEnv("WEBHOOK_RETRY_BACKOFF", "30s"),
+		},
+		Provider: ProviderConfig{
+			CallbackBaseURL:      getEnv("PROVIDER_CALLBACK_BASE_URL", "http://localhost:8080/api/callbacks"),
+			SimulatedSecret:      getEnv("SIMULATED_PROVIDER_SECRET", ""),
+			SimulatedSettleAfter: getEnv("SIMULATED_PROVIDER_SETTLE_AFTER", "5s"),
+		},
+		Outbox: OutboxConfig{
+			MaxAttempts:  getEnv("OUTBOX_MAX_ATTEMPTS", "10"),
+			RetryBackoff: getEnv("OUTBOX_RETRY_BACKOFF", "5s"),
+		},
+		GRPC: GRPCConfig{
+			Port:    getEnv("GRPC_PORT", "9090"),
+			APIKeys: getEnv("GRPC_API_KEYS", ""),
+		},
+		API: APIConfig{
+			V1DeprecationDate: getEnv("API_V1_DEPRECATION_DATE", "2026-10-19"),
+			V1SunsetDate:      getEnv("API_V1_SUNSET_DATE", "2027-04-30"),
+		},
+	}
+
+	// The simulated provider is only available in release mode when a secret
+	// is configured explicitly
+	if cfg.Provider.SimulatedSecret == "" && !cfg.Server.IsRelease() {
+		cfg.Provider.SimulatedSecret = DefaultSimulatedSecret
 	}
+
+	return cfg
+}
+
+// Validate rejects settings that are unsafe in release mode
+func (c *Config) Validate() error {
+	if c.Server.IsRelease() && c.Provider.SimulatedSecret == DefaultSimulatedSecret {
+		return errors.New("SIMULATED_PROVIDER_SECRET must not be the default secret in release mode")
+	}
+	return nil
+}
+
+// IsDebug reports whether the server runs in gin's debug mode
+func (c *ServerConfig) IsDebug() bool {
+	return c.GinMode == "debug"
+}
+
+// IsRelease reports whether the server runs in gin's release mode
+func (c *ServerConfig) IsRelease() bool {
+	return c.GinMode == "release"
 }
 
 func (c *DatabaseConfig) DSN() string {
@@ -3,6 +3,7 @@ package database
 import (
 	"ainopay-server/internal/config"
 	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
 	"fmt"
 	"log"
 
@@ -34,19 +35,51 @@ func Connect(cfg *config.DatabaseConfig) error {
 func Migrate() error {
 	log.Println("Running database migrations...")
 
+	// Summaries of existing payments are built once the table is created
+	backfillSummaries := !DB.Migrator().HasTable(&models.PaymentDailySummary{})
+
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Category{},
 		&models.PaymentMethod{},
+		&models.Invoice{},
+		&models.FinancialAccount{},
+		&models.TaxCode{},
 		&models.Payment{},
 		&models.RefreshToken{},
 		&models.PasswordResetToken{},
+		&models.ReconciliationSession{},
+		&models.StatementLine{},
+		&models.Transfer{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.WebhookDeliveryAttempt{},
+		&models.OutboxEvent{},
+		&models.LedgerAccount{},
+		&models.JournalEntry{},
+		&models.Posting{},
+		&models.SpendingLimit{},
+		&models.RiskRule{},
+		&models.CategorizationRule{},
+		&models.PaymentDailySummary{},
+		&models.ReportSubscription{},
+		&models.Notification{},
+		&models.NotificationPreference{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
+	if backfillSummaries {
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			return repositories.NewPaymentSummaryRepository(tx).Rebuild(nil)
+		})
+		if err != nil {
+			return fmt.Errorf("building payment summaries failed: %w", err)
+		}
+	}
+
 	log.Println("Database migration completed successfully")
 	return nil
 }
@@ -90,6 +123,23 @@ func Seed() error {
 		}
 	}
 
+	// Seed tax codes
+	taxCodes := []models.TaxCode{
+		{Code: "PPN11", Name: "PPN 11%", Rate: 11, Description: "Value added tax at the standard rate", IsActive: true},
+		{Code: "PPN12", Name: "PPN 12%", Rate: 12, Description: "Value added tax on luxury goods", IsActive: true},
+		{Code: "PPN0", Name: "PPN 0%", Rate: 0, Description: "Zero-rated exports", IsActive: true},
+		{Code: "EXEMPT", Name: "PPN exempt", Rate: 0, Description: "Goods and services exempt from value added tax", IsActive: true},
+	}
+
+	for _, tc := range taxCodes {
+		var existing models.TaxCode
+		if err := DB.Where("code = ?", tc.Code).First(&existing).Error; err == gorm.ErrRecordNotFound {
+			if err := DB.Create(&tc).Error; err != nil {
+				return fmt.Errorf("failed to seed tax code: %w", err)
+			}
+		}
+	}
+
 	log.Println("Database seeding completed successfully")
 	return nil
 }
@@ -0,0 +1,65 @@
+package graph
+
+import (
+	"ainopay-server/internal/services"
+	"errors"
+)
+
+// Codes set in the extensions of errors, matching the status codes the REST
+// API responds with
+const (
+	codeBadUserInput     = "BAD_USER_INPUT"          // 400
+	codeNotFound         = "NOT_FOUND"               // 404
+	codeConflict         = "CONFLICT"                // 409
+	codeDuplicatePayment = "DUPLICATE_PAYMENT"       // 409 with candidate_ids
+	codeSpendingLimit    = "SPENDING_LIMIT_EXCEEDED" // 422 with violations
+)
+
+// resolverError is an error reported to clients with a code and details in
+// its extensions
+type resolverError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func newError(code, message string) *resolverError {
+	return &resolverError{message: message, extensions: map[string]interface{}{"code": code}}
+}
+
+func (e *resolverError) Error() string {
+	return e.message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+// paymentError converts payment service errors
+func paymentError(err error) error {
+	var duplicateErr *services.DuplicatePaymentError
+	if errors.As(err, &duplicateErr) {
+		e := newError(codeDuplicatePayment, "Possible duplicate payment; resubmit with force: true to create it anyway")
+		e.extensions["candidate_ids"] = duplicateErr.CandidateIDs
+		return e
+	}
+	var limitErr *services.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		e := newError(codeSpendingLimit, err.Error())
+		e.extensions["violations"] = limitErr.Violations
+		return e
+	}
+
+	switch {
+	case errors.Is(err, services.ErrFinancialAccountNotFound), errors.Is(err, services.ErrFinancialAccountInactive),
+		errors.Is(err, services.ErrCategoryRequired), errors.Is(err, services.ErrTaxCodeNotFound),
+		errors.Is(err, services.ErrTaxCodeInactive), errors.Is(err, services.ErrInvalidTax):
+		return newError(codeBadUserInput, err.Error())
+	case errors.Is(err, services.ErrPaymentNotFound):
+		return newError(codeNotFound, err.Error())
+	case errors.Is(err, services.ErrPaymentInReview), errors.Is(err, services.ErrPaymentNotInReview),
+		errors.Is(err, services.ErrPaymentCharged):
+		return newError(codeConflict, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"ainopay-server/internal/models"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// loaders batch the lookups of the relations of payments made while one
+// request resolves, so that a page of payments costs one query per relation
+// rather than one per payment
+type loaders struct {
+	categories     *dataloader.Loader[uuid.UUID, *models.Category]
+	paymentMethods *dataloader.Loader[uuid.UUID, *models.PaymentMethod]
+	accounts       *dataloader.Loader[uuid.UUID, *models.FinancialAccount]
+	taxCodes       *dataloader.Loader[uuid.UUID, *models.TaxCode]
+}
+
+func (r *Resolver) newLoaders() *loaders {
+	return &loaders{
+		categories: newLoader(r.categoryRepo.FindByIDs, func(c *models.Category) uuid.UUID {
+			return c.ID
+		}),
+		paymentMethods: newLoader(r.paymentMethodRepo.FindByIDs, func(m *models.PaymentMethod) uuid.UUID {
+			return m.ID
+		}),
+		accounts: newLoader(r.accountRepo.FindByIDs, func(a *models.FinancialAccount) uuid.UUID {
+			return a.ID
+		}),
+		taxCodes: newLoader(r.taxCodeRepo.FindByIDs, func(t *models.TaxCode) uuid.UUID {
+			return t.ID
+		}),
+	}
+}
+
+// newLoader returns a loader that fetches a batch of keys with find and
+// matches the rows found to the keys by their ID
+func newLoader[V any](find func(ids []uuid.UUID) ([]V, error), id func(*V) uuid.UUID) *dataloader.Loader[uuid.UUID, *V] {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, keys []uuid.UUID) []*dataloader.Result[*V] {
+		results := make([]*dataloader.Result[*V], len(keys))
+
+		rows, err := find(keys)
+		if err != nil {
+			for i := range results {
+				results[i] = &dataloader.Result[*V]{Error: err}
+			}
+			return results
+		}
+
+		byID := make(map[uuid.UUID]*V, len(rows))
+		for i := range rows {
+			byID[id(&rows[i])] = &rows[i]
+		}
+		for i, key := range keys {
+			if row, ok := byID[key]; ok {
+				results[i] = &dataloader.Result[*V]{Data: row}
+			} else {
+				results[i] = &dataloader.Result[*V]{Error: fmt.Errorf("%s not found", key)}
+			}
+		}
+		return results
+	})
+}
@@ -0,0 +1,392 @@
+package graph
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/services"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// maxPageLimit caps the number of payments of one page
+const maxPageLimit = 100
+
+// Resolver resolves the fields of Query and Mutation
+type Resolver struct {
+	paymentService    *services.PaymentService
+	reportService     *services.ReportService
+	paymentRepo       *repositories.PaymentRepository
+	categoryRepo      *repositories.CategoryRepository
+	paymentMethodRepo *repositories.PaymentMethodRepository
+	accountRepo       *repositories.AccountRepository
+	taxCodeRepo       *repositories.TaxCodeRepository
+}
+
+type paymentFilterInput struct {
+	Status          *string
+	Direction       *string
+	Search          *string
+	MinAmount       *float64
+	MaxAmount       *float64
+	StartDate       *string
+	EndDate         *string
+	CategoryID      *graphql.ID
+	PaymentMethodID *graphql.ID
+	Reconciled      *bool
+}
+
+// toFilter converts the filter, which may be nil, with dates in loc
+func (f *paymentFilterInput) toFilter(loc *time.Location) (repositories.PaymentFilter, error) {
+	var filter repositories.PaymentFilter
+	if f == nil {
+		return filter, nil
+	}
+
+	filter.Status = stringValue(f.Status)
+	filter.Direction = stringValue(f.Direction)
+	filter.Search = stringValue(f.Search)
+	filter.MinAmount = f.MinAmount
+	filter.MaxAmount = f.MaxAmount
+	filter.Reconciled = f.Reconciled
+
+	if f.StartDate != nil {
+		t, err := time.ParseInLocation("2006-01-02", *f.StartDate, loc)
+		if err != nil {
+			return filter, newError(codeBadUserInput, "Invalid start date format")
+		}
+		filter.StartDate = &t
+	}
+	if f.EndDate != nil {
+		t, err := time.ParseInLocation("2006-01-02", *f.EndDate, loc)
+		if err != nil {
+			return filter, newError(codeBadUserInput, "Invalid end date format")
+		}
+		// Set to end of day
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		filter.EndDate = &t
+	}
+
+	var err error
+	if filter.CategoryID, err = parseOptionalID(f.CategoryID, "category"); err != nil {
+		return filter, err
+	}
+	if filter.PaymentMethodID, err = parseOptionalID(f.PaymentMethodID, "payment method"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
+func (r *Resolver) Payments(ctx context.Context, args struct {
+	Filter *paymentFilterInput
+	Page   int32
+	Limit  int32
+}) (*paymentPageResolver, error) {
+	req := requestFrom(ctx)
+
+	if args.Page < 1 || args.Limit < 1 || args.Limit > maxPageLimit {
+		return nil, newError(codeBadUserInput, "Page must be at least 1 and limit between 1 and 100")
+	}
+
+	filter, err := args.Filter.toFilter(req.loc)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = int(args.Limit)
+	filter.Offset = int((args.Page - 1) * args.Limit)
+
+	// Relations are batched by the payment resolvers instead of preloaded
+	payments, total, err := r.paymentRepo.FindAllWithoutRelations(req.userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &paymentPageResolver{payments: payments, total: total, page: args.Page, limit: args.Limit}, nil
+}
+
+func (r *Resolver) Payment(ctx context.Context, args struct{ ID graphql.ID }) (*paymentResolver, error) {
+	id, err := parseID(args.ID, "payment")
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := r.paymentService.GetOwned(requestFrom(ctx).userID, id)
+	if errors.Is(err, services.ErrPaymentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &paymentResolver{payment}, nil
+}
+
+func (r *Resolver) Categories() ([]*categoryResolver, error) {
+	categories, err := r.categoryRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*categoryResolver, len(categories))
+	for i := range categories {
+		resolvers[i] = &categoryResolver{&categories[i]}
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) PaymentMethods() ([]*paymentMethodResolver, error) {
+	methods, err := r.paymentMethodRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*paymentMethodResolver, len(methods))
+	for i := range methods {
+		resolvers[i] = &paymentMethodResolver{&methods[i]}
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) DashboardStats(ctx context.Context, args struct {
+	Preset  *string
+	From    *string
+	To      *string
+	Compare bool
+}) (*dashboardStatsResolver, error) {
+	req := requestFrom(ctx)
+
+	statsReq := &services.StatsRequest{Preset: stringValue(args.Preset), Compare: args.Compare, Location: req.loc}
+	if args.From != nil {
+		t, err := time.ParseInLocation("2006-01-02", *args.From, req.loc)
+		if err != nil {
+			return nil, newError(codeBadUserInput, "Invalid from date format")
+		}
+		statsReq.From = &t
+	}
+	if args.To != nil {
+		t, err := time.ParseInLocation("2006-01-02", *args.To, req.loc)
+		if err != nil {
+			return nil, newError(codeBadUserInput, "Invalid to date format")
+		}
+		statsReq.To = &t
+	}
+
+	stats, err := r.reportService.GetDashboardStats(req.userID, statsReq)
+	if errors.Is(err, services.ErrInvalidStatsPeriod) {
+		return nil, newError(codeBadUserInput, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &dashboardStatsResolver{statisticsResolver{&stats.PaymentStatistics}, stats}, nil
+}
+
+type paymentTaxInput struct {
+	TaxCodeID     *graphql.ID
+	Rate          *float64 `validate:"omitempty,gte=0,lte=100"`
+	Amount        *float64 `validate:"omitempty,gte=0"`
+	Inclusive     *bool
+	InvoiceNumber *string `validate:"omitempty,max=50"`
+}
+
+// toRequest converts the tax of a payment input, which may be nil
+func (t *paymentTaxInput) toRequest() (*services.PaymentTaxRequest, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	taxCodeID, err := parseOptionalID(t.TaxCodeID, "tax code")
+	if err != nil {
+		return nil, err
+	}
+
+	return &services.PaymentTaxRequest{
+		TaxCodeID:     taxCodeID,
+		Rate:          t.Rate,
+		Amount:        t.Amount,
+		Inclusive:     t.Inclusive,
+		InvoiceNumber: stringValue(t.InvoiceNumber),
+	}, nil
+}
+
+type createPaymentInput struct {
+	Amount          float64  `validate:"gt=0"`
+	Fee             *float64 `validate:"omitempty,gte=0"`
+	Direction       *string  `validate:"omitempty,oneof=income expense transfer"`
+	CategoryID      *graphql.ID
+	PaymentMethodID graphql.ID
+	AccountID       *graphql.ID
+	Description     *string   `validate:"omitempty,max=500"`
+	Payee           *string   `validate:"omitempty,max=255"`
+	Tags            *[]string `validate:"omitempty,max=20,dive,max=50"`
+	TransactionDate graphql.Time
+	Tax             *paymentTaxInput
+	Force           *bool
+}
+
+func (r *Resolver) CreatePayment(ctx context.Context, args struct{ Input createPaymentInput }) (*paymentResolver, error) {
+	input := &args.Input
+	if err := validate(input); err != nil {
+		return nil, err
+	}
+
+	serviceReq := &services.CreatePaymentRequest{
+		Amount:          input.Amount,
+		Direction:       stringValue(input.Direction),
+		Description:     stringValue(input.Description),
+		Payee:           stringValue(input.Payee),
+		TransactionDate: input.TransactionDate.Time,
+	}
+	if input.Fee != nil {
+		serviceReq.Fee = *input.Fee
+	}
+	if input.Tags != nil {
+		serviceReq.Tags = *input.Tags
+	}
+	if input.Force != nil {
+		serviceReq.Force = *input.Force
+	}
+
+	var err error
+	if input.CategoryID != nil {
+		if serviceReq.CategoryID, err = parseID(*input.CategoryID, "category"); err != nil {
+			return nil, err
+		}
+	}
+	if serviceReq.PaymentMethodID, err = parseID(input.PaymentMethodID, "payment method"); err != nil {
+		return nil, err
+	}
+	if serviceReq.AccountID, err = parseOptionalID(input.AccountID, "account"); err != nil {
+		return nil, err
+	}
+	if serviceReq.Tax, err = input.Tax.toRequest(); err != nil {
+		return nil, err
+	}
+
+	payment, err := r.paymentService.Create(requestFrom(ctx).userID, serviceReq)
+	if err != nil {
+		return nil, paymentError(err)
+	}
+	return &paymentResolver{payment}, nil
+}
+
+type updatePaymentInput struct {
+	Amount          float64  `validate:"gt=0"`
+	Fee             *float64 `validate:"omitempty,gte=0"`
+	Status          string   `validate:"oneof=pending completed failed refunded"`
+	Direction       *string  `validate:"omitempty,oneof=income expense transfer"`
+	CategoryID      graphql.ID
+	PaymentMethodID graphql.ID
+	AccountID       *graphql.ID
+	Description     *string   `validate:"omitempty,max=500"`
+	Payee           *string   `validate:"omitempty,max=255"`
+	Tags            *[]string `validate:"omitempty,max=20,dive,max=50"`
+	TransactionDate graphql.Time
+	Tax             *paymentTaxInput
+}
+
+func (r *Resolver) UpdatePayment(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input updatePaymentInput
+}) (*paymentResolver, error) {
+	id, err := parseID(args.ID, "payment")
+	if err != nil {
+		return nil, err
+	}
+
+	input := &args.Input
+	if err := validate(input); err != nil {
+		return nil, err
+	}
+
+	serviceReq := &services.UpdatePaymentRequest{
+		Amount:          input.Amount,
+		Status:          input.Status,
+		Direction:       stringValue(input.Direction),
+		Description:     stringValue(input.Description),
+		Fee:             input.Fee,
+		Payee:           input.Payee,
+		TransactionDate: input.TransactionDate.Time,
+	}
+	if input.Tags != nil {
+		serviceReq.Tags = *input.Tags
+	}
+
+	if serviceReq.CategoryID, err = parseID(input.CategoryID, "category"); err != nil {
+		return nil, err
+	}
+	if serviceReq.PaymentMethodID, err = parseID(input.PaymentMethodID, "payment method"); err != nil {
+		return nil, err
+	}
+	if serviceReq.AccountID, err = parseOptionalID(input.AccountID, "account"); err != nil {
+		return nil, err
+	}
+	if serviceReq.Tax, err = input.Tax.toRequest(); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.paymentService.GetOwned(requestFrom(ctx).userID, id); err != nil {
+		return nil, paymentError(err)
+	}
+
+	payment, err := r.paymentService.Update(id, serviceReq)
+	if err != nil {
+		return nil, paymentError(err)
+	}
+	return &paymentResolver{payment}, nil
+}
+
+func (r *Resolver) DeletePayment(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	id, err := parseID(args.ID, "payment")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := r.paymentService.GetOwned(requestFrom(ctx).userID, id); err != nil {
+		return "", paymentError(err)
+	}
+
+	if err := r.paymentService.Delete(id); err != nil {
+		return "", err
+	}
+	return args.ID, nil
+}
+
+func parseID(id graphql.ID, name string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(string(id))
+	if err != nil {
+		return uuid.Nil, newError(codeBadUserInput, "Invalid "+name+" ID")
+	}
+	return parsed, nil
+}
+
+// parseOptionalID parses an optional ID, returning nil when it is not given
+func parseOptionalID(id *graphql.ID, name string) (*uuid.UUID, error) {
+	if id == nil {
+		return nil, nil
+	}
+	parsed, err := parseID(*id, name)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// validate checks the validate tags of an input like the REST handlers do
+func validate(input interface{}) error {
+	if validationErrors := middleware.ValidateStruct(input); len(validationErrors) > 0 {
+		err := newError(codeBadUserInput, "Validation failed")
+		err.extensions["details"] = validationErrors
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+// Package graph serves the GraphQL API. Resolvers reuse the services and
+// repositories of the REST API, and the category, payment method, account
+// and tax code of payments are loaded in batches per request.
+package graph
+
+import (
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/services"
+	"context"
+	_ "embed"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Server executes GraphQL requests
+type Server struct {
+	schema   *graphql.Schema
+	resolver *Resolver
+}
+
+func NewServer(paymentService *services.PaymentService, reportService *services.ReportService, paymentRepo *repositories.PaymentRepository, categoryRepo *repositories.CategoryRepository, paymentMethodRepo *repositories.PaymentMethodRepository, accountRepo *repositories.AccountRepository, taxCodeRepo *repositories.TaxCodeRepository) *Server {
+	resolver := &Resolver{
+		paymentService:    paymentService,
+		reportService:     reportService,
+		paymentRepo:       paymentRepo,
+		categoryRepo:      categoryRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		accountRepo:       accountRepo,
+		taxCodeRepo:       taxCodeRepo,
+	}
+	return &Server{
+		schema:   graphql.MustParseSchema(schemaSDL, resolver),
+		resolver: resolver,
+	}
+}
+
+// Exec runs a query or mutation for a user. Dates without a time are
+// interpreted in loc.
+func (s *Server) Exec(ctx context.Context, userID uuid.UUID, loc *time.Location, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	ctx = context.WithValue(ctx, requestKey{}, &request{
+		userID:  userID,
+		loc:     loc,
+		loaders: s.resolver.newLoaders(),
+	})
+	return s.schema.Exec(ctx, query, operationName, variables)
+}
+
+type requestKey struct{}
+
+// request holds what resolvers need to know about the request they run in
+type request struct {
+	userID  uuid.UUID
+	loc     *time.Location
+	loaders *loaders
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
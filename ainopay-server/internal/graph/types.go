@@ -0,0 +1,238 @@
+package graph
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/services"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+type paymentResolver struct {
+	p *models.Payment
+}
+
+func (r *paymentResolver) ID() graphql.ID           { return graphql.ID(r.p.ID.String()) }
+func (r *paymentResolver) Amount() float64          { return r.p.Amount }
+func (r *paymentResolver) Fee() float64             { return r.p.Fee }
+func (r *paymentResolver) Status() string           { return r.p.Status }
+func (r *paymentResolver) Direction() string        { return r.p.Direction }
+func (r *paymentResolver) Description() string      { return r.p.Description }
+func (r *paymentResolver) Payee() string            { return r.p.Payee }
+func (r *paymentResolver) Reconciled() bool         { return r.p.Reconciled }
+func (r *paymentResolver) OverLimit() bool          { return r.p.OverLimit }
+func (r *paymentResolver) TaxRate() float64         { return r.p.TaxRate }
+func (r *paymentResolver) TaxAmount() float64       { return r.p.TaxAmount }
+func (r *paymentResolver) TaxInclusive() bool       { return r.p.TaxInclusive }
+func (r *paymentResolver) TaxInvoiceNumber() string { return r.p.TaxInvoiceNumber }
+func (r *paymentResolver) TransactionDate() graphql.Time {
+	return graphql.Time{Time: r.p.TransactionDate}
+}
+func (r *paymentResolver) CreatedAt() graphql.Time { return graphql.Time{Time: r.p.CreatedAt} }
+func (r *paymentResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.p.UpdatedAt} }
+
+func (r *paymentResolver) Tags() []string {
+	if r.p.Tags == nil {
+		return []string{}
+	}
+	return r.p.Tags
+}
+
+// The relations below are used as they are when the payment was loaded with
+// them, as returned by the payment service, and batched otherwise
+
+func (r *paymentResolver) Category(ctx context.Context) (*categoryResolver, error) {
+	if r.p.Category.ID != uuid.Nil {
+		return &categoryResolver{&r.p.Category}, nil
+	}
+	category, err := requestFrom(ctx).loaders.categories.Load(ctx, r.p.CategoryID)()
+	if err != nil {
+		return nil, err
+	}
+	return &categoryResolver{category}, nil
+}
+
+func (r *paymentResolver) PaymentMethod(ctx context.Context) (*paymentMethodResolver, error) {
+	if r.p.PaymentMethod.ID != uuid.Nil {
+		return &paymentMethodResolver{&r.p.PaymentMethod}, nil
+	}
+	method, err := requestFrom(ctx).loaders.paymentMethods.Load(ctx, r.p.PaymentMethodID)()
+	if err != nil {
+		return nil, err
+	}
+	return &paymentMethodResolver{method}, nil
+}
+
+func (r *paymentResolver) Account(ctx context.Context) (*accountResolver, error) {
+	if r.p.AccountID == nil {
+		return nil, nil
+	}
+	if r.p.Account != nil {
+		return &accountResolver{r.p.Account}, nil
+	}
+	account, err := requestFrom(ctx).loaders.accounts.Load(ctx, *r.p.AccountID)()
+	if err != nil {
+		return nil, err
+	}
+	return &accountResolver{account}, nil
+}
+
+func (r *paymentResolver) TaxCode(ctx context.Context) (*taxCodeResolver, error) {
+	if r.p.TaxCodeID == nil {
+		return nil, nil
+	}
+	if r.p.TaxCode != nil {
+		return &taxCodeResolver{r.p.TaxCode}, nil
+	}
+	code, err := requestFrom(ctx).loaders.taxCodes.Load(ctx, *r.p.TaxCodeID)()
+	if err != nil {
+		return nil, err
+	}
+	return &taxCodeResolver{code}, nil
+}
+
+type paymentPageResolver struct {
+	payments    []models.Payment
+	total       int64
+	page, limit int32
+}
+
+func (r *paymentPageResolver) Payments() []*paymentResolver {
+	resolvers := make([]*paymentResolver, len(r.payments))
+	for i := range r.payments {
+		resolvers[i] = &paymentResolver{&r.payments[i]}
+	}
+	return resolvers
+}
+
+func (r *paymentPageResolver) Total() int32 { return int32(r.total) }
+func (r *paymentPageResolver) Page() int32  { return r.page }
+func (r *paymentPageResolver) Limit() int32 { return r.limit }
+
+type categoryResolver struct {
+	c *models.Category
+}
+
+func (r *categoryResolver) ID() graphql.ID      { return graphql.ID(r.c.ID.String()) }
+func (r *categoryResolver) Name() string        { return r.c.Name }
+func (r *categoryResolver) Description() string { return r.c.Description }
+
+type paymentMethodResolver struct {
+	m *models.PaymentMethod
+}
+
+func (r *paymentMethodResolver) ID() graphql.ID { return graphql.ID(r.m.ID.String()) }
+func (r *paymentMethodResolver) Name() string   { return r.m.Name }
+func (r *paymentMethodResolver) Code() string   { return r.m.Code }
+func (r *paymentMethodResolver) IsActive() bool { return r.m.IsActive }
+
+type accountResolver struct {
+	a *models.FinancialAccount
+}
+
+func (r *accountResolver) ID() graphql.ID { return graphql.ID(r.a.ID.String()) }
+func (r *accountResolver) Name() string   { return r.a.Name }
+func (r *accountResolver) Type() string   { return r.a.Type }
+func (r *accountResolver) IsActive() bool { return r.a.IsActive }
+
+type taxCodeResolver struct {
+	t *models.TaxCode
+}
+
+func (r *taxCodeResolver) ID() graphql.ID { return graphql.ID(r.t.ID.String()) }
+func (r *taxCodeResolver) Code() string   { return r.t.Code }
+func (r *taxCodeResolver) Name() string   { return r.t.Name }
+func (r *taxCodeResolver) Rate() float64  { return r.t.Rate }
+
+type statisticsResolver struct {
+	s *models.PaymentStatistics
+}
+
+func (r *statisticsResolver) TotalPayments() int32  { return int32(r.s.TotalPayments) }
+func (r *statisticsResolver) CompletedCount() int32 { return int32(r.s.CompletedCount) }
+func (r *statisticsResolver) PendingCount() int32   { return int32(r.s.PendingCount) }
+func (r *statisticsResolver) TotalIncome() float64  { return r.s.TotalIncome }
+func (r *statisticsResolver) TotalExpense() float64 { return r.s.TotalExpense }
+func (r *statisticsResolver) TotalFees() float64    { return r.s.TotalFees }
+func (r *statisticsResolver) Net() float64          { return r.s.Net }
+
+type dashboardStatsResolver struct {
+	statisticsResolver
+	stats *services.DashboardStats
+}
+
+func (r *dashboardStatsResolver) Period() *statsPeriodResolver {
+	return &statsPeriodResolver{&r.stats.Period}
+}
+
+func (r *dashboardStatsResolver) Comparison() *statsComparisonResolver {
+	if r.stats.Comparison == nil {
+		return nil
+	}
+	return &statsComparisonResolver{r.stats.Comparison}
+}
+
+type statsPeriodResolver struct {
+	p *services.StatsPeriod
+}
+
+func (r *statsPeriodResolver) Preset() *string { return optionalString(r.p.Preset) }
+func (r *statsPeriodResolver) From() *string   { return optionalString(r.p.From) }
+func (r *statsPeriodResolver) To() *string     { return optionalString(r.p.To) }
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type statsComparisonResolver struct {
+	c *services.StatsComparison
+}
+
+func (r *statsComparisonResolver) Period() *statsPeriodResolver {
+	return &statsPeriodResolver{&r.c.Period}
+}
+
+func (r *statsComparisonResolver) Previous() *statisticsResolver {
+	return &statisticsResolver{&r.c.Previous}
+}
+
+func (r *statsComparisonResolver) Changes() *statsChangesResolver {
+	return &statsChangesResolver{&r.c.Changes}
+}
+
+type statsChangesResolver struct {
+	c *services.StatsChanges
+}
+
+func (r *statsChangesResolver) TotalPayments() *statChangeResolver {
+	return &statChangeResolver{&r.c.TotalPayments}
+}
+func (r *statsChangesResolver) CompletedCount() *statChangeResolver {
+	return &statChangeResolver{&r.c.CompletedCount}
+}
+func (r *statsChangesResolver) PendingCount() *statChangeResolver {
+	return &statChangeResolver{&r.c.PendingCount}
+}
+func (r *statsChangesResolver) TotalIncome() *statChangeResolver {
+	return &statChangeResolver{&r.c.TotalIncome}
+}
+func (r *statsChangesResolver) TotalExpense() *statChangeResolver {
+	return &statChangeResolver{&r.c.TotalExpense}
+}
+func (r *statsChangesResolver) TotalFees() *statChangeResolver {
+	return &statChangeResolver{&r.c.TotalFees}
+}
+func (r *statsChangesResolver) Net() *statChangeResolver {
+	return &statChangeResolver{&r.c.Net}
+}
+
+type statChangeResolver struct {
+	c *services.StatChange
+}
+
+func (r *statChangeResolver) Absolute() float64 { return r.c.Absolute }
+func (r *statChangeResolver) Percent() *float64 { return r.c.Percent }
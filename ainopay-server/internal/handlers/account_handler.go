@@ -0,0 +1,415 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// AccountRequest represents the request body for creating or updating an account
+type AccountRequest struct {
+	Name            string  `json:"name" validate:"required,max=255"`
+	Type            string  `json:"type" validate:"required,oneof=bank e_wallet cash credit_card"`
+	PaymentMethodID string  `json:"payment_method_id" validate:"omitempty,uuid4"`
+	Currency        string  `json:"currency" validate:"omitempty,iso4217"` // default: IDR, unchanged on update when omitted; fixed once the account has payments or transfers
+	OpeningBalance  float64 `json:"opening_balance"`
+	IsActive        *bool   `json:"is_active"`
+}
+
+// TransferRequest represents the request body for creating a transfer
+type TransferRequest struct {
+	FromAccountID string  `json:"from_account_id" validate:"required,uuid4"`
+	ToAccountID   string  `json:"to_account_id" validate:"required,uuid4"`
+	Amount        float64 `json:"amount" validate:"required,gt=0"`
+	Description   string  `json:"description" validate:"max=500"`
+	TransferDate  string  `json:"transfer_date" validate:"required"`
+}
+
+// accountErrorStatus maps account service errors to HTTP status codes
+func accountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrFinancialAccountNotFound), errors.Is(err, services.ErrTransferNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrFinancialAccountInUse), errors.Is(err, services.ErrCurrencyInUse):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrFinancialAccountInactive), errors.Is(err, services.ErrInvalidFinancialAccount),
+		errors.Is(err, services.ErrSameAccountTransfer), errors.Is(err, services.ErrInvalidBalanceInterval),
+		errors.Is(err, services.ErrBalanceRangeTooLarge), errors.Is(err, services.ErrCrossCurrencyTransfer),
+		errors.Is(err, services.ErrInvalidCurrency):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindAccountRequest binds and validates an account request, writing the error response on failure
+func bindAccountRequest(c *gin.Context) (*services.FinancialAccountRequest, bool) {
+	var req AccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, false
+	}
+
+	paymentMethodID, err := parseOptionalUUID(req.PaymentMethodID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID")
+		return nil, false
+	}
+
+	return &services.FinancialAccountRequest{
+		Name:            req.Name,
+		Type:            req.Type,
+		PaymentMethodID: paymentMethodID,
+		Currency:        req.Currency,
+		OpeningBalance:  req.OpeningBalance,
+		IsActive:        req.IsActive,
+	}, true
+}
+
+// Create godoc
+// @Summary Create account
+// @Description Creates a bank account, e-wallet or other account. Payments made with its payment method are linked to it by default.
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AccountRequest true "Account Request"
+// @Success 201 {object} utils.Response
+// @Router /accounts [post]
+func (h *AccountHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req, ok := bindAccountRequest(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.accountService.Create(userID.(uuid.UUID), req)
+	if err != nil {
+		utils.ErrorResponse(c, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Account created successfully", account)
+}
+
+// GetAll godoc
+// @Summary Get accounts with balances
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /accounts [get]
+func (h *AccountHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	accounts, err := h.accountService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Accounts retrieved successfully", accounts)
+}
+
+// GetByID godoc
+// @Summary Get account by ID
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Success 200 {object} utils.Response
+// @Router /accounts/{id} [get]
+func (h *AccountHandler) GetByID(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	account, err := h.accountService.GetByID(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account retrieved successfully", account)
+}
+
+// Update godoc
+// @Summary Update account
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Param request body AccountRequest true "Account Request"
+// @Success 200 {object} utils.Response
+// @Router /accounts/{id} [put]
+func (h *AccountHandler) Update(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	req, ok := bindAccountRequest(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.accountService.Update(userID.(uuid.UUID), id, req)
+	if err != nil {
+		utils.ErrorResponse(c, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account updated successfully", account)
+}
+
+// Delete godoc
+// @Summary Delete account
+// @Description Only accounts without payments or transfers can be deleted
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Success 200 {object} utils.Response
+// @Router /accounts/{id} [delete]
+func (h *AccountHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	if err := h.accountService.Delete(userID.(uuid.UUID), id); err != nil {
+		utils.ErrorResponse(c, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Account deleted successfully", nil)
+}
+
+// GetBalanceHistory godoc
+// @Summary Get account balance over time
+// @Description Balance at the end of every period, computed from the account's opening balance, payments and transfers
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Account ID"
+// @Param interval query string false "day, week or month" default(day)
+// @Param start_date query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param end_date query string false "End date (YYYY-MM-DD), defaults to today"
+// @Success 200 {object} utils.Response
+// @Router /accounts/{id}/balance-history [get]
+func (h *AccountHandler) GetBalanceHistory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	endDate := time.Now().In(middleware.Location(c))
+	if val := c.Query("end_date"); val != "" {
+		if endDate, err = time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid end date format, expected YYYY-MM-DD")
+			return
+		}
+	}
+
+	startDate := endDate.AddDate(0, 0, -30)
+	if val := c.Query("start_date"); val != "" {
+		if startDate, err = time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid start date format, expected YYYY-MM-DD")
+			return
+		}
+	}
+	if startDate.After(endDate) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Start date must not be after end date")
+		return
+	}
+
+	result, err := h.accountService.GetBalanceHistory(userID.(uuid.UUID), id, startDate, endDate, c.DefaultQuery("interval", "day"), middleware.Location(c))
+	if err != nil {
+		utils.ErrorResponse(c, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Balance history retrieved successfully", result)
+}
+
+// CreateTransfer godoc
+// @Summary Transfer money between accounts
+// @Tags accounts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TransferRequest true "Transfer Request"
+// @Success 201 {object} utils.Response
+// @Router /transfers [post]
+func (h *AccountHandler) CreateTransfer(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	transferDate, err := time.Parse(time.RFC3339, req.TransferDate)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid transfer date format")
+		return
+	}
+
+	fromAccountID, err := uuid.Parse(req.FromAccountID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid source account ID")
+		return
+	}
+
+	toAccountID, err := uuid.Parse(req.ToAccountID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid destination account ID")
+		return
+	}
+
+	transfer, err := h.accountService.CreateTransfer(userID.(uuid.UUID), &services.CreateTransferRequest{
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		TransferDate:  transferDate,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Transfer created successfully", transfer)
+}
+
+// GetTransfers godoc
+// @Summary Get transfers
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param account_id query string false "Only transfers from or to this account"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response
+// @Router /transfers [get]
+func (h *AccountHandler) GetTransfers(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	var filter repositories.TransferFilter
+	if val := c.Query("account_id"); val != "" {
+		accountID, err := uuid.Parse(val)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+			return
+		}
+		filter.AccountID = &accountID
+	}
+	if val := c.Query("start_date"); val != "" {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
+			filter.StartDate = &t
+		}
+	}
+	if val := c.Query("end_date"); val != "" {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
+			// Set to end of day
+			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+			filter.EndDate = &t
+		}
+	}
+
+	result, err := h.accountService.GetTransfers(userID.(uuid.UUID), page, limit, filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transfers retrieved successfully", result)
+}
+
+// DeleteTransfer godoc
+// @Summary Delete transfer
+// @Tags accounts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Transfer ID"
+// @Success 200 {object} utils.Response
+// @Router /transfers/{id} [delete]
+func (h *AccountHandler) DeleteTransfer(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid transfer ID")
+		return
+	}
+
+	if err := h.accountService.DeleteTransfer(userID.(uuid.UUID), id); err != nil {
+		utils.ErrorResponse(c, accountErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Transfer deleted successfully", nil)
+}
@@ -4,6 +4,7 @@ import (
 	"ainopay-server/internal/middleware"
 	"ainopay-server/internal/services"
 	"ainopay-server/internal/utils"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -24,6 +25,13 @@ type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
 	FullName string `json:"full_name" validate:"required,min=2,max=100"`
+	Timezone string `json:"timezone" validate:"max=64"`
+}
+
+// UpdateProfileRequest represents the request body for updating the current user
+type UpdateProfileRequest struct {
+	FullName *string `json:"full_name" validate:"omitempty,min=2,max=100"`
+	Timezone *string `json:"timezone" validate:"omitempty,max=64"`
 }
 
 // LoginRequest represents the request body for login
@@ -62,6 +70,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Email:    req.Email,
 		Password: req.Password,
 		FullName: req.FullName,
+		Timezone: req.Timezone,
 	}
 
 	result, err := h.authService.Register(serviceReq)
@@ -133,6 +142,55 @@ func (h *AuthHandler) GetMe(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "User retrieved successfully", user)
 }
 
+// UpdateMe godoc
+// @Summary Update current user
+// @Description Changes the name and timezone preference of the current user. The timezone, an IANA name such as Asia/Jakarta, is used to interpret date filters and group payments by day and month.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateProfileRequest true "Update Profile Request"
+// @Success 200 {object} utils.Response
+// @Router /auth/me [patch]
+func (h *AuthHandler) UpdateMe(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	user, err := h.authService.UpdateProfile(id, &services.UpdateProfileRequest{
+		FullName: req.FullName,
+		Timezone: req.Timezone,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTimezone):
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrUserNotFound):
+			utils.ErrorResponse(c, http.StatusNotFound, "User not found")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "User updated successfully", user)
+}
+
 // Refresh godoc
 // @Summary Refresh access token
 // @Tags auth
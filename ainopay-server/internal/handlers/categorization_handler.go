@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CategorizationHandler struct {
+	categorizationService *services.CategorizationService
+	paymentService        *services.PaymentService
+}
+
+func NewCategorizationHandler(categorizationService *services.CategorizationService, paymentService *services.PaymentService) *CategorizationHandler {
+	return &CategorizationHandler{categorizationService: categorizationService, paymentService: paymentService}
+}
+
+// CategorizationRuleRequest represents the request body for creating or updating a categorization rule
+type CategorizationRuleRequest struct {
+	Name                string   `json:"name" validate:"required,max=255"`
+	Priority            int      `json:"priority"`
+	DescriptionContains string   `json:"description_contains" validate:"max=255"`
+	DescriptionRegex    string   `json:"description_regex" validate:"max=500"`
+	MinAmount           *float64 `json:"min_amount" validate:"omitempty,gte=0"`
+	MaxAmount           *float64 `json:"max_amount" validate:"omitempty,gte=0"`
+	PaymentMethodID     string   `json:"payment_method_id" validate:"omitempty,uuid4"`
+	CategoryID          string   `json:"category_id" validate:"omitempty,uuid4"`
+	Payee               string   `json:"payee" validate:"max=255"`
+	Tags                []string `json:"tags" validate:"max=20,dive,max=50"`
+	IsActive            *bool    `json:"is_active"`
+}
+
+// categorizationErrorStatus maps categorization service errors to HTTP status codes
+func categorizationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCategorizationRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidCategorizationRule):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindCategorizationRuleRequest binds and validates a categorization rule request, writing the error response on failure
+func bindCategorizationRuleRequest(c *gin.Context) (*services.CategorizationRuleRequest, bool) {
+	var req CategorizationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, false
+	}
+
+	paymentMethodID, err := parseOptionalUUID(req.PaymentMethodID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID")
+		return nil, false
+	}
+
+	categoryID, err := parseOptionalUUID(req.CategoryID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+		return nil, false
+	}
+
+	return &services.CategorizationRuleRequest{
+		Name:                req.Name,
+		Priority:            req.Priority,
+		DescriptionContains: req.DescriptionContains,
+		DescriptionRegex:    req.DescriptionRegex,
+		MinAmount:           req.MinAmount,
+		MaxAmount:           req.MaxAmount,
+		PaymentMethodID:     paymentMethodID,
+		CategoryID:          categoryID,
+		Payee:               req.Payee,
+		Tags:                req.Tags,
+		IsActive:            req.IsActive,
+	}, true
+}
+
+// Create godoc
+// @Summary Create categorization rule
+// @Description Sets the category, payee and tags of new and imported payments matching all of the rule's conditions. Rules are tried by ascending priority.
+// @Tags categorization-rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CategorizationRuleRequest true "Categorization Rule Request"
+// @Success 201 {object} utils.Response
+// @Router /categorization-rules [post]
+func (h *CategorizationHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req, ok := bindCategorizationRuleRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.categorizationService.Create(userID.(uuid.UUID), req)
+	if err != nil {
+		utils.ErrorResponse(c, categorizationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Categorization rule created successfully", rule)
+}
+
+// GetAll godoc
+// @Summary Get categorization rules
+// @Tags categorization-rules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /categorization-rules [get]
+func (h *CategorizationHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	rules, err := h.categorizationService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categorization rules retrieved successfully", rules)
+}
+
+// Update godoc
+// @Summary Update categorization rule
+// @Tags categorization-rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Categorization rule ID"
+// @Param request body CategorizationRuleRequest true "Categorization Rule Request"
+// @Success 200 {object} utils.Response
+// @Router /categorization-rules/{id} [put]
+func (h *CategorizationHandler) Update(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid categorization rule ID")
+		return
+	}
+
+	req, ok := bindCategorizationRuleRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.categorizationService.Update(userID.(uuid.UUID), id, req)
+	if err != nil {
+		utils.ErrorResponse(c, categorizationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categorization rule updated successfully", rule)
+}
+
+// Delete godoc
+// @Summary Delete categorization rule
+// @Tags categorization-rules
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Categorization rule ID"
+// @Success 200 {object} utils.Response
+// @Router /categorization-rules/{id} [delete]
+func (h *CategorizationHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid categorization rule ID")
+		return
+	}
+
+	if err := h.categorizationService.Delete(userID.(uuid.UUID), id); err != nil {
+		utils.ErrorResponse(c, categorizationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categorization rule deleted successfully", nil)
+}
+
+// Preview godoc
+// @Summary Preview categorization of existing payments
+// @Description Lists the changes the active rules, or only rule_id, would make to existing payments. Matching rules replace the category and payee and add their tags.
+// @Tags categorization-rules
+// @Produce json
+// @Security BearerAuth
+// @Param rule_id query string false "Only apply this rule"
+// @Success 200 {object} utils.Response
+// @Router /categorization-rules/preview [post]
+func (h *CategorizationHandler) Preview(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	ruleID, err := parseOptionalUUID(c.Query("rule_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid categorization rule ID")
+		return
+	}
+
+	changes, err := h.categorizationService.Preview(userID.(uuid.UUID), ruleID)
+	if err != nil {
+		utils.ErrorResponse(c, categorizationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categorization preview generated successfully", changes)
+}
+
+// Apply godoc
+// @Summary Apply categorization rules to existing payments
+// @Description Makes the changes listed by the preview and returns them
+// @Tags categorization-rules
+// @Produce json
+// @Security BearerAuth
+// @Param rule_id query string false "Only apply this rule"
+// @Success 200 {object} utils.Response
+// @Router /categorization-rules/apply [post]
+func (h *CategorizationHandler) Apply(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	ruleID, err := parseOptionalUUID(c.Query("rule_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid categorization rule ID")
+		return
+	}
+
+	changes, err := h.paymentService.ApplyCategorization(userID.(uuid.UUID), ruleID)
+	if err != nil {
+		utils.ErrorResponse(c, categorizationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Categorization rules applied successfully", changes)
+}
@@ -22,6 +22,7 @@ func NewCategoryHandler(categoryRepo *repositories.CategoryRepository) *Category
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} utils.Response
+// @Deprecated
 // @Router /categories [get]
 func (h *CategoryHandler) GetAll(c *gin.Context) {
 	categories, err := h.categoryRepo.FindAll()
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"ainopay-server/internal/middleware"
 	"ainopay-server/internal/repositories"
 	"ainopay-server/internal/services"
 	"ainopay-server/internal/utils"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -14,30 +16,83 @@ import (
 
 type DashboardHandler struct {
 	paymentService *services.PaymentService
+	reportService  *services.ReportService
 	paymentRepo    *repositories.PaymentRepository
 }
 
-func NewDashboardHandler(paymentService *services.PaymentService, paymentRepo *repositories.PaymentRepository) *DashboardHandler {
+func NewDashboardHandler(paymentService *services.PaymentService, reportService *services.ReportService, paymentRepo *repositories.PaymentRepository) *DashboardHandler {
 	return &DashboardHandler{
 		paymentService: paymentService,
+		reportService:  reportService,
 		paymentRepo:    paymentRepo,
 	}
 }
 
+// bindStatsRequest reads the period of dashboard statistics, writing the
+// error response on failure
+func bindStatsRequest(c *gin.Context) (*services.StatsRequest, bool) {
+	req := &services.StatsRequest{Preset: c.Query("preset"), Compare: true, Location: middleware.Location(c)}
+	if val := c.Query("from"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid from date format")
+			return nil, false
+		}
+		req.From = &t
+	}
+	if val := c.Query("to"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid to date format")
+			return nil, false
+		}
+		req.To = &t
+	}
+	if val := c.Query("compare"); val != "" {
+		compare, err := strconv.ParseBool(val)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid compare value")
+			return nil, false
+		}
+		req.Compare = compare
+	}
+	return req, true
+}
+
+// statsErrorStatus maps dashboard statistics errors to HTTP status codes
+func statsErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvalidStatsPeriod) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
 // GetStats godoc
 // @Summary Get dashboard statistics
+// @Description Totals of completed payments: income, expense, fees and net (income minus expenses and fees), for all time or a period.
+// @Description The period is a preset or a from/to date range. Calendar presets are compared with the same part of the previous month, quarter or year, date ranges with the range of the same length right before.
 // @Tags dashboard
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} utils.Response
+// @Param preset query string false "Preset period (this_month, last_month, this_quarter, last_quarter, ytd, last_year)"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD, default: today when from is set)"
+// @Param compare query bool false "Compare with the previous period (default: true)"
+// @Success 200 {object} utils.Response{data=services.DashboardStats}
+// @Deprecated
 // @Router /dashboard/stats [get]
 func (h *DashboardHandler) GetStats(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	id := userID.(uuid.UUID)
 
-	stats, err := h.paymentService.GetStatistics(id)
+	req, ok := bindStatsRequest(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.reportService.GetDashboardStats(id, req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.ErrorResponse(c, statsErrorStatus(err), err.Error())
 		return
 	}
 
@@ -55,7 +110,7 @@ func (h *DashboardHandler) GetRecent(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	id := userID.(uuid.UUID)
 
-	result, err := h.paymentService.GetAll(id, 1, 5, "", "", nil, nil, nil, nil)
+	result, err := h.paymentService.GetAll(id, 1, 5, "", "", "", nil, nil, nil, nil)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -65,7 +120,7 @@ func (h *DashboardHandler) GetRecent(c *gin.Context) {
 }
 
 // GetChartData godoc
-// @Summary Get monthly earnings for chart
+// @Summary Get monthly expenses for chart
 // @Tags dashboard
 // @Produce json
 // @Security BearerAuth
@@ -76,12 +131,13 @@ func (h *DashboardHandler) GetChartData(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	id := userID.(uuid.UUID)
 
-	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	loc := middleware.Location(c)
+	year, err := strconv.Atoi(c.DefaultQuery("year", strconv.Itoa(time.Now().In(loc).Year())))
 	if err != nil {
-		year = time.Now().Year()
+		year = time.Now().In(loc).Year()
 	}
 
-	stats, err := h.paymentService.GetMonthlyStats(id, year)
+	stats, err := h.reportService.GetMonthlyStats(id, year, loc)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -89,3 +145,55 @@ func (h *DashboardHandler) GetChartData(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusOK, "Chart data retrieved successfully", stats)
 }
+
+// GetCashFlow godoc
+// @Summary Get monthly cash flow
+// @Description Monthly inflow and outflow of completed payments, including fees and transfers to untracked accounts
+// @Tags dashboard
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "First month (YYYY-MM-DD, default: 11 months before end_date)"
+// @Param end_date query string false "Last month (YYYY-MM-DD, default: today)"
+// @Success 200 {object} utils.Response
+// @Router /dashboard/cash-flow [get]
+func (h *DashboardHandler) GetCashFlow(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	end := time.Now().In(middleware.Location(c))
+	if val := c.Query("end_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid end date format")
+			return
+		}
+		end = t
+	}
+
+	start := end.AddDate(0, -11, 0)
+	if val := c.Query("start_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid start date format")
+			return
+		}
+		start = t
+	}
+
+	if start.After(end) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Start date must be before end date")
+		return
+	}
+	if end.Sub(start) > 5*366*24*time.Hour {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Date range must not exceed 5 years")
+		return
+	}
+
+	series, err := h.reportService.GetCashFlow(id, start, end, middleware.Location(c))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cash flow retrieved successfully", series)
+}
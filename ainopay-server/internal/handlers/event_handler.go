@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"io"
+	"log"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// streamHeartbeatInterval keeps idle streams from being closed by proxies
+const streamHeartbeatInterval = 25 * time.Second
+
+type EventHandler struct {
+	realtimeService *services.RealtimeService
+	reportService   *services.ReportService
+}
+
+func NewEventHandler(realtimeService *services.RealtimeService, reportService *services.ReportService) *EventHandler {
+	return &EventHandler{realtimeService: realtimeService, reportService: reportService}
+}
+
+// Stream godoc
+// @Summary Stream payment events and dashboard statistics
+// @Description Server-sent events for the user's payments: payment.created, payment.updated and payment.deleted with the payment, and payment.status_changed with the payment and its previous status. The event ID is the outbox event ID; events may be repeated.
+// @Description A stats event with the refreshed dashboard statistics follows each burst of payment events and is also sent when the stream opens. The statistics period takes the same parameters as /dashboard/stats.
+// @Description EventSource cannot set headers, so the access token may be given in the access_token query parameter instead.
+// @Tags events
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param access_token query string false "Access token, when the Authorization header cannot be set"
+// @Param preset query string false "Preset period (this_month, last_month, this_quarter, last_quarter, ytd, last_year)"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD, default: today when from is set)"
+// @Param compare query bool false "Compare with the previous period (default: true)"
+// @Success 200 {string} string "event stream"
+// @Router /events [get]
+func (h *EventHandler) Stream(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	req, ok := bindStatsRequest(c)
+	if !ok {
+		return
+	}
+
+	// Subscribe before loading the first statistics so no change is missed
+	events, unsubscribe := h.realtimeService.Subscribe(id)
+	defer unsubscribe()
+
+	stats, err := h.reportService.GetDashboardStats(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, statsErrorStatus(err), err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+	c.SSEvent("stats", stats)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": heartbeat\n\n")
+			return err == nil
+		case event, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and reloads
+				return false
+			}
+			c.Render(-1, sse.Event{Id: event.ID, Event: event.Type, Data: event.Data})
+
+			// Refresh the statistics once the queued events are written
+			if len(events) > 0 {
+				return true
+			}
+			stats, err := h.reportService.GetDashboardStats(id, req)
+			if err != nil {
+				log.Printf("Error refreshing dashboard stats for user %s: %v", id, err)
+				return true
+			}
+			c.SSEvent("stats", stats)
+			return true
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/providers"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxCallbackBodySize limits the size of inbound provider callbacks
+const maxCallbackBodySize = 1 << 20
+
+type GatewayHandler struct {
+	gatewayService *services.GatewayService
+}
+
+func NewGatewayHandler(gatewayService *services.GatewayService) *GatewayHandler {
+	return &GatewayHandler{gatewayService: gatewayService}
+}
+
+// ChargeRequest represents the request body for charging a payment through a provider
+type ChargeRequest struct {
+	Provider string `json:"provider"`
+}
+
+// gatewayErrorStatus maps gateway and provider errors to HTTP status codes
+func gatewayErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound), errors.Is(err, providers.ErrChargeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, providers.ErrUnknownProvider):
+		return http.StatusBadRequest
+	case errors.Is(err, providers.ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrPaymentNotChargeable), errors.Is(err, services.ErrPaymentNotCharged),
+		errors.Is(err, services.ErrPaymentNotRefundable), errors.Is(err, providers.ErrNotRefundable):
+		return http.StatusConflict
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// GetProviders godoc
+// @Summary List payment providers
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /payment-providers [get]
+func (h *GatewayHandler) GetProviders(c *gin.Context) {
+	utils.SuccessResponse(c, http.StatusOK, "Payment providers retrieved successfully", h.gatewayService.Providers())
+}
+
+// runPaymentAction parses the payment ID and runs a gateway operation on it
+func (h *GatewayHandler) runPaymentAction(c *gin.Context, message string, action func(userID, paymentID uuid.UUID) (*models.Payment, error)) {
+	userID, _ := c.Get("user_id")
+
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+		return
+	}
+
+	payment, err := action(userID.(uuid.UUID), paymentID)
+	if err != nil {
+		utils.ErrorResponse(c, gatewayErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, message, payment)
+}
+
+// Charge godoc
+// @Summary Charge payment through a provider
+// @Description Sends a pending payment to a provider. The payment settles when the provider calls back or on sync.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Param request body ChargeRequest false "Provider, defaults to the first registered provider"
+// @Success 200 {object} utils.Response
+// @Router /payments/{id}/charge [post]
+func (h *GatewayHandler) Charge(c *gin.Context) {
+	var req ChargeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+	}
+
+	h.runPaymentAction(c, "Payment charged successfully", func(userID, paymentID uuid.UUID) (*models.Payment, error) {
+		return h.gatewayService.Charge(userID, paymentID, req.Provider)
+	})
+}
+
+// Sync godoc
+// @Summary Sync payment status from its provider
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Router /payments/{id}/sync [post]
+func (h *GatewayHandler) Sync(c *gin.Context) {
+	h.runPaymentAction(c, "Payment status synced successfully", h.gatewayService.SyncStatus)
+}
+
+// Refund godoc
+// @Summary Refund payment through its provider
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Router /payments/{id}/refund [post]
+func (h *GatewayHandler) Refund(c *gin.Context) {
+	h.runPaymentAction(c, "Payment refunded successfully", h.gatewayService.Refund)
+}
+
+// Callback godoc
+// @Summary Receive provider status callback
+// @Description Public endpoint for payment providers. Each provider authenticates its callbacks with its own signature scheme.
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} utils.Response
+// @Router /callbacks/{provider} [post]
+func (h *GatewayHandler) Callback(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCallbackBodySize))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	payment, err := h.gatewayService.HandleCallback(c.Param("provider"), body, c.Request.Header)
+	if err != nil {
+		status := gatewayErrorStatus(err)
+		if status == http.StatusBadGateway {
+			status = http.StatusBadRequest
+		}
+		if errors.Is(err, providers.ErrUnknownProvider) {
+			status = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, status, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Callback processed successfully", gin.H{
+		"payment_id": payment.ID,
+		"status":     payment.Status,
+	})
+}
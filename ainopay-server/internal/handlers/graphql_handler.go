@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"ainopay-server/internal/graph"
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GraphQLHandler struct {
+	server *graph.Server
+}
+
+func NewGraphQLHandler(server *graph.Server) *GraphQLHandler {
+	return &GraphQLHandler{server: server}
+}
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query godoc
+// @Summary Run a GraphQL query or mutation
+// @Description Queries payments (with filters and pagination), a payment, categories, payment methods and dashboard stats; mutations create, update and delete payments.
+// @Description The response follows the GraphQL specification rather than the usual envelope. Errors carry a code in their extensions: BAD_USER_INPUT, NOT_FOUND, CONFLICT, DUPLICATE_PAYMENT or SPENDING_LIMIT_EXCEEDED.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body GraphQLRequest true "GraphQL request"
+// @Success 200 {object} object
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	response := h.server.Exec(c.Request.Context(), userID.(uuid.UUID), middleware.Location(c), req.Query, req.OperationName, req.Variables)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InvoiceHandler struct {
+	invoiceService *services.InvoiceService
+}
+
+func NewInvoiceHandler(invoiceService *services.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService}
+}
+
+// InvoiceRequest represents the request body for creating or updating an invoice
+type InvoiceRequest struct {
+	Number  string  `json:"number" validate:"required,max=100"`
+	Payee   string  `json:"payee" validate:"required,max=255"`
+	DueDate string  `json:"due_date" validate:"required"`
+	Amount  float64 `json:"amount" validate:"required,gt=0"`
+}
+
+// ApplyPaymentRequest represents the request body for applying a payment to an invoice
+type ApplyPaymentRequest struct {
+	PaymentID string `json:"payment_id" validate:"required,uuid4"`
+}
+
+// invoiceErrorStatus maps invoice service errors to HTTP status codes
+func invoiceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvoiceNotFound), errors.Is(err, services.ErrPaymentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvoiceNumberTaken), errors.Is(err, services.ErrPaymentAlreadyApplied):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrPaymentNotOnInvoice):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindInvoiceRequest binds and validates an invoice request, writing the error response on failure
+func bindInvoiceRequest(c *gin.Context) (*InvoiceRequest, time.Time, bool) {
+	var req InvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, time.Time{}, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, time.Time{}, false
+	}
+
+	dueDate, err := time.Parse("2006-01-02", req.DueDate)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid due date format (expected YYYY-MM-DD)")
+		return nil, time.Time{}, false
+	}
+
+	return &req, dueDate, true
+}
+
+// Create godoc
+// @Summary Create new invoice
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body InvoiceRequest true "Create Invoice Request"
+// @Success 201 {object} utils.Response
+// @Router /invoices [post]
+func (h *InvoiceHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	req, dueDate, ok := bindInvoiceRequest(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceService.Create(id, &services.CreateInvoiceRequest{
+		Number:  req.Number,
+		Payee:   req.Payee,
+		DueDate: dueDate,
+		Amount:  req.Amount,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Invoice created successfully", invoice)
+}
+
+// GetAll godoc
+// @Summary Get all invoices
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (open, partially_paid, paid, overdue)"
+// @Success 200 {object} utils.Response
+// @Router /invoices [get]
+func (h *InvoiceHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	invoices, err := h.invoiceService.GetAll(id, c.Query("status"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invoices retrieved successfully", invoices)
+}
+
+// GetOverdue godoc
+// @Summary Get overdue invoices
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /invoices/overdue [get]
+func (h *InvoiceHandler) GetOverdue(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	invoices, err := h.invoiceService.GetOverdue(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Overdue invoices retrieved successfully", invoices)
+}
+
+// GetByID godoc
+// @Summary Get invoice by ID
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} utils.Response
+// @Router /invoices/{id} [get]
+func (h *InvoiceHandler) GetByID(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.GetByID(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invoice retrieved successfully", invoice)
+}
+
+// Update godoc
+// @Summary Update invoice
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param request body InvoiceRequest true "Update Invoice Request"
+// @Success 200 {object} utils.Response
+// @Router /invoices/{id} [put]
+func (h *InvoiceHandler) Update(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	req, dueDate, ok := bindInvoiceRequest(c)
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceService.Update(userID.(uuid.UUID), id, &services.UpdateInvoiceRequest{
+		Number:  req.Number,
+		Payee:   req.Payee,
+		DueDate: dueDate,
+		Amount:  req.Amount,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invoice updated successfully", invoice)
+}
+
+// Delete godoc
+// @Summary Delete invoice
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} utils.Response
+// @Router /invoices/{id} [delete]
+func (h *InvoiceHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	if err := h.invoiceService.Delete(userID.(uuid.UUID), id); err != nil {
+		utils.ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invoice deleted successfully", nil)
+}
+
+// ApplyPayment godoc
+// @Summary Apply a payment to an invoice
+// @Tags invoices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param request body ApplyPaymentRequest true "Apply Payment Request"
+// @Success 200 {object} utils.Response
+// @Router /invoices/{id}/payments [post]
+func (h *InvoiceHandler) ApplyPayment(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	var req ApplyPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	paymentID, err := uuid.Parse(req.PaymentID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.ApplyPayment(userID.(uuid.UUID), id, paymentID)
+	if err != nil {
+		utils.ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payment applied to invoice successfully", invoice)
+}
+
+// RemovePayment godoc
+// @Summary Remove a payment from an invoice
+// @Tags invoices
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Invoice ID"
+// @Param payment_id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Router /invoices/{id}/payments/{payment_id} [delete]
+func (h *InvoiceHandler) RemovePayment(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid invoice ID")
+		return
+	}
+
+	paymentID, err := uuid.Parse(c.Param("payment_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+		return
+	}
+
+	invoice, err := h.invoiceService.RemovePayment(userID.(uuid.UUID), id, paymentID)
+	if err != nil {
+		utils.ErrorResponse(c, invoiceErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payment removed from invoice successfully", invoice)
+}
@@ -0,0 +1,348 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LedgerHandler struct {
+	ledgerService *services.LedgerService
+}
+
+func NewLedgerHandler(ledgerService *services.LedgerService) *LedgerHandler {
+	return &LedgerHandler{ledgerService: ledgerService}
+}
+
+// CreateLedgerAccountRequest represents the request body for creating a ledger account
+type CreateLedgerAccountRequest struct {
+	Code     string `json:"code" validate:"required,max=50"`
+	Name     string `json:"name" validate:"required,max=255"`
+	Type     string `json:"type" validate:"required,oneof=asset liability equity income expense"`
+	Currency string `json:"currency" validate:"omitempty,iso4217"` // default: IDR
+}
+
+// JournalLineRequest is one posting of a manual journal entry
+type JournalLineRequest struct {
+	AccountID string  `json:"account_id" validate:"required,uuid4"`
+	Debit     float64 `json:"debit" validate:"gte=0"`
+	Credit    float64 `json:"credit" validate:"gte=0"`
+}
+
+// CreateJournalEntryRequest represents the request body for posting a manual journal entry
+type CreateJournalEntryRequest struct {
+	Description string               `json:"description" validate:"required,max=500"`
+	EntryDate   string               `json:"entry_date"` // YYYY-MM-DD, defaults to today
+	Lines       []JournalLineRequest `json:"lines" validate:"required,min=2,dive"`
+}
+
+// ledgerErrorStatus maps ledger service errors to HTTP status codes
+func ledgerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrJournalEntryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrLedgerAccountNotFound), errors.Is(err, services.ErrUnbalancedEntry),
+		errors.Is(err, services.ErrInvalidPosting), errors.Is(err, services.ErrInvalidAccountType),
+		errors.Is(err, services.ErrReservedAccountCode), errors.Is(err, services.ErrMixedCurrencyEntry),
+		errors.Is(err, services.ErrInvalidCurrency):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrAccountCodeTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetAccounts godoc
+// @Summary Get ledger accounts with balances
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /ledger/accounts [get]
+func (h *LedgerHandler) GetAccounts(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	accounts, err := h.ledgerService.GetAccounts(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ledger accounts retrieved successfully", accounts)
+}
+
+// CreateAccount godoc
+// @Summary Create ledger account
+// @Description Cash and expense accounts for payment methods and categories are created automatically
+// @Tags ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateLedgerAccountRequest true "Account Request"
+// @Success 201 {object} utils.Response
+// @Router /ledger/accounts [post]
+func (h *LedgerHandler) CreateAccount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req CreateLedgerAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	account, err := h.ledgerService.CreateAccount(userID.(uuid.UUID), &services.CreateLedgerAccountRequest{
+		Code:     req.Code,
+		Name:     req.Name,
+		Type:     req.Type,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, ledgerErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Ledger account created successfully", account)
+}
+
+// GetEntries godoc
+// @Summary Get journal entries
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param payment_id query string false "Only entries of this payment"
+// @Param account_id query string false "Only entries posting to this account"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response
+// @Router /ledger/entries [get]
+func (h *LedgerHandler) GetEntries(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	var filter repositories.EntryFilter
+	if val := c.Query("payment_id"); val != "" {
+		paymentID, err := uuid.Parse(val)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+			return
+		}
+		filter.PaymentID = &paymentID
+	}
+	if val := c.Query("account_id"); val != "" {
+		accountID, err := uuid.Parse(val)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+			return
+		}
+		filter.AccountID = &accountID
+	}
+	if val := c.Query("start_date"); val != "" {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
+			filter.StartDate = &t
+		}
+	}
+	if val := c.Query("end_date"); val != "" {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
+			// Set to end of day
+			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+			filter.EndDate = &t
+		}
+	}
+
+	result, err := h.ledgerService.GetEntries(userID.(uuid.UUID), page, limit, filter)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Journal entries retrieved successfully", result)
+}
+
+// GetEntry godoc
+// @Summary Get journal entry by ID
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Journal entry ID"
+// @Success 200 {object} utils.Response
+// @Router /ledger/entries/{id} [get]
+func (h *LedgerHandler) GetEntry(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid journal entry ID")
+		return
+	}
+
+	entry, err := h.ledgerService.GetEntry(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, ledgerErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Journal entry retrieved successfully", entry)
+}
+
+// CreateEntry godoc
+// @Summary Post manual journal entry
+// @Description Every line must have either a debit or a credit, and total debits must equal total credits
+// @Tags ledger
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateJournalEntryRequest true "Journal Entry Request"
+// @Success 201 {object} utils.Response
+// @Router /ledger/entries [post]
+func (h *LedgerHandler) CreateEntry(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req CreateJournalEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	var entryDate time.Time
+	if req.EntryDate != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.EntryDate, middleware.Location(c))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid entry date format, expected YYYY-MM-DD")
+			return
+		}
+		entryDate = t
+	}
+
+	lines := make([]services.JournalLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		accountID, err := uuid.Parse(line.AccountID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+			return
+		}
+		lines = append(lines, services.JournalLine{AccountID: accountID, Debit: line.Debit, Credit: line.Credit})
+	}
+
+	entry, err := h.ledgerService.CreateEntry(userID.(uuid.UUID), &services.CreateJournalEntryRequest{
+		Description: req.Description,
+		EntryDate:   entryDate,
+		Lines:       lines,
+	})
+	if err != nil {
+		utils.ErrorResponse(c, ledgerErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Journal entry posted successfully", entry)
+}
+
+// GetTrialBalance godoc
+// @Summary Get trial balance
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Param as_of query string false "Only entries up to this date (YYYY-MM-DD)"
+// @Success 200 {object} utils.Response
+// @Router /ledger/trial-balance [get]
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var asOf *time.Time
+	if val := c.Query("as_of"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid as_of date format, expected YYYY-MM-DD")
+			return
+		}
+		// Include the whole day
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		asOf = &t
+	}
+
+	result, err := h.ledgerService.GetTrialBalance(userID.(uuid.UUID), asOf)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Trial balance retrieved successfully", result)
+}
+
+// Check godoc
+// @Summary Check ledger invariants
+// @Description Verifies that every entry balances, total debits equal total credits and payments are posted according to their current state
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /ledger/check [get]
+func (h *LedgerHandler) Check(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	result, err := h.ledgerService.Check(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ledger check completed", result)
+}
+
+// Sync godoc
+// @Summary Post missing payment entries
+// @Description Posts adjusting entries for payments whose postings do not match their current state, e.g. payments completed before the ledger existed
+// @Tags ledger
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /ledger/sync [post]
+func (h *LedgerHandler) Sync(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	result, err := h.ledgerService.SyncPayments(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ledger synced successfully", result)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// NotificationPreferenceRequest sets the channels of one notification type
+type NotificationPreferenceRequest struct {
+	Type  string `json:"type" validate:"required,oneof=budget_exceeded payment_failed approval_needed"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+}
+
+// UpdateNotificationPreferencesRequest represents the request body for updating notification preferences
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceRequest `json:"preferences" validate:"required,min=1,dive"`
+}
+
+// notificationErrorStatus maps notification service errors to HTTP status codes
+func notificationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidNotificationType):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetAll godoc
+// @Summary Get notifications
+// @Description Notifications of the user, newest first
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 20, max: 100)"
+// @Param unread query bool false "Only unread notifications"
+// @Success 200 {object} utils.Response{data=services.NotificationListResponse}
+// @Router /notifications [get]
+func (h *NotificationHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	unreadOnly := c.Query("unread") == "true"
+
+	result, err := h.notificationService.GetAll(userID.(uuid.UUID), unreadOnly, page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notifications retrieved successfully", result)
+}
+
+// GetUnreadCount godoc
+// @Summary Get the number of unread notifications
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	count, err := h.notificationService.UnreadCount(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Unread count retrieved successfully", gin.H{"unread": count})
+}
+
+// MarkRead godoc
+// @Summary Mark notification as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} utils.Response
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid notification ID")
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, notificationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification marked as read", notification)
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications as read
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	marked, err := h.notificationService.MarkAllRead(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notifications marked as read", gin.H{"marked": marked})
+}
+
+// GetPreferences godoc
+// @Summary Get notification preferences
+// @Description Whether each notification type goes to the notification center (in_app) and by email
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /notifications/preferences [get]
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	preferences, err := h.notificationService.GetPreferences(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification preferences retrieved successfully", preferences)
+}
+
+// UpdatePreferences godoc
+// @Summary Update notification preferences
+// @Description Sets the channels of the given notification types; other types keep theirs
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateNotificationPreferencesRequest true "Notification Preferences Request"
+// @Success 200 {object} utils.Response
+// @Router /notifications/preferences [put]
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	preferences := make([]models.NotificationPreference, len(req.Preferences))
+	for i, p := range req.Preferences {
+		preferences[i] = models.NotificationPreference{Type: p.Type, InApp: p.InApp, Email: p.Email}
+	}
+
+	updated, err := h.notificationService.UpdatePreferences(userID.(uuid.UUID), preferences)
+	if err != nil {
+		utils.ErrorResponse(c, notificationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification preferences updated successfully", updated)
+}
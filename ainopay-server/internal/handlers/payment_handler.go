@@ -3,7 +3,9 @@ package handlers
 import (
 	"ainopay-server/internal/middleware"
 	"ainopay-server/internal/services"
+	"ainopay-server/internal/statements"
 	"ainopay-server/internal/utils"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -22,31 +24,132 @@ func NewPaymentHandler(paymentService *services.PaymentService) *PaymentHandler
 
 // CreatePaymentRequest represents the request body for creating a payment
 type CreatePaymentRequest struct {
-	Amount          float64 `json:"amount" validate:"required,gt=0"`
-	CategoryID      string  `json:"category_id" validate:"required,uuid4"`
-	PaymentMethodID string  `json:"payment_method_id" validate:"required,uuid4"`
-	Description     string  `json:"description" validate:"max=500"`
-	TransactionDate string  `json:"transaction_date" validate:"required"`
+	Amount          float64            `json:"amount" validate:"required,gt=0"`
+	Fee             float64            `json:"fee" validate:"gte=0"`
+	Direction       string             `json:"direction" validate:"omitempty,oneof=income expense transfer"`
+	CategoryID      string             `json:"category_id" validate:"omitempty,uuid4"` // set by categorization rules when omitted
+	PaymentMethodID string             `json:"payment_method_id" validate:"required,uuid4"`
+	AccountID       string             `json:"account_id" validate:"omitempty,uuid4"`
+	Description     string             `json:"description" validate:"max=500"`
+	Payee           string             `json:"payee" validate:"max=255"`
+	Tags            []string           `json:"tags" validate:"max=20,dive,max=50"`
+	TransactionDate string             `json:"transaction_date" validate:"required"`
+	Tax             *PaymentTaxRequest `json:"tax"`
+	Force           bool               `json:"force"`
 }
 
 // UpdatePaymentRequest represents the request body for updating a payment
 type UpdatePaymentRequest struct {
-	Amount          float64 `json:"amount" validate:"required,gt=0"`
-	Status          string  `json:"status" validate:"required,oneof=pending completed failed refunded"`
-	CategoryID      string  `json:"category_id" validate:"required,uuid4"`
-	PaymentMethodID string  `json:"payment_method_id" validate:"required,uuid4"`
-	Description     string  `json:"description" validate:"max=500"`
-	TransactionDate string  `json:"transaction_date" validate:"required"`
+	Amount          float64            `json:"amount" validate:"required,gt=0"`
+	Fee             *float64           `json:"fee" validate:"omitempty,gte=0"` // unchanged when omitted
+	Status          string             `json:"status" validate:"required,oneof=pending completed failed refunded"`
+	Direction       string             `json:"direction" validate:"omitempty,oneof=income expense transfer"` // unchanged when omitted
+	CategoryID      string             `json:"category_id" validate:"required,uuid4"`
+	PaymentMethodID string             `json:"payment_method_id" validate:"required,uuid4"`
+	AccountID       string             `json:"account_id" validate:"omitempty,uuid4"` // unchanged when omitted
+	Description     string             `json:"description" validate:"max=500"`
+	Payee           *string            `json:"payee" validate:"omitempty,max=255"`
+	Tags            []string           `json:"tags" validate:"max=20,dive,max=50"`
+	TransactionDate string             `json:"transaction_date" validate:"required"`
+	Tax             *PaymentTaxRequest `json:"tax"` // unchanged when omitted
+}
+
+// PaymentTaxRequest represents the tax of a payment
+type PaymentTaxRequest struct {
+	TaxCodeID     string   `json:"tax_code_id" validate:"omitempty,uuid4"`
+	Rate          *float64 `json:"rate" validate:"omitempty,gte=0,lte=100"` // percent, defaults to the tax code's rate
+	Amount        *float64 `json:"amount" validate:"omitempty,gte=0"`       // computed from the rate when omitted
+	Inclusive     *bool    `json:"inclusive"`                               // the amount includes the tax, default: true
+	InvoiceNumber string   `json:"invoice_number" validate:"max=50"`
+}
+
+// paymentErrorStatus maps payment service errors to HTTP status codes
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrFinancialAccountNotFound), errors.Is(err, services.ErrFinancialAccountInactive),
+		errors.Is(err, services.ErrCategoryRequired), errors.Is(err, services.ErrTaxCodeNotFound),
+		errors.Is(err, services.ErrTaxCodeInactive), errors.Is(err, services.ErrInvalidTax),
+		errors.Is(err, services.ErrImportCurrency):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrPaymentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrPaymentInReview), errors.Is(err, services.ErrPaymentNotInReview),
+		errors.Is(err, services.ErrPaymentCharged):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// paymentTax converts the tax of a payment request, which may be nil
+func paymentTax(req *PaymentTaxRequest) (*services.PaymentTaxRequest, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	taxCodeID, err := parseOptionalUUID(req.TaxCodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &services.PaymentTaxRequest{
+		TaxCodeID:     taxCodeID,
+		Rate:          req.Rate,
+		Amount:        req.Amount,
+		Inclusive:     req.Inclusive,
+		InvoiceNumber: req.InvoiceNumber,
+	}, nil
+}
+
+// parseOptionalUUID parses an optional ID, returning nil for an empty string
+func parseOptionalUUID(val string) (*uuid.UUID, error) {
+	if val == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(val)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// duplicateResponse reports a possible duplicate together with the matching payments
+func duplicateResponse(c *gin.Context, err *services.DuplicatePaymentError) {
+	c.JSON(http.StatusConflict, gin.H{
+		"success": false,
+		"error":   "Possible duplicate payment; resubmit with force=true to create it anyway",
+		"details": gin.H{
+			"candidate_ids": err.CandidateIDs,
+		},
+	})
+}
+
+// spendingLimitResponse reports the spending limits a payment would exceed and the headroom left on each
+func spendingLimitResponse(c *gin.Context, err *services.SpendingLimitError) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"success": false,
+		"error":   err.Error(),
+		"details": gin.H{
+			"violations": err.Violations,
+		},
+	})
 }
 
 // Create godoc
 // @Summary Create new payment
+// @Description The category, payee and tags are completed by the user's categorization rules.
+// @Description Returns 409 with the IDs of similar payments when the payment looks like a duplicate, unless force is set.
+// @Description Returns 422 with the remaining headroom when the payment would exceed a spending limit that rejects.
 // @Tags payments
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body services.CreatePaymentRequest true "Create Payment Request"
+// @Param force query bool false "Create even if the payment looks like a duplicate"
 // @Success 201 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Failure 422 {object} utils.Response
+// @Deprecated
 // @Router /payments [post]
 func (h *PaymentHandler) Create(c *gin.Context) {
 	userID, _ := c.Get("user_id")
@@ -76,10 +179,12 @@ func (h *PaymentHandler) Create(c *gin.Context) {
 	}
 
 	// Parse UUIDs
-	categoryID, err := uuid.Parse(req.CategoryID)
-	if err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
-		return
+	var categoryID uuid.UUID
+	if req.CategoryID != "" {
+		if categoryID, err = uuid.Parse(req.CategoryID); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+			return
+		}
 	}
 
 	paymentMethodID, err := uuid.Parse(req.PaymentMethodID)
@@ -88,18 +193,47 @@ func (h *PaymentHandler) Create(c *gin.Context) {
 		return
 	}
 
+	accountID, err := parseOptionalUUID(req.AccountID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	tax, err := paymentTax(req.Tax)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tax code ID")
+		return
+	}
+
 	// Convert to service request
 	serviceReq := &services.CreatePaymentRequest{
 		Amount:          req.Amount,
+		Fee:             req.Fee,
+		Direction:       req.Direction,
 		CategoryID:      categoryID,
 		PaymentMethodID: paymentMethodID,
+		AccountID:       accountID,
 		Description:     req.Description,
+		Payee:           req.Payee,
+		Tags:            req.Tags,
 		TransactionDate: transactionDate,
+		Tax:             tax,
+		Force:           req.Force || c.Query("force") == "true",
 	}
 
 	payment, err := h.paymentService.Create(id, serviceReq)
+	var duplicateErr *services.DuplicatePaymentError
+	if errors.As(err, &duplicateErr) {
+		duplicateResponse(c, duplicateErr)
+		return
+	}
+	var limitErr *services.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		spendingLimitResponse(c, limitErr)
+		return
+	}
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.ErrorResponse(c, paymentErrorStatus(err), err.Error())
 		return
 	}
 
@@ -114,12 +248,14 @@ func (h *PaymentHandler) Create(c *gin.Context) {
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Param status query string false "Filter by status"
+// @Param direction query string false "Filter by direction (income, expense, transfer)"
 // @Param search query string false "Search in description"
 // @Param min_amount query number false "Minimum amount"
 // @Param max_amount query number false "Maximum amount"
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
 // @Success 200 {object} utils.Response
+// @Deprecated
 // @Router /payments [get]
 func (h *PaymentHandler) GetAll(c *gin.Context) {
 	userID, _ := c.Get("user_id")
@@ -128,6 +264,7 @@ func (h *PaymentHandler) GetAll(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	status := c.Query("status")
+	direction := c.Query("direction")
 	search := c.Query("search")
 
 	// Parse advanced filters
@@ -145,19 +282,19 @@ func (h *PaymentHandler) GetAll(c *gin.Context) {
 
 	var startDate, endDate *time.Time
 	if val := c.Query("start_date"); val != "" {
-		if t, err := time.Parse("2006-01-02", val); err == nil {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
 			startDate = &t
 		}
 	}
 	if val := c.Query("end_date"); val != "" {
-		if t, err := time.Parse("2006-01-02", val); err == nil {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
 			// Set to end of day
 			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 			endDate = &t
 		}
 	}
 
-	result, err := h.paymentService.GetAll(id, page, limit, status, search, minAmount, maxAmount, startDate, endDate)
+	result, err := h.paymentService.GetAll(id, page, limit, status, direction, search, minAmount, maxAmount, startDate, endDate)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -172,6 +309,7 @@ func (h *PaymentHandler) GetAll(c *gin.Context) {
 // @Produce text/csv
 // @Security BearerAuth
 // @Param status query string false "Filter by status"
+// @Param direction query string false "Filter by direction (income, expense, transfer)"
 // @Param search query string false "Search in description"
 // @Param min_amount query number false "Minimum amount"
 // @Param max_amount query number false "Maximum amount"
@@ -184,6 +322,7 @@ func (h *PaymentHandler) Export(c *gin.Context) {
 	id := userID.(uuid.UUID)
 
 	status := c.Query("status")
+	direction := c.Query("direction")
 	search := c.Query("search")
 
 	// Parse advanced filters
@@ -201,19 +340,19 @@ func (h *PaymentHandler) Export(c *gin.Context) {
 
 	var startDate, endDate *time.Time
 	if val := c.Query("start_date"); val != "" {
-		if t, err := time.Parse("2006-01-02", val); err == nil {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
 			startDate = &t
 		}
 	}
 	if val := c.Query("end_date"); val != "" {
-		if t, err := time.Parse("2006-01-02", val); err == nil {
+		if t, err := time.ParseInLocation("2006-01-02", val, middleware.Location(c)); err == nil {
 			// Set to end of day
 			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 			endDate = &t
 		}
 	}
 
-	csvData, err := h.paymentService.Export(id, status, search, minAmount, maxAmount, startDate, endDate)
+	csvData, err := h.paymentService.Export(id, status, direction, search, minAmount, maxAmount, startDate, endDate, middleware.Location(c))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
@@ -224,6 +363,103 @@ func (h *PaymentHandler) Export(c *gin.Context) {
 	c.Data(http.StatusOK, "text/csv", csvData)
 }
 
+// Import godoc
+// @Summary Import payments from a bank statement
+// @Description Records the entries of a camt.053, MT940 or CSV statement as payments, debits as expenses and credits as income.
+// @Description Categorization rules set the category, payee and tags of matching entries.
+// @Description Entries in another currency than the account's (IDR without an account) are skipped and reported. The import is all or nothing.
+// @Tags payments
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Bank statement"
+// @Param format formData string false "Statement format (camt053, mt940, csv); detected when omitted"
+// @Param category_id formData string true "Category ID for entries no categorization rule categorizes"
+// @Param payment_method_id formData string true "Payment method ID for imported payments"
+// @Param account_id formData string false "Account the statement belongs to"
+// @Param currency formData string false "Statement currency; rejected when it differs from the account currency"
+// @Param force formData bool false "Import entries that look like duplicates"
+// @Success 201 {object} utils.Response
+// @Router /payments/import [post]
+func (h *PaymentHandler) Import(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	// Parse UUIDs
+	categoryID, err := uuid.Parse(c.PostForm("category_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	paymentMethodID, err := uuid.Parse(c.PostForm("payment_method_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID")
+		return
+	}
+
+	accountID, err := parseOptionalUUID(c.PostForm("account_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Statement file is required")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unable to read statement file")
+		return
+	}
+	defer file.Close()
+
+	parsed, err := statements.Parse(c.PostForm("format"), file)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.paymentService.Import(id, &services.ImportPaymentsRequest{
+		Entries:         statements.Entries(parsed),
+		PaymentMethodID: paymentMethodID,
+		CategoryID:      categoryID,
+		AccountID:       accountID,
+		Currency:        c.PostForm("currency"),
+		Force:           c.PostForm("force") == "true",
+	})
+	if err != nil {
+		utils.ErrorResponse(c, paymentErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Payments imported successfully", result)
+}
+
+// GetDuplicates godoc
+// @Summary Get suspected duplicate payments
+// @Description Groups existing payments with the same amount, method and category, close dates and similar descriptions
+// @Tags payments
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /payments/duplicates [get]
+func (h *PaymentHandler) GetDuplicates(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	groups, err := h.paymentService.GetSuspectedDuplicates(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Suspected duplicates retrieved successfully", groups)
+}
+
 // GetByID godoc
 // @Summary Get payment by ID
 // @Tags payments
@@ -231,6 +467,7 @@ func (h *PaymentHandler) Export(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Payment ID"
 // @Success 200 {object} utils.Response
+// @Deprecated
 // @Router /payments/{id} [get]
 func (h *PaymentHandler) GetByID(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -250,6 +487,7 @@ func (h *PaymentHandler) GetByID(c *gin.Context) {
 
 // Update godoc
 // @Summary Update payment
+// @Description The status and amount of a payment charged through a provider cannot be changed.
 // @Tags payments
 // @Accept json
 // @Produce json
@@ -257,6 +495,8 @@ func (h *PaymentHandler) GetByID(c *gin.Context) {
 // @Param id path string true "Payment ID"
 // @Param request body services.UpdatePaymentRequest true "Update Payment Request"
 // @Success 200 {object} utils.Response
+// @Failure 422 {object} utils.Response
+// @Deprecated
 // @Router /payments/{id} [put]
 func (h *PaymentHandler) Update(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
@@ -301,19 +541,42 @@ func (h *PaymentHandler) Update(c *gin.Context) {
 		return
 	}
 
+	accountID, err := parseOptionalUUID(req.AccountID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return
+	}
+
+	tax, err := paymentTax(req.Tax)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tax code ID")
+		return
+	}
+
 	// Convert to service request
 	serviceReq := &services.UpdatePaymentRequest{
 		Amount:          req.Amount,
+		Fee:             req.Fee,
 		Status:          req.Status,
+		Direction:       req.Direction,
 		CategoryID:      categoryID,
 		PaymentMethodID: paymentMethodID,
+		AccountID:       accountID,
 		Description:     req.Description,
+		Payee:           req.Payee,
+		Tags:            req.Tags,
 		TransactionDate: transactionDate,
+		Tax:             tax,
 	}
 
 	payment, err := h.paymentService.Update(id, serviceReq)
+	var limitErr *services.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		spendingLimitResponse(c, limitErr)
+		return
+	}
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.ErrorResponse(c, paymentErrorStatus(err), err.Error())
 		return
 	}
 
@@ -327,6 +590,7 @@ func (h *PaymentHandler) Update(c *gin.Context) {
 // @Security BearerAuth
 // @Param id path string true "Payment ID"
 // @Success 200 {object} utils.Response
+// @Deprecated
 // @Router /payments/{id} [delete]
 func (h *PaymentHandler) Delete(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
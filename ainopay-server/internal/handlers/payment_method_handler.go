@@ -22,6 +22,7 @@ func NewPaymentMethodHandler(paymentMethodRepo *repositories.PaymentMethodReposi
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} utils.Response
+// @Deprecated
 // @Router /payment-methods [get]
 func (h *PaymentMethodHandler) GetAll(c *gin.Context) {
 	methods, err := h.paymentMethodRepo.FindAll()
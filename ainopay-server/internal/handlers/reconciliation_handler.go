@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/statements"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+}
+
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationService: reconciliationService}
+}
+
+// ConfirmLineRequest represents the optional body for confirming a statement line
+type ConfirmLineRequest struct {
+	PaymentID string `json:"payment_id" validate:"omitempty,uuid4"`
+}
+
+// CreatePaymentFromLineRequest represents the request body for creating a payment from a statement line
+type CreatePaymentFromLineRequest struct {
+	CategoryID      string `json:"category_id" validate:"required,uuid4"`
+	PaymentMethodID string `json:"payment_method_id" validate:"required,uuid4"`
+	Description     string `json:"description" validate:"max=500"`
+}
+
+// reconciliationErrorStatus maps reconciliation service errors to HTTP status codes
+func reconciliationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSessionNotFound), errors.Is(err, services.ErrLineNotFound),
+		errors.Is(err, services.ErrPaymentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrSessionClosed), errors.Is(err, services.ErrLineAlreadyResolved),
+		errors.Is(err, services.ErrPaymentReconciled):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrLineNothingToMatch), errors.Is(err, services.ErrEmptyStatement):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// parseSessionAndLine parses the session and line IDs from the path
+func parseSessionAndLine(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	lineID, err := uuid.Parse(c.Param("line_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid line ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return sessionID, lineID, true
+}
+
+// Create godoc
+// @Summary Upload a bank statement and propose matches
+// @Description Accepts camt.053, MT940 or CSV statements. CSV files need a header row with date (YYYY-MM-DD), description, amount and optionally reference
+// @Tags reconciliations
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Bank statement"
+// @Param format formData string false "Statement format (camt053, mt940, csv); detected when omitted"
+// @Param name formData string false "Session name"
+// @Param date_window_days formData int false "Days either side of the statement date to search for payments" default(3)
+// @Success 201 {object} utils.Response
+// @Router /reconciliations [post]
+func (h *ReconciliationHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Statement file is required")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unable to read statement file")
+		return
+	}
+	defer file.Close()
+
+	parsed, err := statements.Parse(c.PostForm("format"), file)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	window, _ := strconv.Atoi(c.DefaultPostForm("date_window_days", "0"))
+
+	session, err := h.reconciliationService.CreateSession(id, &services.CreateReconciliationRequest{
+		Name:           c.PostForm("name"),
+		DateWindowDays: window,
+		Entries:        statements.Entries(parsed),
+	})
+	if err != nil {
+		utils.ErrorResponse(c, reconciliationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Reconciliation session created successfully", session)
+}
+
+// GetAll godoc
+// @Summary Get reconciliation sessions
+// @Tags reconciliations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /reconciliations [get]
+func (h *ReconciliationHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	sessions, err := h.reconciliationService.GetSessions(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reconciliation sessions retrieved successfully", sessions)
+}
+
+// GetByID godoc
+// @Summary Get reconciliation session with its lines
+// @Tags reconciliations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} utils.Response
+// @Router /reconciliations/{id} [get]
+func (h *ReconciliationHandler) GetByID(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	session, err := h.reconciliationService.GetSession(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, reconciliationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reconciliation session retrieved successfully", session)
+}
+
+// ConfirmLine godoc
+// @Summary Confirm a statement line match
+// @Description Confirms the proposed payment, or matches the line to the given payment_id
+// @Tags reconciliations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param line_id path string true "Line ID"
+// @Param request body ConfirmLineRequest false "Confirm Line Request"
+// @Success 200 {object} utils.Response
+// @Router /reconciliations/{id}/lines/{line_id}/confirm [post]
+func (h *ReconciliationHandler) ConfirmLine(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	sessionID, lineID, ok := parseSessionAndLine(c)
+	if !ok {
+		return
+	}
+
+	var req ConfirmLineRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+			return
+		}
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	var paymentID *uuid.UUID
+	if req.PaymentID != "" {
+		parsed, err := uuid.Parse(req.PaymentID)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+			return
+		}
+		paymentID = &parsed
+	}
+
+	session, err := h.reconciliationService.ConfirmLine(userID.(uuid.UUID), sessionID, lineID, paymentID)
+	if err != nil {
+		utils.ErrorResponse(c, reconciliationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Statement line confirmed successfully", session)
+}
+
+// RejectLine godoc
+// @Summary Reject a statement line match
+// @Tags reconciliations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param line_id path string true "Line ID"
+// @Success 200 {object} utils.Response
+// @Router /reconciliations/{id}/lines/{line_id}/reject [post]
+func (h *ReconciliationHandler) RejectLine(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	sessionID, lineID, ok := parseSessionAndLine(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.reconciliationService.RejectLine(userID.(uuid.UUID), sessionID, lineID)
+	if err != nil {
+		utils.ErrorResponse(c, reconciliationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Statement line rejected successfully", session)
+}
+
+// CreatePayment godoc
+// @Summary Create a payment from an unmatched statement line
+// @Tags reconciliations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param line_id path string true "Line ID"
+// @Param request body CreatePaymentFromLineRequest true "Create Payment Request"
+// @Success 201 {object} utils.Response
+// @Router /reconciliations/{id}/lines/{line_id}/create-payment [post]
+func (h *ReconciliationHandler) CreatePayment(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	sessionID, lineID, ok := parseSessionAndLine(c)
+	if !ok {
+		return
+	}
+
+	var req CreatePaymentFromLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return
+	}
+
+	// Parse UUIDs
+	categoryID, err := uuid.Parse(req.CategoryID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	paymentMethodID, err := uuid.Parse(req.PaymentMethodID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID")
+		return
+	}
+
+	session, err := h.reconciliationService.CreatePaymentFromLine(userID.(uuid.UUID), sessionID, lineID, &services.CreatePaymentFromLineRequest{
+		CategoryID:      categoryID,
+		PaymentMethodID: paymentMethodID,
+		Description:     req.Description,
+	})
+	var limitErr *services.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		spendingLimitResponse(c, limitErr)
+		return
+	}
+	if err != nil {
+		utils.ErrorResponse(c, reconciliationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Payment created from statement line successfully", session)
+}
+
+// Complete godoc
+// @Summary Complete a reconciliation session
+// @Tags reconciliations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} utils.Response
+// @Router /reconciliations/{id}/complete [post]
+func (h *ReconciliationHandler) Complete(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	session, err := h.reconciliationService.Complete(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, reconciliationErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reconciliation session completed successfully", session)
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// bindBreakdownRequest reads the grouping and filters of a breakdown report,
+// writing the error response on failure. The date range defaults to the
+// current month.
+func bindBreakdownRequest(c *gin.Context) (*services.BreakdownRequest, bool) {
+	loc := middleware.Location(c)
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if val := c.Query("start_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid start date format")
+			return nil, false
+		}
+		start = t
+	}
+
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	if val := c.Query("end_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid end date format")
+			return nil, false
+		}
+		// Set to end of day
+		end = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+	}
+
+	if start.After(end) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Start date must be before end date")
+		return nil, false
+	}
+
+	return &services.BreakdownRequest{
+		GroupBy:   c.Param("group"),
+		StartDate: &start,
+		EndDate:   &end,
+		Direction: c.Query("direction"),
+		Status:    c.Query("status"),
+	}, true
+}
+
+// bindTaxSummaryRequest reads the period and filters of a tax summary,
+// writing the error response on failure. The date range defaults to the
+// current year.
+func bindTaxSummaryRequest(c *gin.Context) (*services.TaxSummaryRequest, bool) {
+	loc := middleware.Location(c)
+	now := time.Now().In(loc)
+	req := &services.TaxSummaryRequest{
+		From:      time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, loc),
+		To:        time.Date(now.Year(), time.December, 31, 0, 0, 0, 0, loc),
+		Interval:  c.Query("interval"),
+		Direction: c.Query("direction"),
+		Location:  loc,
+	}
+	if val := c.Query("start_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid start date format")
+			return nil, false
+		}
+		req.From = t
+	}
+	if val := c.Query("end_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid end date format")
+			return nil, false
+		}
+		req.To = t
+	}
+
+	if req.From.After(req.To) {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Start date must be before end date")
+		return nil, false
+	}
+
+	return req, true
+}
+
+// reportErrorStatus maps report service errors to HTTP status codes
+func reportErrorStatus(err error) int {
+	if errors.Is(err, services.ErrInvalidBreakdown) || errors.Is(err, services.ErrInvalidTaxSummary) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// GetBreakdown godoc
+// @Summary Get payment breakdown
+// @Description Count, total, average and share of the total of the payments of each category, payment method or status in a date range
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Param group path string true "Grouping (category, payment-method, status)"
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: first day of the current month)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: last day of the start date's month)"
+// @Param direction query string false "Direction (income, expense, transfer; default: expense)"
+// @Param status query string false "Status (default: completed, or all for the status breakdown)"
+// @Success 200 {object} utils.Response
+// @Router /reports/breakdown/{group} [get]
+func (h *ReportHandler) GetBreakdown(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req, ok := bindBreakdownRequest(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportService.GetBreakdown(userID.(uuid.UUID), req)
+	if err != nil {
+		utils.ErrorResponse(c, reportErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Breakdown retrieved successfully", report)
+}
+
+// ExportBreakdown godoc
+// @Summary Export payment breakdown to CSV
+// @Tags reports
+// @Produce text/csv
+// @Security BearerAuth
+// @Param group path string true "Grouping (category, payment-method, status)"
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: first day of the current month)"
+// @Param end_date query string false "End date (YYYY-MM-DD, default: last day of the start date's month)"
+// @Param direction query string false "Direction (income, expense, transfer; default: expense)"
+// @Param status query string false "Status (default: completed, or all for the status breakdown)"
+// @Success 200 {file} file "breakdown.csv"
+// @Router /reports/breakdown/{group}/export [get]
+func (h *ReportHandler) ExportBreakdown(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req, ok := bindBreakdownRequest(c)
+	if !ok {
+		return
+	}
+
+	csvData, err := h.reportService.ExportBreakdown(userID.(uuid.UUID), req)
+	if err != nil {
+		utils.ErrorResponse(c, reportErrorStatus(err), err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename="+req.GroupBy+"-breakdown.csv")
+	c.Data(http.StatusOK, "text/csv", csvData)
+}
+
+// GetForecast godoc
+// @Summary Get spending forecast
+// @Description Expected expenses per category at the end of the current month and year, with an 80% confidence band.
+// @Description Pending payments dated in the period and recurring monthly payments found in the last six months are added as they are.
+// @Description Other spending is projected from the same month last year (seasonal_naive) when a category has a year of history, else from the average of the last three months (moving_average).
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=services.SpendingForecast}
+// @Router /reports/forecast [get]
+func (h *ReportHandler) GetForecast(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	forecast, err := h.reportService.GetSpendingForecast(userID.(uuid.UUID), middleware.Location(c), time.Now())
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Forecast retrieved successfully", forecast)
+}
+
+// GetTaxSummary godoc
+// @Summary Get tax summary
+// @Description Tax base (net), tax and gross amount of completed income and expenses with a tax code or tax amount, per period, tax code and direction.
+// @Description Totals give the output tax on income, the input tax on expenses and the difference payable.
+// @Tags reports
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: first day of the current year)"
+// @Param end_date query string false "End date, inclusive (YYYY-MM-DD, default: last day of the current year)"
+// @Param interval query string false "Period length (month, quarter, year; default: month)"
+// @Param direction query string false "Direction (income, expense; default: both)"
+// @Success 200 {object} utils.Response{data=services.TaxSummary}
+// @Router /reports/tax-summary [get]
+func (h *ReportHandler) GetTaxSummary(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req, ok := bindTaxSummaryRequest(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.reportService.GetTaxSummary(userID.(uuid.UUID), req)
+	if err != nil {
+		utils.ErrorResponse(c, reportErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tax summary retrieved successfully", summary)
+}
+
+// ExportTaxSummary godoc
+// @Summary Export tax summary to CSV
+// @Tags reports
+// @Produce text/csv
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD, default: first day of the current year)"
+// @Param end_date query string false "End date, inclusive (YYYY-MM-DD, default: last day of the current year)"
+// @Param interval query string false "Period length (month, quarter, year; default: month)"
+// @Param direction query string false "Direction (income, expense; default: both)"
+// @Success 200 {file} file "tax-summary.csv"
+// @Router /reports/tax-summary/export [get]
+func (h *ReportHandler) ExportTaxSummary(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req, ok := bindTaxSummaryRequest(c)
+	if !ok {
+		return
+	}
+
+	csvData, err := h.reportService.ExportTaxSummary(userID.(uuid.UUID), req)
+	if err != nil {
+		utils.ErrorResponse(c, reportErrorStatus(err), err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=tax-summary.csv")
+	c.Data(http.StatusOK, "text/csv", csvData)
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReportSubscriptionHandler struct {
+	subscriptionService *services.ReportSubscriptionService
+}
+
+func NewReportSubscriptionHandler(subscriptionService *services.ReportSubscriptionService) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{subscriptionService: subscriptionService}
+}
+
+// ReportSubscriptionRequest represents the request body for creating or updating a report subscription
+type ReportSubscriptionRequest struct {
+	Frequency string `json:"frequency" validate:"required,oneof=weekly monthly"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+// reportSubscriptionErrorStatus maps report subscription service errors to HTTP status codes
+func reportSubscriptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrReportSubscriptionNotFound), errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrReportSubscriptionExists):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidReportFrequency):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindReportSubscriptionRequest binds and validates a report subscription request, writing the error response on failure
+func bindReportSubscriptionRequest(c *gin.Context) (*services.ReportSubscriptionRequest, bool) {
+	var req ReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, false
+	}
+
+	return &services.ReportSubscriptionRequest{
+		Frequency: req.Frequency,
+		IsActive:  req.IsActive,
+	}, true
+}
+
+// Create godoc
+// @Summary Subscribe to summary emails
+// @Description Emails totals, top expense categories and pending payments after every week (Monday to Sunday) or calendar month in the user's timezone
+// @Tags report-subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ReportSubscriptionRequest true "Report Subscription Request"
+// @Success 201 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /report-subscriptions [post]
+func (h *ReportSubscriptionHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	req, ok := bindReportSubscriptionRequest(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.subscriptionService.Create(userID.(uuid.UUID), req)
+	if err != nil {
+		utils.ErrorResponse(c, reportSubscriptionErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Report subscription created successfully", subscription)
+}
+
+// GetAll godoc
+// @Summary Get report subscriptions
+// @Tags report-subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /report-subscriptions [get]
+func (h *ReportSubscriptionHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	subscriptions, err := h.subscriptionService.GetAll(userID.(uuid.UUID))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Report subscriptions retrieved successfully", subscriptions)
+}
+
+// Preview godoc
+// @Summary Preview summary email
+// @Description Renders the summary email of the last complete week or month. Returns the subject, HTML and plain-text body, or only the HTML or plain-text body when format is set.
+// @Tags report-subscriptions
+// @Produce json,html,plain
+// @Security BearerAuth
+// @Param frequency query string false "Frequency (weekly, monthly; default: weekly)"
+// @Param format query string false "Format (html, text)"
+// @Success 200 {object} utils.Response{data=services.SummaryEmail}
+// @Router /report-subscriptions/preview [get]
+func (h *ReportSubscriptionHandler) Preview(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	frequency := c.DefaultQuery("frequency", "weekly")
+	email, err := h.subscriptionService.Preview(userID.(uuid.UUID), frequency, middleware.Location(c))
+	if err != nil {
+		utils.ErrorResponse(c, reportSubscriptionErrorStatus(err), err.Error())
+		return
+	}
+
+	switch c.Query("format") {
+	case "html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(email.HTML))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(email.Text))
+	case "":
+		utils.SuccessResponse(c, http.StatusOK, "Summary email rendered successfully", email)
+	default:
+		utils.ErrorResponse(c, http.StatusBadRequest, "Format must be html or text")
+	}
+}
+
+// Update godoc
+// @Summary Update report subscription
+// @Description Changing the frequency or resuming a paused subscription starts it again with the current period
+// @Tags report-subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report subscription ID"
+// @Param request body ReportSubscriptionRequest true "Report Subscription Request"
+// @Success 200 {object} utils.Response
+// @Router /report-subscriptions/{id} [put]
+func (h *ReportSubscriptionHandler) Update(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid report subscription ID")
+		return
+	}
+
+	req, ok := bindReportSubscriptionRequest(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.subscriptionService.Update(userID.(uuid.UUID), id, req)
+	if err != nil {
+		utils.ErrorResponse(c, reportSubscriptionErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Report subscription updated successfully", subscription)
+}
+
+// Delete godoc
+// @Summary Delete report subscription
+// @Tags report-subscriptions
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Report subscription ID"
+// @Success 200 {object} utils.Response
+// @Router /report-subscriptions/{id} [delete]
+func (h *ReportSubscriptionHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid report subscription ID")
+		return
+	}
+
+	if err := h.subscriptionService.Delete(userID.(uuid.UUID), id); err != nil {
+		utils.ErrorResponse(c, reportSubscriptionErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Report subscription deleted successfully", nil)
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RiskHandler serves the admin API for risk rules and the review of held payments
+type RiskHandler struct {
+	riskService    *services.RiskService
+	paymentService *services.PaymentService
+}
+
+func NewRiskHandler(riskService *services.RiskService, paymentService *services.PaymentService) *RiskHandler {
+	return &RiskHandler{riskService: riskService, paymentService: paymentService}
+}
+
+// RiskRuleRequest represents the request body for creating or updating a risk rule
+type RiskRuleRequest struct {
+	Name     string                `json:"name" validate:"required,max=255"`
+	Type     string                `json:"type" validate:"required,oneof=amount_above amount_outlier velocity time_of_day"`
+	Params   models.RiskRuleParams `json:"params"`
+	Score    int                   `json:"score" validate:"required,gt=0"`
+	IsActive *bool                 `json:"is_active"`
+}
+
+// riskErrorStatus maps risk service errors to HTTP status codes
+func riskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrRiskRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidRiskRule):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindRiskRuleRequest binds and validates a risk rule request, writing the error response on failure
+func bindRiskRuleRequest(c *gin.Context) (*services.RiskRuleRequest, bool) {
+	var req RiskRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, false
+	}
+
+	return &services.RiskRuleRequest{
+		Name:     req.Name,
+		Type:     req.Type,
+		Params:   req.Params,
+		Score:    req.Score,
+		IsActive: req.IsActive,
+	}, true
+}
+
+// CreateRule godoc
+// @Summary Create risk rule
+// @Description Rules score every new payment; payments reaching the review score are held in the review status
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RiskRuleRequest true "Risk Rule Request"
+// @Success 201 {object} utils.Response
+// @Router /admin/risk-rules [post]
+func (h *RiskHandler) CreateRule(c *gin.Context) {
+	req, ok := bindRiskRuleRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.riskService.CreateRule(req)
+	if err != nil {
+		utils.ErrorResponse(c, riskErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Risk rule created successfully", rule)
+}
+
+// GetRules godoc
+// @Summary Get risk rules
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /admin/risk-rules [get]
+func (h *RiskHandler) GetRules(c *gin.Context) {
+	rules, err := h.riskService.GetRules()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Risk rules retrieved successfully", rules)
+}
+
+// GetRule godoc
+// @Summary Get risk rule by ID
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Risk rule ID"
+// @Success 200 {object} utils.Response
+// @Router /admin/risk-rules/{id} [get]
+func (h *RiskHandler) GetRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid risk rule ID")
+		return
+	}
+
+	rule, err := h.riskService.GetRule(id)
+	if err != nil {
+		utils.ErrorResponse(c, riskErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Risk rule retrieved successfully", rule)
+}
+
+// UpdateRule godoc
+// @Summary Update risk rule
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Risk rule ID"
+// @Param request body RiskRuleRequest true "Risk Rule Request"
+// @Success 200 {object} utils.Response
+// @Router /admin/risk-rules/{id} [put]
+func (h *RiskHandler) UpdateRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid risk rule ID")
+		return
+	}
+
+	req, ok := bindRiskRuleRequest(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.riskService.UpdateRule(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, riskErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Risk rule updated successfully", rule)
+}
+
+// DeleteRule godoc
+// @Summary Delete risk rule
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Risk rule ID"
+// @Success 200 {object} utils.Response
+// @Router /admin/risk-rules/{id} [delete]
+func (h *RiskHandler) DeleteRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid risk rule ID")
+		return
+	}
+
+	if err := h.riskService.DeleteRule(id); err != nil {
+		utils.ErrorResponse(c, riskErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Risk rule deleted successfully", nil)
+}
+
+// GetReviewQueue godoc
+// @Summary Get payments held for review
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /admin/payments/review [get]
+func (h *RiskHandler) GetReviewQueue(c *gin.Context) {
+	payments, err := h.paymentService.GetInReview()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payments in review retrieved successfully", payments)
+}
+
+// Approve godoc
+// @Summary Approve a payment held for review
+// @Description Moves the payment to the status it was created with
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Router /admin/payments/{id}/approve [post]
+func (h *RiskHandler) Approve(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+		return
+	}
+
+	payment, err := h.paymentService.ApproveReview(id)
+	if err != nil {
+		utils.ErrorResponse(c, paymentErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payment approved successfully", payment)
+}
+
+// Reject godoc
+// @Summary Reject a payment held for review
+// @Description Marks the payment as failed
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} utils.Response
+// @Router /admin/payments/{id}/reject [post]
+func (h *RiskHandler) Reject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+		return
+	}
+
+	payment, err := h.paymentService.RejectReview(id)
+	if err != nil {
+		utils.ErrorResponse(c, paymentErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Payment rejected successfully", payment)
+}
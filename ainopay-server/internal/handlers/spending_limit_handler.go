@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SpendingLimitHandler serves both the user's own limits and, under
+// /admin/users/:user_id, the limits admins set for any user
+type SpendingLimitHandler struct {
+	limitService *services.SpendingLimitService
+}
+
+func NewSpendingLimitHandler(limitService *services.SpendingLimitService) *SpendingLimitHandler {
+	return &SpendingLimitHandler{limitService: limitService}
+}
+
+// SpendingLimitRequest represents the request body for creating or updating a spending limit
+type SpendingLimitRequest struct {
+	PaymentMethodID string  `json:"payment_method_id" validate:"omitempty,uuid4"`
+	CategoryID      string  `json:"category_id" validate:"omitempty,uuid4"`
+	Period          string  `json:"period" validate:"required,oneof=daily monthly"`
+	Amount          float64 `json:"amount" validate:"required,gt=0"`
+	Action          string  `json:"action" validate:"omitempty,oneof=reject flag"`
+}
+
+// spendingLimitErrorStatus maps spending limit service errors to HTTP status codes
+func spendingLimitErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSpendingLimitNotFound), errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrSpendingLimitLocked):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidSpendingLimit):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// limitOwner returns the user whose limits are managed and whether an admin
+// is managing them, writing the error response on failure
+func limitOwner(c *gin.Context) (uuid.UUID, bool, bool) {
+	if param := c.Param("user_id"); param != "" {
+		userID, err := uuid.Parse(param)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid user ID")
+			return uuid.Nil, false, false
+		}
+		return userID, true, true
+	}
+
+	userID, _ := c.Get("user_id")
+	return userID.(uuid.UUID), false, true
+}
+
+// bindSpendingLimitRequest binds and validates a spending limit request, writing the error response on failure
+func bindSpendingLimitRequest(c *gin.Context) (*services.SpendingLimitRequest, bool) {
+	var req SpendingLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, false
+	}
+
+	paymentMethodID, err := parseOptionalUUID(req.PaymentMethodID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID")
+		return nil, false
+	}
+
+	categoryID, err := parseOptionalUUID(req.CategoryID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+		return nil, false
+	}
+
+	return &services.SpendingLimitRequest{
+		PaymentMethodID: paymentMethodID,
+		CategoryID:      categoryID,
+		Period:          req.Period,
+		Amount:          req.Amount,
+		Action:          req.Action,
+	}, true
+}
+
+// Create godoc
+// @Summary Create spending limit
+// @Description Caps daily or monthly expenses (fees included), optionally per payment method and category. Payments that would exceed a limit are rejected, or flagged as over_limit when action is flag.
+// @Tags spending-limits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SpendingLimitRequest true "Spending Limit Request"
+// @Success 201 {object} utils.Response
+// @Router /spending-limits [post]
+// @Router /admin/users/{user_id}/spending-limits [post]
+func (h *SpendingLimitHandler) Create(c *gin.Context) {
+	userID, byAdmin, ok := limitOwner(c)
+	if !ok {
+		return
+	}
+
+	req, ok := bindSpendingLimitRequest(c)
+	if !ok {
+		return
+	}
+
+	limit, err := h.limitService.Create(userID, byAdmin, req)
+	if err != nil {
+		utils.ErrorResponse(c, spendingLimitErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Spending limit created successfully", limit)
+}
+
+// GetAll godoc
+// @Summary Get spending limits
+// @Tags spending-limits
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /spending-limits [get]
+// @Router /admin/users/{user_id}/spending-limits [get]
+func (h *SpendingLimitHandler) GetAll(c *gin.Context) {
+	userID, _, ok := limitOwner(c)
+	if !ok {
+		return
+	}
+
+	limits, err := h.limitService.GetAll(userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Spending limits retrieved successfully", limits)
+}
+
+// GetUsage godoc
+// @Summary Get usage against spending limits
+// @Description Spending and remaining headroom of every limit in its period containing date. Periods are days and months in the timezone of the limits' owner.
+// @Tags spending-limits
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Date (YYYY-MM-DD), defaults to today in the owner's timezone"
+// @Success 200 {object} utils.Response
+// @Router /spending-limits/usage [get]
+// @Router /admin/users/{user_id}/spending-limits/usage [get]
+func (h *SpendingLimitHandler) GetUsage(c *gin.Context) {
+	userID, _, ok := limitOwner(c)
+	if !ok {
+		return
+	}
+
+	var date *time.Time
+	if val := c.Query("date"); val != "" {
+		t, err := time.Parse("2006-01-02", val)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid date format, expected YYYY-MM-DD")
+			return
+		}
+		date = &t
+	}
+
+	usage, err := h.limitService.GetUsage(userID, date)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Spending limit usage retrieved successfully", usage)
+}
+
+// Update godoc
+// @Summary Update spending limit
+// @Description Users cannot change limits set by an admin
+// @Tags spending-limits
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Spending limit ID"
+// @Param request body SpendingLimitRequest true "Spending Limit Request"
+// @Success 200 {object} utils.Response
+// @Router /spending-limits/{id} [put]
+// @Router /admin/users/{user_id}/spending-limits/{id} [put]
+func (h *SpendingLimitHandler) Update(c *gin.Context) {
+	userID, byAdmin, ok := limitOwner(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid spending limit ID")
+		return
+	}
+
+	req, ok := bindSpendingLimitRequest(c)
+	if !ok {
+		return
+	}
+
+	limit, err := h.limitService.Update(userID, id, byAdmin, req)
+	if err != nil {
+		utils.ErrorResponse(c, spendingLimitErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Spending limit updated successfully", limit)
+}
+
+// Delete godoc
+// @Summary Delete spending limit
+// @Description Users cannot remove limits set by an admin
+// @Tags spending-limits
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Spending limit ID"
+// @Success 200 {object} utils.Response
+// @Router /spending-limits/{id} [delete]
+// @Router /admin/users/{user_id}/spending-limits/{id} [delete]
+func (h *SpendingLimitHandler) Delete(c *gin.Context) {
+	userID, byAdmin, ok := limitOwner(c)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid spending limit ID")
+		return
+	}
+
+	if err := h.limitService.Delete(userID, id, byAdmin); err != nil {
+		utils.ErrorResponse(c, spendingLimitErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Spending limit deleted successfully", nil)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"ainopay-server/internal/statements"
+	"ainopay-server/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatementHandler struct{}
+
+func NewStatementHandler() *StatementHandler {
+	return &StatementHandler{}
+}
+
+// Parse godoc
+// @Summary Parse a bank statement
+// @Description Normalizes a camt.053, MT940 or CSV statement into a list of entries without storing anything
+// @Tags statements
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Bank statement"
+// @Param format formData string false "Statement format (camt053, mt940, csv); detected when omitted"
+// @Success 200 {object} utils.Response
+// @Router /statements/parse [post]
+func (h *StatementHandler) Parse(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Statement file is required")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Unable to read statement file")
+		return
+	}
+	defer file.Close()
+
+	parsed, err := statements.Parse(c.PostForm("format"), file)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Statement parsed successfully", parsed)
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TaxHandler serves the active tax codes to users and the admin API that
+// configures them
+type TaxHandler struct {
+	taxService *services.TaxService
+}
+
+func NewTaxHandler(taxService *services.TaxService) *TaxHandler {
+	return &TaxHandler{taxService: taxService}
+}
+
+// TaxCodeRequest represents the request body for creating or updating a tax code
+type TaxCodeRequest struct {
+	Code        string  `json:"code" validate:"required,max=20"`
+	Name        string  `json:"name" validate:"required,max=255"`
+	Rate        float64 `json:"rate" validate:"gte=0,lte=100"` // percent
+	Description string  `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// taxErrorStatus maps tax service errors to HTTP status codes
+func taxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTaxCodeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTaxCodeExists), errors.Is(err, services.ErrTaxCodeInUse):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidTax):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindTaxCodeRequest binds and validates a tax code request, writing the error response on failure
+func bindTaxCodeRequest(c *gin.Context) (*services.TaxCodeRequest, bool) {
+	var req TaxCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, false
+	}
+
+	return &services.TaxCodeRequest{
+		Code:        req.Code,
+		Name:        req.Name,
+		Rate:        req.Rate,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+	}, true
+}
+
+// GetCodes godoc
+// @Summary Get tax codes
+// @Description Active tax codes that can be set on payments
+// @Tags tax-codes
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /tax-codes [get]
+func (h *TaxHandler) GetCodes(c *gin.Context) {
+	codes, err := h.taxService.GetCodes(false)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tax codes retrieved successfully", codes)
+}
+
+// GetAllCodes godoc
+// @Summary Get all tax codes
+// @Description Active and inactive tax codes
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /admin/tax-codes [get]
+func (h *TaxHandler) GetAllCodes(c *gin.Context) {
+	codes, err := h.taxService.GetCodes(true)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tax codes retrieved successfully", codes)
+}
+
+// CreateCode godoc
+// @Summary Create tax code
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body TaxCodeRequest true "Tax Code Request"
+// @Success 201 {object} utils.Response
+// @Router /admin/tax-codes [post]
+func (h *TaxHandler) CreateCode(c *gin.Context) {
+	req, ok := bindTaxCodeRequest(c)
+	if !ok {
+		return
+	}
+
+	code, err := h.taxService.CreateCode(req)
+	if err != nil {
+		utils.ErrorResponse(c, taxErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tax code created successfully", code)
+}
+
+// UpdateCode godoc
+// @Summary Update tax code
+// @Description Payments keep the rate and tax amount they were recorded with
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax code ID"
+// @Param request body TaxCodeRequest true "Tax Code Request"
+// @Success 200 {object} utils.Response
+// @Router /admin/tax-codes/{id} [put]
+func (h *TaxHandler) UpdateCode(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tax code ID")
+		return
+	}
+
+	req, ok := bindTaxCodeRequest(c)
+	if !ok {
+		return
+	}
+
+	code, err := h.taxService.UpdateCode(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, taxErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tax code updated successfully", code)
+}
+
+// DeleteCode godoc
+// @Summary Delete tax code
+// @Description Tax codes used by payments cannot be deleted, only deactivated
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tax code ID"
+// @Success 200 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/tax-codes/{id} [delete]
+func (h *TaxHandler) DeleteCode(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid tax code ID")
+		return
+	}
+
+	if err := h.taxService.DeleteCode(id); err != nil {
+		utils.ErrorResponse(c, taxErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tax code deleted successfully", nil)
+}
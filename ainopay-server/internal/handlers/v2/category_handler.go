@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"ainopay-server/internal/repositories"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryRepo      *repositories.CategoryRepository
+	paymentMethodRepo *repositories.PaymentMethodRepository
+}
+
+func NewCategoryHandler(categoryRepo *repositories.CategoryRepository, paymentMethodRepo *repositories.PaymentMethodRepository) *CategoryHandler {
+	return &CategoryHandler{categoryRepo: categoryRepo, paymentMethodRepo: paymentMethodRepo}
+}
+
+// GetCategories godoc
+// @Summary Get all categories
+// @Tags v2
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]Category}
+// @Router /v2/categories [get]
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	categories, err := h.categoryRepo.FindAll()
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	data := make([]Category, len(categories))
+	for i := range categories {
+		data[i] = categoryDTO(&categories[i])
+	}
+	SuccessResponse(c, http.StatusOK, data)
+}
+
+// GetPaymentMethods godoc
+// @Summary Get all payment methods
+// @Tags v2
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]PaymentMethod}
+// @Router /v2/payment-methods [get]
+func (h *CategoryHandler) GetPaymentMethods(c *gin.Context) {
+	methods, err := h.paymentMethodRepo.FindAll()
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	data := make([]PaymentMethod, len(methods))
+	for i := range methods {
+		data[i] = paymentMethodDTO(&methods[i])
+	}
+	SuccessResponse(c, http.StatusOK, data)
+}
@@ -0,0 +1,73 @@
+package v2
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DashboardHandler struct {
+	reportService *services.ReportService
+}
+
+func NewDashboardHandler(reportService *services.ReportService) *DashboardHandler {
+	return &DashboardHandler{reportService: reportService}
+}
+
+// GetStats godoc
+// @Summary Get dashboard statistics
+// @Description Totals of completed payments for all time or a period, given as a preset or a from/to date range, optionally compared with the previous period.
+// @Tags v2
+// @Produce json
+// @Security BearerAuth
+// @Param preset query string false "Preset period (this_month, last_month, this_quarter, last_quarter, ytd, last_year)"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD, default: today when from is set)"
+// @Param compare query bool false "Compare with the previous period (default: true)"
+// @Success 200 {object} Response{data=DashboardStats}
+// @Failure 400 {object} Response
+// @Router /v2/dashboard/stats [get]
+func (h *DashboardHandler) GetStats(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	loc := middleware.Location(c)
+	req := &services.StatsRequest{Preset: c.Query("preset"), Compare: true, Location: loc}
+	if val := c.Query("from"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid from date format, expected YYYY-MM-DD")
+			return
+		}
+		req.From = &t
+	}
+	if val := c.Query("to"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid to date format, expected YYYY-MM-DD")
+			return
+		}
+		req.To = &t
+	}
+	if val := c.Query("compare"); val != "" {
+		compare, err := strconv.ParseBool(val)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid compare value")
+			return
+		}
+		req.Compare = compare
+	}
+
+	stats, err := h.reportService.GetDashboardStats(id, req)
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, http.StatusOK, dashboardStatsDTO(stats))
+}
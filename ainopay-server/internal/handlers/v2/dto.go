@@ -0,0 +1,225 @@
+package v2
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/services"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment is a payment as returned by the v2 API
+type Payment struct {
+	ID              uuid.UUID        `json:"id"`
+	Amount          float64          `json:"amount"`
+	Fee             float64          `json:"fee"`
+	Status          string           `json:"status"`    // pending, review, completed, failed, refunded
+	Direction       string           `json:"direction"` // income, expense, transfer
+	Category        CategoryRef      `json:"category"`
+	PaymentMethod   PaymentMethodRef `json:"payment_method"`
+	AccountID       *uuid.UUID       `json:"account_id"`
+	InvoiceID       *uuid.UUID       `json:"invoice_id"`
+	Description     string           `json:"description"`
+	Payee           string           `json:"payee"`
+	Tags            []string         `json:"tags"`
+	TransactionDate time.Time        `json:"transaction_date"`
+	Tax             *PaymentTax      `json:"tax"` // null when the payment has no tax
+	Reconciled      bool             `json:"reconciled"`
+	OverLimit       bool             `json:"over_limit"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+}
+
+// CategoryRef identifies the category of a payment
+type CategoryRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// PaymentMethodRef identifies the payment method of a payment
+type PaymentMethodRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Code string    `json:"code"`
+}
+
+// PaymentTax is the tax of a payment
+type PaymentTax struct {
+	TaxCodeID     *uuid.UUID `json:"tax_code_id"`
+	Rate          float64    `json:"rate"` // percent
+	Amount        float64    `json:"amount"`
+	Inclusive     bool       `json:"inclusive"` // the amount includes the tax
+	InvoiceNumber string     `json:"invoice_number"`
+}
+
+// Category is a category as returned by the v2 API
+type Category struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+}
+
+// PaymentMethod is a payment method as returned by the v2 API
+type PaymentMethod struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Code     string    `json:"code"`
+	IsActive bool      `json:"is_active"`
+}
+
+// LimitViolation is a spending limit a payment would exceed
+type LimitViolation struct {
+	LimitID   uuid.UUID `json:"limit_id"`
+	Period    string    `json:"period"` // daily, monthly
+	Limit     float64   `json:"limit"`
+	Used      float64   `json:"used"`      // spent in the period without this payment
+	Remaining float64   `json:"remaining"` // headroom left for this payment
+	Amount    float64   `json:"amount"`    // the payment's amount plus fee
+}
+
+// PaymentStatistics are the totals of the payments of a period
+type PaymentStatistics struct {
+	TotalPayments  int64   `json:"total_payments"`
+	CompletedCount int64   `json:"completed_count"`
+	PendingCount   int64   `json:"pending_count"`
+	TotalIncome    float64 `json:"total_income"`
+	TotalExpense   float64 `json:"total_expense"`
+	TotalFees      float64 `json:"total_fees"`
+	Net            float64 `json:"net"` // income minus expenses and fees
+}
+
+// StatsPeriod is the date range statistics cover
+type StatsPeriod struct {
+	Preset string `json:"preset,omitempty"`
+	From   string `json:"from,omitempty"` // YYYY-MM-DD
+	To     string `json:"to,omitempty"`   // YYYY-MM-DD, inclusive
+}
+
+// StatChange is the change of a statistic from the previous period
+type StatChange struct {
+	Absolute float64  `json:"absolute"`
+	Percent  *float64 `json:"percent"` // null when the previous value is zero
+}
+
+type StatsChanges struct {
+	TotalPayments  StatChange `json:"total_payments"`
+	CompletedCount StatChange `json:"completed_count"`
+	PendingCount   StatChange `json:"pending_count"`
+	TotalIncome    StatChange `json:"total_income"`
+	TotalExpense   StatChange `json:"total_expense"`
+	TotalFees      StatChange `json:"total_fees"`
+	Net            StatChange `json:"net"`
+}
+
+// StatsComparison compares statistics with those of the previous period
+type StatsComparison struct {
+	Period   StatsPeriod       `json:"period"`
+	Previous PaymentStatistics `json:"previous"`
+	Changes  StatsChanges      `json:"changes"`
+}
+
+// DashboardStats are the statistics of a period, optionally compared with
+// the previous period
+type DashboardStats struct {
+	Period     StatsPeriod       `json:"period"`
+	Statistics PaymentStatistics `json:"statistics"`
+	Comparison *StatsComparison  `json:"comparison"` // null when not compared
+}
+
+// paymentDTO converts a payment loaded with its category and payment method
+func paymentDTO(p *models.Payment) Payment {
+	payment := Payment{
+		ID:              p.ID,
+		Amount:          p.Amount,
+		Fee:             p.Fee,
+		Status:          p.Status,
+		Direction:       p.Direction,
+		Category:        CategoryRef{ID: p.CategoryID, Name: p.Category.Name},
+		PaymentMethod:   PaymentMethodRef{ID: p.PaymentMethodID, Name: p.PaymentMethod.Name, Code: p.PaymentMethod.Code},
+		AccountID:       p.AccountID,
+		InvoiceID:       p.InvoiceID,
+		Description:     p.Description,
+		Payee:           p.Payee,
+		Tags:            p.Tags,
+		TransactionDate: p.TransactionDate,
+		Reconciled:      p.Reconciled,
+		OverLimit:       p.OverLimit,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+	if payment.Tags == nil {
+		payment.Tags = []string{}
+	}
+	if p.TaxCodeID != nil || p.TaxAmount != 0 {
+		payment.Tax = &PaymentTax{
+			TaxCodeID:     p.TaxCodeID,
+			Rate:          p.TaxRate,
+			Amount:        p.TaxAmount,
+			Inclusive:     p.TaxInclusive,
+			InvoiceNumber: p.TaxInvoiceNumber,
+		}
+	}
+	return payment
+}
+
+func categoryDTO(c *models.Category) Category {
+	return Category{ID: c.ID, Name: c.Name, Description: c.Description}
+}
+
+func paymentMethodDTO(m *models.PaymentMethod) PaymentMethod {
+	return PaymentMethod{ID: m.ID, Name: m.Name, Code: m.Code, IsActive: m.IsActive}
+}
+
+func limitViolationDTO(v *services.LimitViolation) LimitViolation {
+	return LimitViolation{
+		LimitID:   v.LimitID,
+		Period:    v.Period,
+		Limit:     v.Limit,
+		Used:      v.Used,
+		Remaining: v.Remaining,
+		Amount:    v.Amount,
+	}
+}
+
+func statisticsDTO(s *models.PaymentStatistics) PaymentStatistics {
+	return PaymentStatistics{
+		TotalPayments:  s.TotalPayments,
+		CompletedCount: s.CompletedCount,
+		PendingCount:   s.PendingCount,
+		TotalIncome:    s.TotalIncome,
+		TotalExpense:   s.TotalExpense,
+		TotalFees:      s.TotalFees,
+		Net:            s.Net,
+	}
+}
+
+func statsPeriodDTO(p *services.StatsPeriod) StatsPeriod {
+	return StatsPeriod{Preset: p.Preset, From: p.From, To: p.To}
+}
+
+func statChangeDTO(c *services.StatChange) StatChange {
+	return StatChange{Absolute: c.Absolute, Percent: c.Percent}
+}
+
+func dashboardStatsDTO(stats *services.DashboardStats) DashboardStats {
+	response := DashboardStats{
+		Period:     statsPeriodDTO(&stats.Period),
+		Statistics: statisticsDTO(&stats.PaymentStatistics),
+	}
+	if c := stats.Comparison; c != nil {
+		response.Comparison = &StatsComparison{
+			Period:   statsPeriodDTO(&c.Period),
+			Previous: statisticsDTO(&c.Previous),
+			Changes: StatsChanges{
+				TotalPayments:  statChangeDTO(&c.Changes.TotalPayments),
+				CompletedCount: statChangeDTO(&c.Changes.CompletedCount),
+				PendingCount:   statChangeDTO(&c.Changes.PendingCount),
+				TotalIncome:    statChangeDTO(&c.Changes.TotalIncome),
+				TotalExpense:   statChangeDTO(&c.Changes.TotalExpense),
+				TotalFees:      statChangeDTO(&c.Changes.TotalFees),
+				Net:            statChangeDTO(&c.Changes.Net),
+			},
+		}
+	}
+	return response
+}
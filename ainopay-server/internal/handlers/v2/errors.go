@@ -0,0 +1,53 @@
+package v2
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// validationResponse reports the fields of a request that failed validation
+func validationResponse(c *gin.Context, validationErrors []middleware.ValidationError) {
+	CodeErrorResponse(c, http.StatusBadRequest, CodeValidationFailed, "Validation failed", validationErrors)
+}
+
+// serviceErrorResponse reports a service error with the status code and
+// error code it maps to
+func serviceErrorResponse(c *gin.Context, err error) {
+	var duplicateErr *services.DuplicatePaymentError
+	if errors.As(err, &duplicateErr) {
+		CodeErrorResponse(c, http.StatusConflict, CodeDuplicatePayment, "Possible duplicate payment; resubmit with force set to create it anyway", gin.H{
+			"candidate_ids": duplicateErr.CandidateIDs,
+		})
+		return
+	}
+	var limitErr *services.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		violations := make([]LimitViolation, len(limitErr.Violations))
+		for i := range limitErr.Violations {
+			violations[i] = limitViolationDTO(&limitErr.Violations[i])
+		}
+		CodeErrorResponse(c, http.StatusUnprocessableEntity, CodeSpendingLimit, err.Error(), gin.H{
+			"violations": violations,
+		})
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrFinancialAccountNotFound), errors.Is(err, services.ErrFinancialAccountInactive),
+		errors.Is(err, services.ErrCategoryRequired), errors.Is(err, services.ErrTaxCodeNotFound),
+		errors.Is(err, services.ErrTaxCodeInactive), errors.Is(err, services.ErrInvalidTax),
+		errors.Is(err, services.ErrInvalidStatsPeriod):
+		ErrorResponse(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrPaymentNotFound):
+		ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPaymentInReview), errors.Is(err, services.ErrPaymentNotInReview),
+		errors.Is(err, services.ErrPaymentCharged):
+		ErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,489 @@
+package v2
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Page sizes of lists
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+	paymentRepo    *repositories.PaymentRepository
+}
+
+func NewPaymentHandler(paymentService *services.PaymentService, paymentRepo *repositories.PaymentRepository) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService, paymentRepo: paymentRepo}
+}
+
+// CreatePaymentRequest represents the request body for creating a payment
+type CreatePaymentRequest struct {
+	Amount          float64            `json:"amount" validate:"required,gt=0"`
+	Fee             float64            `json:"fee" validate:"gte=0"`
+	Direction       string             `json:"direction" validate:"omitempty,oneof=income expense transfer"` // default: expense
+	CategoryID      string             `json:"category_id" validate:"omitempty,uuid4"`                       // set by categorization rules when omitted
+	PaymentMethodID string             `json:"payment_method_id" validate:"required,uuid4"`
+	AccountID       string             `json:"account_id" validate:"omitempty,uuid4"`
+	Description     string             `json:"description" validate:"max=500"`
+	Payee           string             `json:"payee" validate:"max=255"`
+	Tags            []string           `json:"tags" validate:"max=20,dive,max=50"`
+	TransactionDate string             `json:"transaction_date" validate:"required"` // RFC 3339
+	Tax             *PaymentTaxRequest `json:"tax"`
+	Force           bool               `json:"force"` // create even if the payment looks like a duplicate
+}
+
+// UpdatePaymentRequest represents the request body for changing a payment.
+// Every field is optional; omitted fields keep their current value.
+type UpdatePaymentRequest struct {
+	Amount          *float64           `json:"amount" validate:"omitempty,gt=0"`
+	Fee             *float64           `json:"fee" validate:"omitempty,gte=0"`
+	Status          *string            `json:"status" validate:"omitempty,oneof=pending completed failed refunded"`
+	Direction       *string            `json:"direction" validate:"omitempty,oneof=income expense transfer"`
+	CategoryID      *string            `json:"category_id" validate:"omitempty,uuid4"`
+	PaymentMethodID *string            `json:"payment_method_id" validate:"omitempty,uuid4"`
+	AccountID       *string            `json:"account_id" validate:"omitempty,uuid4"`
+	Description     *string            `json:"description" validate:"omitempty,max=500"`
+	Payee           *string            `json:"payee" validate:"omitempty,max=255"`
+	Tags            []string           `json:"tags" validate:"omitempty,max=20,dive,max=50"`
+	TransactionDate *string            `json:"transaction_date"` // RFC 3339
+	Tax             *PaymentTaxRequest `json:"tax"`              // the tax amount follows the amount when omitted
+}
+
+// PaymentTaxRequest represents the tax of a payment
+type PaymentTaxRequest struct {
+	TaxCodeID     string   `json:"tax_code_id" validate:"omitempty,uuid4"`
+	Rate          *float64 `json:"rate" validate:"omitempty,gte=0,lte=100"` // percent, defaults to the tax code's rate
+	Amount        *float64 `json:"amount" validate:"omitempty,gte=0"`       // computed from the rate when omitted
+	Inclusive     *bool    `json:"inclusive"`                               // the amount includes the tax, default: true
+	InvoiceNumber string   `json:"invoice_number" validate:"max=50"`
+}
+
+// paymentRefs holds the parsed references of a payment request
+type paymentRefs struct {
+	categoryID      uuid.UUID
+	paymentMethodID uuid.UUID
+	accountID       *uuid.UUID
+	tax             *services.PaymentTaxRequest
+}
+
+// parsePaymentRefs parses the IDs and tax of a validated payment request,
+// writing the error response on failure
+func parsePaymentRefs(c *gin.Context, categoryID, paymentMethodID, accountID string, tax *PaymentTaxRequest) (*paymentRefs, bool) {
+	refs := &paymentRefs{}
+	var err error
+	if categoryID != "" {
+		if refs.categoryID, err = uuid.Parse(categoryID); err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid category ID")
+			return nil, false
+		}
+	}
+	if refs.paymentMethodID, err = uuid.Parse(paymentMethodID); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid payment method ID")
+		return nil, false
+	}
+	if refs.accountID, err = parseOptionalUUID(accountID); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid account ID")
+		return nil, false
+	}
+	if tax != nil {
+		taxCodeID, err := parseOptionalUUID(tax.TaxCodeID)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid tax code ID")
+			return nil, false
+		}
+		refs.tax = &services.PaymentTaxRequest{
+			TaxCodeID:     taxCodeID,
+			Rate:          tax.Rate,
+			Amount:        tax.Amount,
+			Inclusive:     tax.Inclusive,
+			InvoiceNumber: tax.InvoiceNumber,
+		}
+	}
+	return refs, true
+}
+
+// parseOptionalUUID parses an optional ID, returning nil for an empty string
+func parseOptionalUUID(val string) (*uuid.UUID, error) {
+	if val == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(val)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// bindPaymentFilter reads the filters of a payment list, writing the error
+// response on failure. Unlike v1, invalid values are rejected rather than
+// ignored.
+func bindPaymentFilter(c *gin.Context) (repositories.PaymentFilter, bool) {
+	filter := repositories.PaymentFilter{
+		Status:    c.Query("status"),
+		Direction: c.Query("direction"),
+		Search:    c.Query("search"),
+	}
+
+	switch filter.Status {
+	case "", "pending", "review", "completed", "failed", "refunded":
+	default:
+		ErrorResponse(c, http.StatusBadRequest, "Invalid status, expected pending, review, completed, failed or refunded")
+		return filter, false
+	}
+	switch filter.Direction {
+	case "", models.PaymentDirectionIncome, models.PaymentDirectionExpense, models.PaymentDirectionTransfer:
+	default:
+		ErrorResponse(c, http.StatusBadRequest, "Invalid direction, expected income, expense or transfer")
+		return filter, false
+	}
+
+	if val := c.Query("min_amount"); val != "" {
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid min_amount")
+			return filter, false
+		}
+		filter.MinAmount = &v
+	}
+	if val := c.Query("max_amount"); val != "" {
+		v, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid max_amount")
+			return filter, false
+		}
+		filter.MaxAmount = &v
+	}
+
+	loc := middleware.Location(c)
+	if val := c.Query("start_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid start_date format, expected YYYY-MM-DD")
+			return filter, false
+		}
+		filter.StartDate = &t
+	}
+	if val := c.Query("end_date"); val != "" {
+		t, err := time.ParseInLocation("2006-01-02", val, loc)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid end_date format, expected YYYY-MM-DD")
+			return filter, false
+		}
+		// Set to end of day
+		t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		filter.EndDate = &t
+	}
+
+	if val := c.Query("reconciled"); val != "" {
+		reconciled, err := strconv.ParseBool(val)
+		if err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid reconciled value")
+			return filter, false
+		}
+		filter.Reconciled = &reconciled
+	}
+
+	var err error
+	if filter.CategoryID, err = parseOptionalUUID(c.Query("category_id")); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid category_id")
+		return filter, false
+	}
+	if filter.PaymentMethodID, err = parseOptionalUUID(c.Query("payment_method_id")); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid payment_method_id")
+		return filter, false
+	}
+	return filter, true
+}
+
+// bindPage reads the page and page size of a list, writing the error
+// response on failure
+func bindPage(c *gin.Context) (int, int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		ErrorResponse(c, http.StatusBadRequest, "page must be a positive integer")
+		return 0, 0, false
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultPageSize)))
+	if err != nil || pageSize < 1 || pageSize > maxPageSize {
+		ErrorResponse(c, http.StatusBadRequest, "page_size must be between 1 and "+strconv.Itoa(maxPageSize))
+		return 0, 0, false
+	}
+	return page, pageSize, true
+}
+
+// ownedPayment reads the payment of the id path parameter, writing the error
+// response when it is invalid or not the user's
+func (h *PaymentHandler) ownedPayment(c *gin.Context) (*models.Payment, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+		return nil, false
+	}
+
+	userID, _ := c.Get("user_id")
+	payment, err := h.paymentService.GetOwned(userID.(uuid.UUID), id)
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return nil, false
+	}
+	return payment, true
+}
+
+// stringOr returns *s, or fallback when s is nil
+func stringOr(s *string, fallback string) string {
+	if s == nil {
+		return fallback
+	}
+	return *s
+}
+
+// Create godoc
+// @Summary Create new payment
+// @Description The category, payee and tags are completed by the user's categorization rules.
+// @Description Fails with DUPLICATE_PAYMENT (409) when the payment looks like a duplicate unless force is set, and with SPENDING_LIMIT_EXCEEDED (422) when it would exceed a spending limit that rejects.
+// @Tags v2
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreatePaymentRequest true "Create Payment Request"
+// @Success 201 {object} Response{data=Payment}
+// @Failure 400 {object} Response
+// @Failure 409 {object} Response
+// @Failure 422 {object} Response
+// @Router /v2/payments [post]
+func (h *PaymentHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	var req CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		validationResponse(c, validationErrors)
+		return
+	}
+
+	transactionDate, err := time.Parse(time.RFC3339, req.TransactionDate)
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid transaction date format, expected RFC 3339")
+		return
+	}
+
+	refs, ok := parsePaymentRefs(c, req.CategoryID, req.PaymentMethodID, req.AccountID, req.Tax)
+	if !ok {
+		return
+	}
+
+	payment, err := h.paymentService.Create(id, &services.CreatePaymentRequest{
+		Amount:          req.Amount,
+		Fee:             req.Fee,
+		Direction:       req.Direction,
+		CategoryID:      refs.categoryID,
+		PaymentMethodID: refs.paymentMethodID,
+		AccountID:       refs.accountID,
+		Description:     req.Description,
+		Payee:           req.Payee,
+		Tags:            req.Tags,
+		TransactionDate: transactionDate,
+		Tax:             refs.tax,
+		Force:           req.Force,
+	})
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, http.StatusCreated, paymentDTO(payment))
+}
+
+// GetAll godoc
+// @Summary Get a page of payments
+// @Tags v2
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Items per page, at most 100" default(20)
+// @Param status query string false "Filter by status"
+// @Param direction query string false "Filter by direction (income, expense, transfer)"
+// @Param search query string false "Search in description"
+// @Param min_amount query number false "Minimum amount"
+// @Param max_amount query number false "Maximum amount"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date, inclusive (YYYY-MM-DD)"
+// @Param category_id query string false "Filter by category"
+// @Param payment_method_id query string false "Filter by payment method"
+// @Param reconciled query bool false "Filter by reconciliation"
+// @Success 200 {object} Response{data=[]Payment,meta=Pagination}
+// @Failure 400 {object} Response
+// @Router /v2/payments [get]
+func (h *PaymentHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	page, pageSize, ok := bindPage(c)
+	if !ok {
+		return
+	}
+	filter, ok := bindPaymentFilter(c)
+	if !ok {
+		return
+	}
+	filter.Limit = pageSize
+	filter.Offset = (page - 1) * pageSize
+
+	payments, total, err := h.paymentRepo.FindAll(id, filter)
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	data := make([]Payment, len(payments))
+	for i := range payments {
+		data[i] = paymentDTO(&payments[i])
+	}
+	PageResponse(c, data, page, pageSize, total)
+}
+
+// GetByID godoc
+// @Summary Get payment by ID
+// @Tags v2
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 200 {object} Response{data=Payment}
+// @Failure 404 {object} Response
+// @Router /v2/payments/{id} [get]
+func (h *PaymentHandler) GetByID(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid payment ID")
+		return
+	}
+
+	payment, err := h.paymentService.GetOwned(userID.(uuid.UUID), id)
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, http.StatusOK, paymentDTO(payment))
+}
+
+// Update godoc
+// @Summary Update payment
+// @Description Changes the fields present in the request; omitted fields keep their current value.
+// @Description The status and amount of a payment charged through a provider cannot be changed.
+// @Tags v2
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Param request body UpdatePaymentRequest true "Update Payment Request"
+// @Success 200 {object} Response{data=Payment}
+// @Failure 400 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Failure 422 {object} Response
+// @Router /v2/payments/{id} [patch]
+func (h *PaymentHandler) Update(c *gin.Context) {
+	current, ok := h.ownedPayment(c)
+	if !ok {
+		return
+	}
+
+	var req UpdatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		validationResponse(c, validationErrors)
+		return
+	}
+
+	transactionDate := current.TransactionDate
+	if req.TransactionDate != nil {
+		var err error
+		if transactionDate, err = time.Parse(time.RFC3339, *req.TransactionDate); err != nil {
+			ErrorResponse(c, http.StatusBadRequest, "Invalid transaction date format, expected RFC 3339")
+			return
+		}
+	}
+
+	refs, ok := parsePaymentRefs(c,
+		stringOr(req.CategoryID, current.CategoryID.String()),
+		stringOr(req.PaymentMethodID, current.PaymentMethodID.String()),
+		stringOr(req.AccountID, ""),
+		req.Tax)
+	if !ok {
+		return
+	}
+
+	amount := current.Amount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	payment, err := h.paymentService.Update(current.ID, &services.UpdatePaymentRequest{
+		Amount:          amount,
+		Fee:             req.Fee,
+		Status:          stringOr(req.Status, current.Status),
+		Direction:       stringOr(req.Direction, ""),
+		CategoryID:      refs.categoryID,
+		PaymentMethodID: refs.paymentMethodID,
+		AccountID:       refs.accountID,
+		Description:     stringOr(req.Description, current.Description),
+		Payee:           req.Payee,
+		Tags:            req.Tags,
+		TransactionDate: transactionDate,
+		Tax:             refs.tax,
+	})
+	if err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	SuccessResponse(c, http.StatusOK, paymentDTO(payment))
+}
+
+// Delete godoc
+// @Summary Delete payment
+// @Tags v2
+// @Security BearerAuth
+// @Param id path string true "Payment ID"
+// @Success 204
+// @Failure 404 {object} Response
+// @Router /v2/payments/{id} [delete]
+func (h *PaymentHandler) Delete(c *gin.Context) {
+	payment, ok := h.ownedPayment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.paymentService.Delete(payment.ID); err != nil {
+		serviceErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+// Package v2 serves version 2 of the REST API under /api/v2. Its request and
+// response types are declared here rather than reusing the models or the
+// service requests, so that changes to those do not break clients: handlers
+// convert between the two, as the v1 handlers do for the older contract.
+//
+// Every response has the same envelope: data on success, with meta for
+// paginated lists, and error with a code on failure.
+package v2
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes, matching those of the GraphQL API where they overlap
+const (
+	CodeBadUserInput     = "BAD_USER_INPUT"          // 400, malformed request or invalid value
+	CodeValidationFailed = "VALIDATION_FAILED"       // 400 with the failed fields in details
+	CodeUnauthenticated  = "UNAUTHENTICATED"         // 401
+	CodeForbidden        = "FORBIDDEN"               // 403
+	CodeNotFound         = "NOT_FOUND"               // 404
+	CodeConflict         = "CONFLICT"                // 409
+	CodeDuplicatePayment = "DUPLICATE_PAYMENT"       // 409 with candidate_ids in details
+	CodeSpendingLimit    = "SPENDING_LIMIT_EXCEEDED" // 422 with violations in details
+	CodeRateLimited      = "RATE_LIMITED"            // 429
+	CodeInternal         = "INTERNAL"                // 500
+)
+
+// Response is the envelope of every response
+type Response struct {
+	Data  interface{} `json:"data,omitempty"`
+	Meta  *Pagination `json:"meta,omitempty"`
+	Error *Error      `json:"error,omitempty"`
+}
+
+// Error describes why a request failed
+type Error struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// Pagination describes the page of a list
+type Pagination struct {
+	Page       int   `json:"page"`
+	PageSize   int   `json:"page_size"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// SuccessResponse sends data
+func SuccessResponse(c *gin.Context, statusCode int, data interface{}) {
+	c.JSON(statusCode, Response{Data: data})
+}
+
+// PageResponse sends a page of a list
+func PageResponse(c *gin.Context, data interface{}, page, pageSize int, total int64) {
+	c.JSON(http.StatusOK, Response{
+		Data: data,
+		Meta: &Pagination{
+			Page:       page,
+			PageSize:   pageSize,
+			Total:      total,
+			TotalPages: int((total + int64(pageSize) - 1) / int64(pageSize)),
+		},
+	})
+}
+
+// CodeErrorResponse sends an error with a code and details
+func CodeErrorResponse(c *gin.Context, statusCode int, code, message string, details interface{}) {
+	c.JSON(statusCode, Response{Error: &Error{Code: code, Message: message, Details: details}})
+}
+
+// ErrorResponse sends an error with the code of the status code. It has the
+// signature of utils.ErrorResponse so that shared middleware can respond in
+// the v2 envelope.
+func ErrorResponse(c *gin.Context, statusCode int, message string) {
+	CodeErrorResponse(c, statusCode, codeForStatus(statusCode), message, nil)
+}
+
+func codeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return CodeBadUserInput
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	default:
+		return CodeInternal
+	}
+}
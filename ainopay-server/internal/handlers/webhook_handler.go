@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/services"
+	"ainopay-server/internal/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// WebhookRequest represents the request body for creating or updating a webhook subscription
+type WebhookRequest struct {
+	URL         string   `json:"url" validate:"required,url,max=2048"` // https, or http in debug mode; private addresses are rejected
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description" validate:"max=500"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// webhookErrorStatus maps webhook service errors to HTTP status codes
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrSubscriptionNotFound), errors.Is(err, services.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidEventType), errors.Is(err, services.ErrInvalidWebhookURL),
+		errors.Is(err, services.ErrWebhookAddressDenied):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// bindWebhookRequest binds and validates a subscription request, writing the error response on failure
+func bindWebhookRequest(c *gin.Context) (*services.WebhookSubscriptionRequest, bool) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request format")
+		return nil, false
+	}
+
+	// Validate request
+	if validationErrors := middleware.ValidateStruct(&req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "Validation failed",
+			"details": validationErrors,
+		})
+		return nil, false
+	}
+
+	return &services.WebhookSubscriptionRequest{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+	}, true
+}
+
+// Create godoc
+// @Summary Create webhook subscription
+// @Description The URL must be https and must not point to a private, loopback or link-local address.
+// @Description The signing secret is only returned in this response. Deliveries carry an X-AinoPay-Signature header with HMAC-SHA256 of "<X-AinoPay-Timestamp>.<body>".
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body WebhookRequest true "Webhook Request"
+// @Success 201 {object} utils.Response
+// @Router /webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	req, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(id, req)
+	if err != nil {
+		utils.ErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Webhook created successfully", subscription)
+}
+
+// GetAll godoc
+// @Summary Get webhook subscriptions
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetAll(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	subscriptions, err := h.webhookService.GetSubscriptions(id)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhooks retrieved successfully", subscriptions)
+}
+
+// GetByID godoc
+// @Summary Get webhook subscription by ID
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} utils.Response
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscription(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook retrieved successfully", subscription)
+}
+
+// Update godoc
+// @Summary Update webhook subscription
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param request body WebhookRequest true "Webhook Request"
+// @Success 200 {object} utils.Response
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	req, ok := bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(userID.(uuid.UUID), id, req)
+	if err != nil {
+		utils.ErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook updated successfully", subscription)
+}
+
+// Delete godoc
+// @Summary Delete webhook subscription
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} utils.Response
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(userID.(uuid.UUID), id); err != nil {
+		utils.ErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook deleted successfully", nil)
+}
+
+// listDeliveries writes a page of deliveries filtered by status and subscription
+func (h *WebhookHandler) listDeliveries(c *gin.Context, status string) {
+	userID, _ := c.Get("user_id")
+	id := userID.(uuid.UUID)
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	var subscriptionID *uuid.UUID
+	if val := c.Query("subscription_id"); val != "" {
+		parsed, err := uuid.Parse(val)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid webhook ID")
+			return
+		}
+		subscriptionID = &parsed
+	}
+
+	result, err := h.webhookService.GetDeliveries(id, page, limit, status, subscriptionID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook deliveries retrieved successfully", result)
+}
+
+// GetDeliveries godoc
+// @Summary Get webhook deliveries
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param status query string false "Filter by status (pending, succeeded, failed, dead)"
+// @Param subscription_id query string false "Filter by webhook ID"
+// @Success 200 {object} utils.Response
+// @Router /webhooks/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	h.listDeliveries(c, c.Query("status"))
+}
+
+// GetDeadLetters godoc
+// @Summary Get dead-lettered webhook deliveries
+// @Description Deliveries whose retries were exhausted
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Param subscription_id query string false "Filter by webhook ID"
+// @Success 200 {object} utils.Response
+// @Router /webhooks/deliveries/dead-letters [get]
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	h.listDeliveries(c, models.DeliveryStatusDead)
+}
+
+// GetDelivery godoc
+// @Summary Get webhook delivery with its attempts
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param delivery_id path string true "Delivery ID"
+// @Success 200 {object} utils.Response
+// @Router /webhooks/deliveries/{delivery_id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook delivery retrieved successfully", delivery)
+}
+
+// Redeliver godoc
+// @Summary Redeliver a webhook
+// @Description Queues the delivery again with a fresh set of retries
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param delivery_id path string true "Delivery ID"
+// @Success 200 {object} utils.Response
+// @Router /webhooks/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	id, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(userID.(uuid.UUID), id)
+	if err != nil {
+		utils.ErrorResponse(c, webhookErrorStatus(err), err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Webhook delivery queued for redelivery", delivery)
+}
@@ -9,12 +9,21 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// ErrorWriter writes an error response, letting each API version use its
+// own envelope
+type ErrorWriter func(c *gin.Context, statusCode int, message string)
+
 // AuthMiddleware validates JWT token
 func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	return AuthMiddlewareWith(cfg, utils.ErrorResponse)
+}
+
+// AuthMiddlewareWith is AuthMiddleware responding with writeError
+func AuthMiddlewareWith(cfg *config.Config, writeError ErrorWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header required")
+			writeError(c, http.StatusUnauthorized, "Authorization header required")
 			c.Abort()
 			return
 		}
@@ -22,7 +31,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		// Extract token from "Bearer <token>"
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format")
+			writeError(c, http.StatusUnauthorized, "Invalid authorization format")
 			c.Abort()
 			return
 		}
@@ -30,7 +39,7 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 		token := parts[1]
 		claims, err := utils.ValidateToken(token, cfg.JWT.Secret)
 		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token")
+			writeError(c, http.StatusUnauthorized, "Invalid or expired token")
 			c.Abort()
 			return
 		}
@@ -44,6 +53,19 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
+// QueryTokenMiddleware lets clients that cannot set headers, such as the
+// browser EventSource, pass the access token in the access_token query
+// parameter. It must run before AuthMiddleware, and only on routes that need
+// it since URLs end up in logs and browser history.
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("access_token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
+
 // AdminMiddleware checks if user is admin
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
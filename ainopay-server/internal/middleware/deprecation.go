@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"ainopay-server/internal/config"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Dates of the v1 deprecation schedule used when the configured ones are invalid
+var (
+	defaultV1DeprecationDate = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	defaultV1SunsetDate      = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// DeprecationMiddleware marks responses of a v1 route as deprecated with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and links to the v2
+// route that supersedes it. Only routes that have a v2 successor use it.
+func DeprecationMiddleware(cfg *config.Config, successor string) gin.HandlerFunc {
+	deprecation, err := time.Parse("2006-01-02", cfg.API.V1DeprecationDate)
+	if err != nil {
+		deprecation = defaultV1DeprecationDate
+	}
+	sunset, err := time.Parse("2006-01-02", cfg.API.V1SunsetDate)
+	if err != nil {
+		sunset = defaultV1SunsetDate
+	}
+
+	deprecationHeader := fmt.Sprintf("@%d", deprecation.Unix())
+	sunsetHeader := sunset.Format(http.TimeFormat)
+	linkHeader := fmt.Sprintf(`<%s>; rel="successor-version"`, successor)
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecationHeader)
+		c.Header("Sunset", sunsetHeader)
+		c.Header("Link", linkHeader)
+		c.Next()
+	}
+}
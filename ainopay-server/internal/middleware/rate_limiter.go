@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"ainopay-server/internal/utils"
 	"net/http"
 	"sync"
 	"time"
@@ -74,15 +75,17 @@ func (i *IPRateLimiter) CleanupOldIPs() {
 
 // RateLimitMiddleware creates a middleware that limits requests per IP
 func RateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
+	return RateLimitMiddlewareWith(limiter, utils.ErrorResponse)
+}
+
+// RateLimitMiddlewareWith is RateLimitMiddleware responding with writeError
+func RateLimitMiddlewareWith(limiter *IPRateLimiter, writeError ErrorWriter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
 		l := limiter.GetLimiter(ip)
 
 		if !l.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"success": false,
-				"error":   "Too many requests. Please try again later.",
-			})
+			writeError(c, http.StatusTooManyRequests, "Too many requests. Please try again later.")
 			c.Abort()
 			return
 		}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// TimezoneHeader overrides the user's timezone preference for one request
+const TimezoneHeader = "X-Timezone"
+
+// TimezoneMiddleware sets the location that dates of the request are
+// interpreted and grouped in: the tz query parameter or X-Timezone header
+// when given, else the authenticated user's timezone preference, else UTC
+func TimezoneMiddleware(userLocation func(userID uuid.UUID) (*time.Location, error)) gin.HandlerFunc {
+	return TimezoneMiddlewareWith(userLocation, utils.ErrorResponse)
+}
+
+// TimezoneMiddlewareWith is TimezoneMiddleware responding with writeError
+func TimezoneMiddlewareWith(userLocation func(userID uuid.UUID) (*time.Location, error), writeError ErrorWriter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Query("tz")
+		if name == "" {
+			name = c.GetHeader(TimezoneHeader)
+		}
+
+		loc := time.UTC
+		if name != "" {
+			override, err := models.LoadTimezone(name)
+			if err != nil {
+				writeError(c, http.StatusBadRequest, "Invalid timezone, expected an IANA name such as Asia/Jakarta")
+				c.Abort()
+				return
+			}
+			loc = override
+		} else if userID, exists := c.Get("user_id"); exists {
+			// Fall back to UTC when the preference cannot be loaded
+			if preferred, err := userLocation(userID.(uuid.UUID)); err == nil {
+				loc = preferred
+			}
+		}
+
+		c.Set("location", loc)
+		c.Next()
+	}
+}
+
+// Location returns the location set by TimezoneMiddleware, or UTC
+func Location(c *gin.Context) *time.Location {
+	if loc, exists := c.Get("location"); exists {
+		return loc.(*time.Location)
+	}
+	return time.UTC
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Financial account types
+const (
+	FinancialAccountBank       = "bank"
+	FinancialAccountEWallet    = "e_wallet"
+	FinancialAccountCash       = "cash"
+	FinancialAccountCreditCard = "credit_card"
+)
+
+// DefaultCurrency is the currency of accounts created without one, and of
+// payments that are not linked to an account
+const DefaultCurrency = "IDR"
+
+// FinancialAccount is a bank account, e-wallet or other place the user keeps
+// money. Its balance is the opening balance plus incoming transfers and the
+// income paid into it, minus outgoing transfers, expenses and fees.
+type FinancialAccount struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	UserID          uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name            string         `gorm:"type:varchar(255);not null" json:"name"`
+	Type            string         `gorm:"type:varchar(20);not null" json:"type"`    // bank, e_wallet, cash, credit_card
+	PaymentMethodID *uuid.UUID     `gorm:"type:uuid;index" json:"payment_method_id"` // payments with this method default to the account
+	PaymentMethod   *PaymentMethod `gorm:"foreignKey:PaymentMethodID" json:"payment_method,omitempty"`
+	Currency        string         `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"` // ISO 4217 code the account's payments are booked in
+	OpeningBalance  float64        `gorm:"type:decimal(15,2);not null;default:0" json:"opening_balance"`
+	IsActive        bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+func (a *FinancialAccount) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// Transfer moves money between two of the user's accounts
+type Transfer struct {
+	ID            uuid.UUID        `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	FromAccountID uuid.UUID        `gorm:"type:uuid;not null;index" json:"from_account_id"`
+	FromAccount   FinancialAccount `gorm:"foreignKey:FromAccountID" json:"from_account,omitempty"`
+	ToAccountID   uuid.UUID        `gorm:"type:uuid;not null;index" json:"to_account_id"`
+	ToAccount     FinancialAccount `gorm:"foreignKey:ToAccountID" json:"to_account,omitempty"`
+	Amount        float64          `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Description   string           `gorm:"type:text" json:"description"`
+	TransferDate  time.Time        `gorm:"not null;index" json:"transfer_date"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+func (t *Transfer) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// BalancePoint is an account's balance at the end of a period together with
+// the money that moved during it
+type BalancePoint struct {
+	Period  string  `json:"period"`
+	Inflow  float64 `json:"inflow"`
+	Outflow float64 `json:"outflow"`
+	Balance float64 `json:"balance"`
+}
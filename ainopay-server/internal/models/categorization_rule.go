@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CategorizationRule sets the category, payee and tags of the user's new
+// payments that match all of its conditions. Rules are tried by ascending
+// priority: the first matching rule with a category or payee sets it, and
+// the tags of all matching rules are added.
+type CategorizationRule struct {
+	ID                  uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID              uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name                string     `gorm:"type:varchar(255);not null" json:"name"`
+	Priority            int        `gorm:"default:0" json:"priority"`
+	DescriptionContains string     `gorm:"type:varchar(255)" json:"description_contains"` // case-insensitive
+	DescriptionRegex    string     `gorm:"type:varchar(500)" json:"description_regex"`    // case-insensitive
+	MinAmount           *float64   `gorm:"type:decimal(15,2)" json:"min_amount"`
+	MaxAmount           *float64   `gorm:"type:decimal(15,2)" json:"max_amount"`
+	PaymentMethodID     *uuid.UUID `gorm:"type:uuid" json:"payment_method_id"`
+	CategoryID          *uuid.UUID `gorm:"type:uuid" json:"category_id"`
+	Category            *Category  `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Payee               string     `gorm:"type:varchar(255)" json:"payee"`
+	Tags                []string   `gorm:"type:jsonb;serializer:json" json:"tags"`
+	IsActive            bool       `gorm:"default:true" json:"is_active"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func (r *CategorizationRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invoice statuses. The status is never stored; it is derived from the
+// linked payments and the due date every time an invoice is loaded.
+const (
+	InvoiceStatusOpen          = "open"
+	InvoiceStatusPartiallyPaid = "partially_paid"
+	InvoiceStatusPaid          = "paid"
+	InvoiceStatusOverdue       = "overdue"
+)
+
+// Invoice represents a supplier invoice that is settled by one or more payments
+type Invoice struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_invoices_user_number" json:"user_id"`
+	Number     string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_invoices_user_number" json:"number"`
+	Payee      string    `gorm:"not null" json:"payee"`
+	DueDate    time.Time `gorm:"type:date;not null" json:"due_date"`
+	Amount     float64   `gorm:"type:decimal(15,2);not null" json:"amount"`
+	PaidAmount float64   `gorm:"->;-:migration" json:"paid_amount"`
+	Status     string    `gorm:"-" json:"status"` // open, partially_paid, paid, overdue
+	Payments   []Payment `gorm:"foreignKey:InvoiceID" json:"payments,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (i *Invoice) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// AfterFind derives the invoice status once the paid amount has been loaded
+func (i *Invoice) AfterFind(tx *gorm.DB) error {
+	i.Status = i.DeriveStatus(time.Now())
+	return nil
+}
+
+// DeriveStatus computes the status of the invoice at the given moment
+func (i *Invoice) DeriveStatus(now time.Time) string {
+	switch {
+	case i.PaidAmount >= i.Amount:
+		return InvoiceStatusPaid
+	case i.DueDate.Before(StartOfDay(now)):
+		return InvoiceStatusOverdue
+	case i.PaidAmount > 0:
+		return InvoiceStatusPartiallyPaid
+	default:
+		return InvoiceStatusOpen
+	}
+}
+
+// StartOfDay truncates t to midnight in its own location
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Ledger account types
+const (
+	AccountTypeAsset     = "asset"
+	AccountTypeLiability = "liability"
+	AccountTypeEquity    = "equity"
+	AccountTypeIncome    = "income"
+	AccountTypeExpense   = "expense"
+)
+
+// Codes of the accounts every user's ledger has
+const (
+	AccountCodeFees       = "FEES"
+	AccountCodeReceivable = "AR"
+	AccountCodeEquity     = "EQUITY"
+	AccountCodeClearing   = "CLEARING"
+)
+
+// Journal entry kinds
+const (
+	JournalKindPayment    = "payment"    // a payment was completed
+	JournalKindRefund     = "refund"     // a completed payment was refunded
+	JournalKindReversal   = "reversal"   // a posted payment was deleted or is no longer completed
+	JournalKindAdjustment = "adjustment" // a posted payment or opening balance was edited
+	JournalKindTransfer   = "transfer"   // money moved between two of the user's accounts
+	JournalKindOpening    = "opening_balance"
+	JournalKindManual     = "manual"
+)
+
+// LedgerAccount is an account in a user's double-entry ledger. Cash accounts
+// are linked to a financial account or, for payments without one, to a
+// payment method. Expense accounts are linked to a category. Every account
+// holds amounts in one currency, and the entries posted to it only balance
+// against accounts in the same currency.
+type LedgerAccount struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID          uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_ledger_account_code" json:"user_id"`
+	Code            string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_ledger_account_code" json:"code"`
+	Name            string     `gorm:"type:varchar(255);not null" json:"name"`
+	Type            string     `gorm:"type:varchar(20);not null" json:"type"` // asset, liability, equity, income, expense
+	PaymentMethodID *uuid.UUID `gorm:"type:uuid;index" json:"payment_method_id,omitempty"`
+	CategoryID      *uuid.UUID `gorm:"type:uuid;index" json:"category_id,omitempty"`
+	AccountID       *uuid.UUID `gorm:"type:uuid;index" json:"account_id,omitempty"`            // financial account
+	Currency        string     `gorm:"type:varchar(3);not null;default:'IDR'" json:"currency"` // ISO 4217 code of the amounts posted to it
+	IsSystem        bool       `gorm:"default:false" json:"is_system"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (a *LedgerAccount) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// DebitNormal reports whether the account's balance increases with debits
+func (a *LedgerAccount) DebitNormal() bool {
+	return a.Type == AccountTypeAsset || a.Type == AccountTypeExpense
+}
+
+// JournalEntry is a balanced set of postings. Entries are never edited;
+// corrections are posted as new entries.
+type JournalEntry struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	PaymentID   *uuid.UUID `gorm:"type:uuid;index" json:"payment_id,omitempty"`
+	TransferID  *uuid.UUID `gorm:"type:uuid;index" json:"transfer_id,omitempty"`
+	AccountID   *uuid.UUID `gorm:"type:uuid;index" json:"account_id,omitempty"` // financial account whose opening balance was posted
+	Kind        string     `gorm:"type:varchar(20);not null" json:"kind"`       // payment, refund, reversal, adjustment, transfer, opening_balance, manual
+	Description string     `gorm:"type:text" json:"description"`
+	EntryDate   time.Time  `gorm:"not null;index" json:"entry_date"`
+	Postings    []Posting  `gorm:"foreignKey:JournalEntryID;constraint:OnDelete:CASCADE" json:"postings,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (e *JournalEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// Posting debits or credits one account as part of a journal entry. Exactly
+// one of Debit and Credit is positive.
+type Posting struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	JournalEntryID uuid.UUID      `gorm:"type:uuid;not null;index" json:"journal_entry_id"`
+	AccountID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"account_id"`
+	Account        *LedgerAccount `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Debit          float64        `gorm:"type:decimal(15,2);not null;default:0" json:"debit"`
+	Credit         float64        `gorm:"type:decimal(15,2);not null;default:0" json:"credit"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+func (p *Posting) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification types
+const (
+	NotificationBudgetExceeded = "budget_exceeded" // a payment went over a spending limit that flags
+	NotificationPaymentFailed  = "payment_failed"
+	NotificationApprovalNeeded = "approval_needed" // sent to admins when a payment is held for review
+)
+
+// NotificationTypes lists the notification types users set preferences for
+var NotificationTypes = []string{
+	NotificationBudgetExceeded,
+	NotificationPaymentFailed,
+	NotificationApprovalNeeded,
+}
+
+// Notification is a message shown in the user's notification center
+type Notification struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index;uniqueIndex:idx_notification_event" json:"user_id"`
+	Type      string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_event" json:"type"`
+	EventID   *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_notification_event" json:"-"` // outbox event that caused it, so that relaying it again adds nothing
+	Title     string     `gorm:"type:varchar(255);not null" json:"title"`
+	Message   string     `gorm:"type:text" json:"message"`
+	PaymentID *uuid.UUID `gorm:"type:uuid" json:"payment_id"`
+	ReadAt    *time.Time `gorm:"index" json:"read_at"`
+	CreatedAt time.Time  `gorm:"index" json:"created_at"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// NotificationPreference sets the channels of one notification type for a
+// user. Types without a preference use DefaultNotificationPreference.
+type NotificationPreference struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	Type      string    `gorm:"type:varchar(50);primaryKey" json:"type"`
+	InApp     bool      `gorm:"not null" json:"in_app"`
+	Email     bool      `gorm:"not null" json:"email"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreference returns the channels of a notification type
+// the user has not set: in-app always, email for failed payments
+func DefaultNotificationPreference(notificationType string) NotificationPreference {
+	return NotificationPreference{
+		Type:  notificationType,
+		InApp: true,
+		Email: notificationType == NotificationPaymentFailed,
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Aggregates that record outbox events. Events of one aggregate instance are
+// relayed in the order they were written.
+const (
+	AggregatePayment = "payment"
+	AggregateUser    = "user"
+)
+
+// User lifecycle events
+const (
+	EventUserRegistered         = "user.registered"
+	EventPasswordResetRequested = "user.password_reset_requested"
+	EventPasswordChanged        = "user.password_changed"
+)
+
+// Outbox event statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusProcessed = "processed"
+	OutboxStatusDead      = "dead" // retries exhausted
+)
+
+// OutboxEvent is a domain event written in the same transaction as the change
+// that caused it and relayed to in-process handlers afterwards
+type OutboxEvent struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	Sequence      int64      `gorm:"autoIncrement;not null;uniqueIndex" json:"sequence"`
+	AggregateType string     `gorm:"type:varchar(50);not null;index:idx_outbox_aggregate" json:"aggregate_type"`
+	AggregateID   uuid.UUID  `gorm:"type:uuid;not null;index:idx_outbox_aggregate" json:"aggregate_id"`
+	UserID        uuid.UUID  `gorm:"type:uuid;index" json:"user_id"`
+	EventType     string     `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Status        string     `gorm:"type:varchar(20);default:'pending';index" json:"status"` // pending, processed, dead
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	ProcessedAt   *time.Time `json:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func (e *OutboxEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
@@ -7,20 +7,49 @@ import (
 	"gorm.io/gorm"
 )
 
+// Payment directions
+const (
+	PaymentDirectionIncome   = "income"
+	PaymentDirectionExpense  = "expense"
+	PaymentDirectionTransfer = "transfer" // money moved to somewhere the user owns that is not tracked as an account
+)
+
 type Payment struct {
-	ID              uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	UserID          uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
-	User            User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Amount          float64        `gorm:"type:decimal(15,2);not null" json:"amount"`
-	Status          string         `gorm:"type:varchar(20);default:'pending'" json:"status"` // pending, completed, failed, refunded
-	PaymentMethodID uuid.UUID      `gorm:"type:uuid;not null" json:"payment_method_id"`
-	PaymentMethod   PaymentMethod  `gorm:"foreignKey:PaymentMethodID" json:"payment_method,omitempty"`
-	CategoryID      uuid.UUID      `gorm:"type:uuid;not null" json:"category_id"`
-	Category        Category       `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	Description     string         `gorm:"type:text" json:"description"`
-	TransactionDate time.Time      `gorm:"not null" json:"transaction_date"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
+	ID                uuid.UUID         `gorm:"type:uuid;primary_key" json:"id"`
+	UserID            uuid.UUID         `gorm:"type:uuid;not null" json:"user_id"`
+	User              User              `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Amount            float64           `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Fee               float64           `gorm:"type:decimal(15,2);default:0" json:"fee"`             // bank or provider fee, paid on top of expenses and deducted from income
+	Status            string            `gorm:"type:varchar(20);default:'pending'" json:"status"`    // pending, review, completed, failed, refunded
+	Direction         string            `gorm:"type:varchar(20);default:'expense'" json:"direction"` // income, expense, transfer
+	PaymentMethodID   uuid.UUID         `gorm:"type:uuid;not null" json:"payment_method_id"`
+	PaymentMethod     PaymentMethod     `gorm:"foreignKey:PaymentMethodID" json:"payment_method,omitempty"`
+	CategoryID        uuid.UUID         `gorm:"type:uuid;not null" json:"category_id"`
+	Category          Category          `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Description       string            `gorm:"type:text" json:"description"`
+	Payee             string            `gorm:"type:varchar(255)" json:"payee"`
+	Tags              []string          `gorm:"type:jsonb;serializer:json" json:"tags"`
+	TransactionDate   time.Time         `gorm:"not null" json:"transaction_date"`
+	AccountID         *uuid.UUID        `gorm:"type:uuid;index" json:"account_id"` // financial account the money left or entered
+	Account           *FinancialAccount `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	InvoiceID         *uuid.UUID        `gorm:"type:uuid;index" json:"invoice_id"`
+	TaxCodeID         *uuid.UUID        `gorm:"type:uuid;index" json:"tax_code_id"`
+	TaxCode           *TaxCode          `gorm:"foreignKey:TaxCodeID" json:"tax_code,omitempty"`
+	TaxRate           float64           `gorm:"type:decimal(5,2);default:0" json:"tax_rate"` // percent
+	TaxAmount         float64           `gorm:"type:decimal(15,2);default:0" json:"tax_amount"`
+	TaxInclusive      bool              `gorm:"not null;default:false" json:"tax_inclusive"`          // the amount is gross and includes the tax; otherwise it is net and the tax is paid separately
+	TaxInvoiceNumber  string            `gorm:"type:varchar(50)" json:"tax_invoice_number,omitempty"` // e.g. the Faktur Pajak number
+	Reconciled        bool              `gorm:"default:false" json:"reconciled"`
+	OverLimit         bool              `gorm:"default:false" json:"over_limit"` // exceeded a spending limit that flags instead of rejecting
+	RiskScore         int               `gorm:"default:0" json:"risk_score"`
+	RiskMatches       []RiskMatch       `gorm:"type:jsonb;serializer:json" json:"risk_matches,omitempty"`
+	HeldStatus        string            `gorm:"type:varchar(20)" json:"held_status,omitempty"` // status to apply when a payment in review is approved
+	Provider          string            `gorm:"type:varchar(50)" json:"provider,omitempty"`
+	ProviderReference string            `gorm:"type:varchar(255);index" json:"provider_reference,omitempty"`
+	ProviderPayload   string            `gorm:"type:text" json:"provider_payload,omitempty"` // raw response of the last charge, query or refund
+	CallbackPayload   string            `gorm:"type:text" json:"callback_payload,omitempty"` // raw body of the last provider callback
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
 }
 
 func (p *Payment) BeforeCreate(tx *gorm.DB) error {
@@ -30,8 +59,70 @@ func (p *Payment) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// CountsTowardLimits reports whether a payment is spending that limits apply
+// to: an expense that is pending, in review or completed
+func (p *Payment) CountsTowardLimits() bool {
+	return p.Direction == PaymentDirectionExpense && (p.Status == "pending" || p.Status == "review" || p.Status == "completed")
+}
+
+// NetAmount is the amount without tax, the base the tax is computed on
+func (p *Payment) NetAmount() float64 {
+	if p.TaxInclusive {
+		return p.Amount - p.TaxAmount
+	}
+	return p.Amount
+}
+
+// GrossAmount is the amount including tax
+func (p *Payment) GrossAmount() float64 {
+	if p.TaxInclusive {
+		return p.Amount
+	}
+	return p.Amount + p.TaxAmount
+}
+
+// Currency is the currency of the account the payment is linked to, or
+// DefaultCurrency when it has none. Account must be loaded.
+func (p *Payment) Currency() string {
+	if p.AccountID != nil && p.Account != nil && p.Account.Currency != "" {
+		return p.Account.Currency
+	}
+	return DefaultCurrency
+}
+
+// CashFlowPoint is the money that came in and went out during one month
+type CashFlowPoint struct {
+	Month   string  `json:"month"` // YYYY-MM
+	Inflow  float64 `json:"inflow"`
+	Outflow float64 `json:"outflow"`
+	Net     float64 `json:"net"`
+}
+
+// PaymentStatistics summarizes the user's payments. Amounts only include
+// completed payments.
+type PaymentStatistics struct {
+	TotalPayments  int64   `json:"total_payments"`
+	CompletedCount int64   `json:"completed_count"`
+	PendingCount   int64   `json:"pending_count"`
+	TotalAmount    float64 `json:"total_amount"` // kept for older clients, same as total_expense
+	TotalIncome    float64 `json:"total_income"`
+	TotalExpense   float64 `json:"total_expense"`
+	TotalFees      float64 `json:"total_fees"`
+	Net            float64 `json:"net"` // income minus expenses and fees
+}
+
 type MonthlyStats struct {
 	Month       string  `json:"month"`
 	TotalAmount float64 `json:"total_amount"`
 	Count       int64   `json:"count"`
 }
+
+// BreakdownRow aggregates the payments of one category, payment method or status
+type BreakdownRow struct {
+	Key     string  `json:"key"` // category or payment method ID, or status
+	Label   string  `json:"label"`
+	Count   int64   `json:"count"`
+	Total   float64 `json:"total"`
+	Average float64 `json:"average"`
+	Share   float64 `json:"share"` // fraction of the total amount of all rows
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentDailySummary totals the user's payments of one day, category,
+// payment method, status and direction. Days are dates in the user's
+// timezone. Summaries are adjusted whenever a payment is created, changed or
+// deleted, so that dashboards need not scan the payments.
+type PaymentDailySummary struct {
+	UserID          uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Day             time.Time `gorm:"type:date;primaryKey" json:"day"`
+	CategoryID      uuid.UUID `gorm:"type:uuid;primaryKey" json:"category_id"`
+	PaymentMethodID uuid.UUID `gorm:"type:uuid;primaryKey" json:"payment_method_id"`
+	Status          string    `gorm:"type:varchar(20);primaryKey" json:"status"`
+	Direction       string    `gorm:"type:varchar(20);primaryKey" json:"direction"`
+	Count           int64     `gorm:"not null;default:0" json:"count"`
+	Amount          float64   `gorm:"type:decimal(15,2);not null;default:0" json:"amount"`
+	Fee             float64   `gorm:"type:decimal(15,2);not null;default:0" json:"fee"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reconciliation session statuses
+const (
+	ReconciliationStatusOpen      = "open"
+	ReconciliationStatusCompleted = "completed"
+)
+
+// Statement line statuses
+const (
+	StatementLineUnmatched = "unmatched"
+	StatementLineProposed  = "proposed"
+	StatementLineConfirmed = "confirmed"
+	StatementLineRejected  = "rejected"
+	StatementLineCreated   = "created"
+)
+
+// ReconciliationSession groups the lines of one uploaded bank statement
+type ReconciliationSession struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key" json:"id"`
+	UserID         uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name           string          `gorm:"not null" json:"name"`
+	Status         string          `gorm:"type:varchar(20);default:'open'" json:"status"` // open, completed
+	DateWindowDays int             `gorm:"not null;default:3" json:"date_window_days"`
+	Lines          []StatementLine `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE" json:"lines,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func (s *ReconciliationSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// StatementLine is a single bank statement line and its match against a payment
+type StatementLine struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	SessionID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"session_id"`
+	LineNumber      int        `gorm:"not null" json:"line_number"`
+	TransactionDate time.Time  `gorm:"not null" json:"transaction_date"`
+	Amount          float64    `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency        string     `gorm:"type:varchar(3)" json:"currency"` // ISO 4217 code, empty when the statement does not state it
+	Description     string     `gorm:"type:text" json:"description"`
+	Reference       string     `gorm:"type:varchar(255)" json:"reference"`
+	Status          string     `gorm:"type:varchar(20);default:'unmatched'" json:"status"` // unmatched, proposed, confirmed, rejected, created
+	PaymentID       *uuid.UUID `gorm:"type:uuid;index" json:"payment_id"`
+	Payment         *Payment   `gorm:"foreignKey:PaymentID" json:"payment,omitempty"`
+	MatchScore      float64    `gorm:"type:decimal(5,4);default:0" json:"match_score"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (l *StatementLine) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Report subscription frequencies
+const (
+	ReportFrequencyWeekly  = "weekly"  // Monday through Sunday
+	ReportFrequencyMonthly = "monthly" // calendar month
+)
+
+// ReportSubscription emails the user a summary of every week or month once
+// it has ended. Periods are in the user's timezone; a user has at most one
+// subscription per frequency.
+type ReportSubscription struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_report_subscription_user_frequency" json:"user_id"`
+	Frequency  string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_report_subscription_user_frequency" json:"frequency"` // weekly, monthly
+	IsActive   bool       `gorm:"default:true" json:"is_active"`
+	PeriodEnd  time.Time  `gorm:"not null" json:"period_end"`        // end (exclusive) of the next period to send
+	NextRunAt  time.Time  `gorm:"not null;index" json:"next_run_at"` // when the next summary is sent or retried
+	LastSentAt *time.Time `json:"last_sent_at"`
+	LastError  string     `gorm:"type:text" json:"last_error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (s *ReportSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// PeriodStart returns the start of the subscription's period that ends at end
+func (s *ReportSubscription) PeriodStart(end time.Time, loc *time.Location) time.Time {
+	end = end.In(loc)
+	if s.Frequency == ReportFrequencyMonthly {
+		return end.AddDate(0, -1, 0)
+	}
+	return end.AddDate(0, 0, -7)
+}
+
+// NextPeriodEnd returns the end of the subscription's period containing t:
+// the following Monday or first of the month, at midnight in loc
+func (s *ReportSubscription) NextPeriodEnd(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	if s.Frequency == ReportFrequencyMonthly {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, 1, 0)
+	}
+	days := (8 - int(t.Weekday())) % 7
+	if days == 0 {
+		days = 7
+	}
+	return StartOfDay(t).AddDate(0, 0, days)
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Risk rule types
+const (
+	RiskRuleAmountAbove   = "amount_above"   // amount is above Params.Amount
+	RiskRuleAmountOutlier = "amount_outlier" // amount is Params.Multiplier times the user's average for the category
+	RiskRuleVelocity      = "velocity"       // Params.Count or more payments created within Params.WindowMinutes
+	RiskRuleTimeOfDay     = "time_of_day"    // transaction time is between Params.StartHour and Params.EndHour
+)
+
+// RiskRuleParams holds the settings of a risk rule; which ones are used
+// depends on the rule type
+type RiskRuleParams struct {
+	Amount        float64 `json:"amount,omitempty"`
+	Multiplier    float64 `json:"multiplier,omitempty"`
+	MinHistory    int     `json:"min_history,omitempty"`   // payments needed before outliers are scored
+	LookbackDays  int     `json:"lookback_days,omitempty"` // history used for the average, default 90
+	Count         int     `json:"count,omitempty"`
+	WindowMinutes int     `json:"window_minutes,omitempty"`
+	StartHour     *int    `json:"start_hour,omitempty"` // 0-23, inclusive, in the offset the transaction date was given in
+	EndHour       *int    `json:"end_hour,omitempty"`   // 0-23, exclusive; may wrap past midnight
+}
+
+// RiskRule adds Score to the risk score of every new payment it matches.
+// Rules are defined by admins and apply to all users.
+type RiskRule struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
+	Type      string         `gorm:"type:varchar(30);not null" json:"type"` // amount_above, amount_outlier, velocity, time_of_day
+	Params    RiskRuleParams `gorm:"type:jsonb;serializer:json" json:"params"`
+	Score     int            `gorm:"not null" json:"score"`
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (r *RiskRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// RiskMatch records a risk rule that matched a payment when it was created
+type RiskMatch struct {
+	RuleID uuid.UUID `json:"rule_id"`
+	Name   string    `json:"name"`
+	Type   string    `json:"type"`
+	Score  int       `json:"score"`
+	Reason string    `json:"reason"`
+}
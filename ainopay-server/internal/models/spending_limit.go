@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Spending limit periods
+const (
+	SpendingLimitDaily   = "daily"
+	SpendingLimitMonthly = "monthly"
+)
+
+// What happens to a payment that would exceed a spending limit
+const (
+	SpendingLimitReject = "reject"
+	SpendingLimitFlag   = "flag"
+)
+
+// SpendingLimit caps the expenses of a user per calendar day or month in the
+// user's timezone.
+// A limit without a payment method or category applies to all of them.
+// Limits set by an admin cannot be changed or removed by the user.
+type SpendingLimit struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	UserID          uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	PaymentMethodID *uuid.UUID     `gorm:"type:uuid" json:"payment_method_id"`
+	PaymentMethod   *PaymentMethod `gorm:"foreignKey:PaymentMethodID" json:"payment_method,omitempty"`
+	CategoryID      *uuid.UUID     `gorm:"type:uuid" json:"category_id"`
+	Category        *Category      `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Period          string         `gorm:"type:varchar(20);not null" json:"period"`                  // daily, monthly
+	Amount          float64        `gorm:"type:decimal(15,2);not null" json:"amount"`                // includes fees
+	Action          string         `gorm:"type:varchar(20);not null;default:'reject'" json:"action"` // reject, flag
+	SetByAdmin      bool           `gorm:"default:false" json:"set_by_admin"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+func (l *SpendingLimit) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// Applies reports whether the limit covers payments with the given method and category
+func (l *SpendingLimit) Applies(paymentMethodID, categoryID uuid.UUID) bool {
+	return (l.PaymentMethodID == nil || *l.PaymentMethodID == paymentMethodID) &&
+		(l.CategoryID == nil || *l.CategoryID == categoryID)
+}
+
+// PeriodBounds returns the calendar day or month in loc of the limit's period
+// containing t
+func (l *SpendingLimit) PeriodBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	t = t.In(loc)
+	if l.Period == SpendingLimitMonthly {
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := StartOfDay(t)
+	return start, start.AddDate(0, 0, 1)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaxCode is a tax that applies to payments, such as Indonesian VAT (PPN).
+// Tax codes are configured by admins and shared by all users; codes that
+// payments use can be deactivated but not deleted.
+type TaxCode struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Code        string    `gorm:"type:varchar(20);uniqueIndex;not null" json:"code"`
+	Name        string    `gorm:"type:varchar(255);not null" json:"name"`
+	Rate        float64   `gorm:"type:decimal(5,2);not null" json:"rate"` // percent
+	Description string    `gorm:"type:text" json:"description"`
+	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (t *TaxCode) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// TaxSummaryRow totals the tax of the payments of one period, tax code and direction
+type TaxSummaryRow struct {
+	PeriodStart time.Time  `json:"-"`
+	Period      string     `json:"period"` // YYYY-MM, YYYY-Qn or YYYY
+	TaxCodeID   *uuid.UUID `json:"tax_code_id"`
+	TaxCode     string     `json:"tax_code"`
+	TaxName     string     `json:"tax_name"`
+	Direction   string     `json:"direction"` // income (output tax) or expense (input tax)
+	Count       int64      `json:"count"`
+	Net         float64    `json:"net"` // tax base
+	Tax         float64    `json:"tax"`
+	Gross       float64    `json:"gross"`
+}
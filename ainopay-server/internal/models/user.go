@@ -1,7 +1,9 @@
 package models
 
 import (
+	"fmt"
 	"time"
+	_ "time/tzdata" // timezone preferences must load without system zoneinfo
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,11 +14,20 @@ type User struct {
 	Email        string    `gorm:"uniqueIndex;not null" json:"email"`
 	PasswordHash string    `gorm:"not null" json:"-"`
 	FullName     string    `gorm:"not null" json:"full_name"`
-	Role         string    `gorm:"type:varchar(20);default:'user'" json:"role"` // admin, user
+	Role         string    `gorm:"type:varchar(20);default:'user'" json:"role"`             // admin, user
+	Timezone     string    `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"` // IANA name, used to bucket dates
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// LoadTimezone returns the location of an IANA timezone name
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
 // BeforeCreate hook to generate UUID
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
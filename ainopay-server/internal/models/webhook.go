@@ -0,0 +1,111 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Payment lifecycle event types delivered to webhooks
+const (
+	EventPaymentCreated       = "payment.created"
+	EventPaymentUpdated       = "payment.updated"
+	EventPaymentStatusChanged = "payment.status_changed"
+	EventPaymentDeleted       = "payment.deleted"
+)
+
+// WebhookEventTypes lists the event types a subscription can filter on
+var WebhookEventTypes = []string{
+	EventPaymentCreated,
+	EventPaymentUpdated,
+	EventPaymentStatusChanged,
+	EventPaymentDeleted,
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed" // last attempt failed, retry scheduled
+	DeliveryStatusDead      = "dead"   // retries exhausted
+)
+
+// WebhookSubscription is an endpoint a user registered to receive events
+type WebhookSubscription struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	URL         string    `gorm:"type:varchar(2048);not null" json:"url"`
+	Secret      string    `gorm:"type:varchar(255);not null" json:"-"`
+	EventTypes  string    `gorm:"type:text" json:"event_types"` // comma separated, empty means all
+	Description string    `gorm:"type:text" json:"description"`
+	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (s *WebhookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Accepts reports whether the subscription wants events of the given type
+func (s *WebhookSubscription) Accepts(eventType string) bool {
+	if strings.TrimSpace(s.EventTypes) == "" {
+		return true
+	}
+	for _, t := range strings.Split(s.EventTypes, ",") {
+		if strings.TrimSpace(t) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event queued for one subscription. Deliveries are
+// persisted so pending retries survive restarts.
+type WebhookDelivery struct {
+	ID             uuid.UUID                `gorm:"type:uuid;primary_key" json:"id"`
+	SubscriptionID uuid.UUID                `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	UserID         uuid.UUID                `gorm:"type:uuid;not null;index" json:"user_id"`
+	EventID        uuid.UUID                `gorm:"type:uuid;not null;index" json:"event_id"`
+	EventType      string                   `gorm:"type:varchar(50);not null" json:"event_type"`
+	Payload        string                   `gorm:"type:text;not null" json:"payload"`
+	Status         string                   `gorm:"type:varchar(20);default:'pending';index" json:"status"` // pending, succeeded, failed, dead
+	AttemptCount   int                      `gorm:"default:0" json:"attempt_count"`
+	NextAttemptAt  *time.Time               `gorm:"index" json:"next_attempt_at"`
+	LastError      string                   `gorm:"type:text" json:"last_error,omitempty"`
+	ResponseStatus int                      `json:"response_status,omitempty"`
+	DeliveredAt    *time.Time               `json:"delivered_at"`
+	Attempts       []WebhookDeliveryAttempt `gorm:"foreignKey:DeliveryID;constraint:OnDelete:CASCADE" json:"attempts,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookDeliveryAttempt records a single HTTP attempt of a delivery
+type WebhookDeliveryAttempt struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	DeliveryID     uuid.UUID `gorm:"type:uuid;not null;index" json:"delivery_id"`
+	AttemptNumber  int       `gorm:"not null" json:"attempt_number"`
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `gorm:"type:text" json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (a *WebhookDeliveryAttempt) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
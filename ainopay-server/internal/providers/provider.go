@@ -0,0 +1,127 @@
+// Package providers defines the interface payment gateways implement to
+// charge, settle and refund payments, plus the built-in simulated gateway.
+package providers
+
+import (
+	"ainopay-server/internal/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Charge statuses reported by providers. They use the same values as
+// models.Payment.Status so they can be applied directly.
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+	StatusRefunded  = "refunded"
+)
+
+var (
+	ErrUnknownProvider  = errors.New("unknown payment provider")
+	ErrChargeNotFound   = errors.New("charge not found at provider")
+	ErrInvalidSignature = errors.New("invalid callback signature")
+	ErrNotRefundable    = errors.New("charge cannot be refunded")
+)
+
+// ChargeRequest asks a provider to collect a payment
+type ChargeRequest struct {
+	PaymentID   uuid.UUID
+	Amount      float64
+	Currency    string
+	Description string
+	CallbackURL string // where the provider should post status notifications
+}
+
+// ChargeResult is the provider's view of a charge. Raw holds the provider
+// response exactly as received so it can be stored for audit.
+type ChargeResult struct {
+	Reference string
+	Status    string
+	Raw       json.RawMessage
+}
+
+// Notification is a verified inbound status callback
+type Notification struct {
+	Reference string
+	Status    string
+	Raw       json.RawMessage
+}
+
+// PaymentProvider is implemented by every payment gateway integration
+type PaymentProvider interface {
+	// Name is the identifier used in routes and stored on payments
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*ChargeResult, error)
+	QueryStatus(ctx context.Context, reference string) (*ChargeResult, error)
+	Refund(ctx context.Context, reference string, amount float64) (*ChargeResult, error)
+	// ParseCallback verifies the signature of an inbound notification and decodes it
+	ParseCallback(body []byte, header http.Header) (*Notification, error)
+}
+
+// Registry holds the providers available to the application
+type Registry struct {
+	providers map[string]PaymentProvider
+	fallback  string
+}
+
+// NewRegistry creates a registry; the first provider is the default one
+func NewRegistry(providers ...PaymentProvider) *Registry {
+	r := &Registry{providers: make(map[string]PaymentProvider)}
+	for _, p := range providers {
+		r.Register(p)
+	}
+	return r
+}
+
+// Register adds a provider, making it the default if it is the first one
+func (r *Registry) Register(p PaymentProvider) {
+	if len(r.providers) == 0 {
+		r.fallback = p.Name()
+	}
+	r.providers[p.Name()] = p
+}
+
+// Get returns the provider with the given name, or the default one for ""
+func (r *Registry) Get(name string) (PaymentProvider, error) {
+	if name == "" {
+		name = r.fallback
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, name)
+	}
+	return p, nil
+}
+
+// Names lists the registered providers in alphabetical order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewDefaultRegistry creates a registry with the built-in providers configured
+// from cfg. The simulated provider is left out when it has no secret, which
+// is the case in release mode unless one is configured.
+func NewDefaultRegistry(cfg *config.Config) *Registry {
+	registry := NewRegistry()
+	if cfg.Provider.SimulatedSecret != "" {
+		settleAfter, err := time.ParseDuration(cfg.Provider.SimulatedSettleAfter)
+		if err != nil || settleAfter < 0 {
+			settleAfter = 5 * time.Second
+		}
+		registry.Register(NewSimulatedProvider(cfg.Provider.SimulatedSecret, settleAfter))
+	}
+	return registry
+}
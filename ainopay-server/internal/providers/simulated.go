@@ -0,0 +1,202 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SimulatedSignatureHeader carries the hex HMAC-SHA256 of the callback body
+const SimulatedSignatureHeader = "X-Simulated-Signature"
+
+// SimulatedProvider is an in-memory gateway for local development. Charges
+// settle after a configurable delay: amounts ending in .99 fail, everything
+// else completes. When a callback URL is given the provider posts a signed
+// notification once the charge settles, just like a real gateway would.
+type SimulatedProvider struct {
+	secret      string
+	settleAfter time.Duration
+	client      *http.Client
+
+	mu      sync.Mutex
+	charges map[string]*simulatedCharge
+}
+
+type simulatedCharge struct {
+	Reference   string    `json:"reference"`
+	PaymentID   uuid.UUID `json:"payment_id"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Description string    `json:"description"`
+	Status      string    `json:"status"`
+	Refunded    float64   `json:"refunded_amount,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	SettledAt   time.Time `json:"settled_at,omitempty"`
+}
+
+// simulatedCallback is the body the simulated provider posts to callbacks
+type simulatedCallback struct {
+	Event  string           `json:"event"`
+	Charge *simulatedCharge `json:"charge"`
+}
+
+func NewSimulatedProvider(secret string, settleAfter time.Duration) *SimulatedProvider {
+	return &SimulatedProvider{
+		secret:      secret,
+		settleAfter: settleAfter,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		charges:     make(map[string]*simulatedCharge),
+	}
+}
+
+func (p *SimulatedProvider) Name() string {
+	return "simulated"
+}
+
+// outcome decides how a simulated charge settles
+func (p *SimulatedProvider) outcome(amount float64) string {
+	cents := int(math.Round(amount*100)) % 100
+	if cents == 99 {
+		return StatusFailed
+	}
+	return StatusCompleted
+}
+
+// settle moves a pending charge to its outcome once the settle delay passed.
+// Callers must hold p.mu.
+func (p *SimulatedProvider) settle(charge *simulatedCharge) {
+	if charge.Status == StatusPending && time.Since(charge.CreatedAt) >= p.settleAfter {
+		charge.Status = p.outcome(charge.Amount)
+		charge.SettledAt = time.Now().UTC()
+	}
+}
+
+func (p *SimulatedProvider) result(charge *simulatedCharge) (*ChargeResult, error) {
+	raw, err := json.Marshal(charge)
+	if err != nil {
+		return nil, err
+	}
+	return &ChargeResult{Reference: charge.Reference, Status: charge.Status, Raw: raw}, nil
+}
+
+func (p *SimulatedProvider) CreateCharge(ctx context.Context, req ChargeRequest) (*ChargeResult, error) {
+	charge := &simulatedCharge{
+		Reference:   "sim_" + uuid.New().String(),
+		PaymentID:   req.PaymentID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Description: req.Description,
+		Status:      StatusPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	p.mu.Lock()
+	p.charges[charge.Reference] = charge
+	p.settle(charge)
+	result, err := p.result(charge)
+	p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CallbackURL != "" && charge.Status == StatusPending {
+		go p.notifyWhenSettled(charge.Reference, req.CallbackURL)
+	}
+
+	return result, nil
+}
+
+func (p *SimulatedProvider) QueryStatus(ctx context.Context, reference string) (*ChargeResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[reference]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	p.settle(charge)
+	return p.result(charge)
+}
+
+func (p *SimulatedProvider) Refund(ctx context.Context, reference string, amount float64) (*ChargeResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[reference]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	p.settle(charge)
+	if charge.Status != StatusCompleted || amount <= 0 || amount > charge.Amount {
+		return nil, ErrNotRefundable
+	}
+
+	charge.Refunded = amount
+	charge.Status = StatusRefunded
+	return p.result(charge)
+}
+
+func (p *SimulatedProvider) ParseCallback(body []byte, header http.Header) (*Notification, error) {
+	signature, err := hex.DecodeString(header.Get(SimulatedSignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.sign(body)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var callback simulatedCallback
+	if err := json.Unmarshal(body, &callback); err != nil || callback.Charge == nil {
+		return nil, fmt.Errorf("invalid callback payload")
+	}
+
+	return &Notification{
+		Reference: callback.Charge.Reference,
+		Status:    callback.Charge.Status,
+		Raw:       body,
+	}, nil
+}
+
+func (p *SimulatedProvider) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// notifyWhenSettled posts a signed callback once the charge has settled
+func (p *SimulatedProvider) notifyWhenSettled(reference, callbackURL string) {
+	time.Sleep(p.settleAfter)
+
+	p.mu.Lock()
+	charge := p.charges[reference]
+	p.settle(charge)
+	body, err := json.Marshal(simulatedCallback{Event: "charge." + charge.Status, Charge: charge})
+	p.mu.Unlock()
+	if err != nil {
+		log.Printf("Simulated provider: failed to encode callback: %v", err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Simulated provider: invalid callback URL: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SimulatedSignatureHeader, hex.EncodeToString(p.sign(body)))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Printf("Simulated provider: callback for %s failed: %v", reference, err)
+		return
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,28 @@
+// Package realtime fans events out to the open sessions of a user, such as
+// server-sent event streams, plus the built-in in-process broker.
+package realtime
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Event is a message pushed to a user's sessions. Data is already encoded so
+// that brokers can forward events between processes as they are.
+type Event struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Broker delivers events published for a user to that user's subscribers.
+// Delivery is best effort: subscribers only receive events published while
+// they are subscribed, and a subscriber that falls too far behind is
+// dropped by closing its channel so that it reconnects and reloads.
+type Broker interface {
+	Publish(userID uuid.UUID, event Event) error
+	// Subscribe returns the subscriber's event channel and a function that
+	// unsubscribes it. The function may be called more than once.
+	Subscribe(userID uuid.UUID) (<-chan Event, func())
+}
@@ -0,0 +1,71 @@
+package realtime
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is the number of events a subscriber may have pending
+// before it is dropped
+const subscriberBuffer = 32
+
+type subscriber struct {
+	events chan Event
+}
+
+// MemoryBroker is a Broker for a single process. With several server
+// instances each only reaches the sessions connected to it, so it must be
+// replaced by a broker backed by a shared message bus.
+type MemoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*subscriber]struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: make(map[uuid.UUID]map[*subscriber]struct{})}
+}
+
+func (b *MemoryBroker) Publish(userID uuid.UUID, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[userID] {
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(userID, sub)
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(userID uuid.UUID) (<-chan Event, func()) {
+	sub := &subscriber{events: make(chan Event, subscriberBuffer)}
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[*subscriber]struct{})
+	}
+	b.subscribers[userID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub.events, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, sub)
+	}
+}
+
+// remove closes the channel of a subscriber still registered. b.mu must be held.
+func (b *MemoryBroker) remove(userID uuid.UUID, sub *subscriber) {
+	subs := b.subscribers[userID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.events)
+	if len(subs) == 0 {
+		delete(b.subscribers, userID)
+	}
+}
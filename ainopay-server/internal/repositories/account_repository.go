@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// paymentInflowSQL and paymentOutflowSQL are the money a payment brought into
+// and took out of its account. Fees are always paid out, and the fee of a
+// refunded payment is not returned.
+const (
+	paymentInflowSQL  = "CASE WHEN status = 'completed' AND direction = 'income' THEN amount ELSE 0 END"
+	paymentOutflowSQL = "CASE WHEN status = 'completed' AND direction <> 'income' THEN amount + fee WHEN status IN ('completed', 'refunded') THEN fee ELSE 0 END"
+)
+
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// Transaction runs fn in a database transaction
+func (r *AccountRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a repository that runs its queries in tx
+func (r *AccountRepository) WithTx(tx *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: tx}
+}
+
+func (r *AccountRepository) Create(account *models.FinancialAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *AccountRepository) FindByID(id uuid.UUID) (*models.FinancialAccount, error) {
+	var account models.FinancialAccount
+	err := r.db.Preload("PaymentMethod").First(&account, "id = ?", id).Error
+	return &account, err
+}
+
+// FindByIDs returns the accounts with the given IDs, in no particular order
+func (r *AccountRepository) FindByIDs(ids []uuid.UUID) ([]models.FinancialAccount, error) {
+	var accounts []models.FinancialAccount
+	err := r.db.Where("id IN ?", ids).Find(&accounts).Error
+	return accounts, err
+}
+
+func (r *AccountRepository) FindByUserID(userID uuid.UUID) ([]models.FinancialAccount, error) {
+	var accounts []models.FinancialAccount
+	err := r.db.Preload("PaymentMethod").Where("user_id = ?", userID).Order("name ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// FindDefaultForPaymentMethod returns the oldest active account of the user
+// linked to a payment method
+func (r *AccountRepository) FindDefaultForPaymentMethod(userID, paymentMethodID uuid.UUID) (*models.FinancialAccount, error) {
+	var account models.FinancialAccount
+	err := r.db.Where("user_id = ? AND payment_method_id = ? AND is_active = ?", userID, paymentMethodID, true).
+		Order("created_at ASC").
+		First(&account).Error
+	return &account, err
+}
+
+func (r *AccountRepository) Update(account *models.FinancialAccount) error {
+	return r.db.Omit("PaymentMethod").Save(account).Error
+}
+
+func (r *AccountRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.FinancialAccount{}, "id = ?", id).Error
+}
+
+// CountUsage counts the payments and transfers that reference an account
+func (r *AccountRepository) CountUsage(id uuid.UUID) (int64, error) {
+	var payments, transfers int64
+	if err := r.db.Model(&models.Payment{}).Where("account_id = ?", id).Count(&payments).Error; err != nil {
+		return 0, err
+	}
+	if err := r.db.Model(&models.Transfer{}).Where("from_account_id = ? OR to_account_id = ?", id, id).Count(&transfers).Error; err != nil {
+		return 0, err
+	}
+	return payments + transfers, nil
+}
+
+func (r *AccountRepository) CreateTransfer(transfer *models.Transfer) error {
+	return r.db.Omit("FromAccount", "ToAccount").Create(transfer).Error
+}
+
+func (r *AccountRepository) FindTransferByID(id uuid.UUID) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.db.Preload("FromAccount").Preload("ToAccount").First(&transfer, "id = ?", id).Error
+	return &transfer, err
+}
+
+// TransferFilter narrows down the transfers returned by FindTransfers
+type TransferFilter struct {
+	AccountID *uuid.UUID // transfers from or to this account
+	StartDate *time.Time
+	EndDate   *time.Time
+	Limit     int
+	Offset    int
+}
+
+func (r *AccountRepository) FindTransfers(userID uuid.UUID, filter TransferFilter) ([]models.Transfer, int64, error) {
+	var transfers []models.Transfer
+	var total int64
+
+	query := r.db.Model(&models.Transfer{}).Where("user_id = ?", userID)
+	if filter.AccountID != nil {
+		query = query.Where("from_account_id = ? OR to_account_id = ?", *filter.AccountID, *filter.AccountID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("transfer_date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("transfer_date <= ?", *filter.EndDate)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Preload("FromAccount").Preload("ToAccount").Order("transfer_date DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	}
+
+	err := query.Find(&transfers).Error
+	return transfers, total, err
+}
+
+func (r *AccountRepository) DeleteTransfer(id uuid.UUID) error {
+	return r.db.Delete(&models.Transfer{}, "id = ?", id).Error
+}
+
+// AccountMovement is the money that moved in and out of an account during a
+// period. Period is zero when movements are not grouped.
+type AccountMovement struct {
+	Period  time.Time
+	Inflow  float64
+	Outflow float64
+}
+
+// Movements sums the payments and transfers of an account between start and
+// end (both optional, end exclusive). With a unit of day, week or month the
+// sums are grouped by period in the given location, and each Period holds the
+// wall-clock start of its period; with an empty unit a single total is returned.
+func (r *AccountRepository) Movements(accountID uuid.UUID, start, end *time.Time, unit string, loc *time.Location) ([]AccountMovement, error) {
+	switch unit {
+	case "", "day", "week", "month":
+	default:
+		return nil, fmt.Errorf("invalid movement period %q", unit)
+	}
+
+	sources := []struct {
+		table, dateColumn, accountColumn, inflow, outflow string
+	}{
+		{"payments", "transaction_date", "account_id", paymentInflowSQL, paymentOutflowSQL},
+		{"transfers", "transfer_date", "to_account_id", "amount", "0"},
+		{"transfers", "transfer_date", "from_account_id", "0", "amount"},
+	}
+
+	totals := make(map[time.Time]*AccountMovement)
+	var periods []time.Time
+	for _, source := range sources {
+		period, args := "NULL::timestamp", []interface{}{}
+		if unit != "" {
+			period, args = "DATE_TRUNC('"+unit+"', "+source.dateColumn+" AT TIME ZONE ?)", []interface{}{loc.String()}
+		}
+
+		query := r.db.Table(source.table).
+			Select(period+" AS period, COALESCE(SUM("+source.inflow+"), 0) AS inflow, COALESCE(SUM("+source.outflow+"), 0) AS outflow", args...).
+			Where(source.accountColumn+" = ?", accountID)
+		if start != nil {
+			query = query.Where(source.dateColumn+" >= ?", *start)
+		}
+		if end != nil {
+			query = query.Where(source.dateColumn+" < ?", *end)
+		}
+		if unit != "" {
+			query = query.Group("period")
+		}
+
+		var rows []struct {
+			Period  *time.Time
+			Inflow  float64
+			Outflow float64
+		}
+		if err := query.Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			var key time.Time
+			if row.Period != nil {
+				key = *row.Period
+			}
+			total, ok := totals[key]
+			if !ok {
+				total = &AccountMovement{Period: key}
+				totals[key] = total
+				periods = append(periods, key)
+			}
+			total.Inflow += row.Inflow
+			total.Outflow += row.Outflow
+		}
+	}
+
+	movements := make([]AccountMovement, 0, len(periods))
+	for _, period := range periods {
+		movements = append(movements, *totals[period])
+	}
+	return movements, nil
+}
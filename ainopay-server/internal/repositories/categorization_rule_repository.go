@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CategorizationRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewCategorizationRuleRepository(db *gorm.DB) *CategorizationRuleRepository {
+	return &CategorizationRuleRepository{db: db}
+}
+
+func (r *CategorizationRuleRepository) Create(rule *models.CategorizationRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *CategorizationRuleRepository) FindByID(id uuid.UUID) (*models.CategorizationRule, error) {
+	var rule models.CategorizationRule
+	err := r.db.Preload("Category").First(&rule, "id = ?", id).Error
+	return &rule, err
+}
+
+// FindByUserID returns the user's rules in the order they are applied
+func (r *CategorizationRuleRepository) FindByUserID(userID uuid.UUID) ([]models.CategorizationRule, error) {
+	var rules []models.CategorizationRule
+	err := r.db.Preload("Category").
+		Where("user_id = ?", userID).
+		Order("priority ASC, created_at ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// FindActiveByUserID returns the user's active rules in the order they are applied
+func (r *CategorizationRuleRepository) FindActiveByUserID(userID uuid.UUID) ([]models.CategorizationRule, error) {
+	var rules []models.CategorizationRule
+	err := r.db.Where("user_id = ? AND is_active = ?", userID, true).
+		Order("priority ASC, created_at ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *CategorizationRuleRepository) Update(rule *models.CategorizationRule) error {
+	return r.db.Omit("Category").Save(rule).Error
+}
+
+func (r *CategorizationRuleRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.CategorizationRule{}, "id = ?", id).Error
+}
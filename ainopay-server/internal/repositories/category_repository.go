@@ -31,6 +31,13 @@ func (r *CategoryRepository) FindByID(id uuid.UUID) (*models.Category, error) {
 	return &category, err
 }
 
+// FindByIDs returns the categories with the given IDs, in no particular order
+func (r *CategoryRepository) FindByIDs(ids []uuid.UUID) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
 func (r *CategoryRepository) Update(category *models.Category) error {
 	return r.db.Save(category).Error
 }
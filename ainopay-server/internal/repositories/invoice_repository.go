@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// invoicePaidAmountSQL sums the completed payments applied against an invoice
+const invoicePaidAmountSQL = "COALESCE((SELECT SUM(p.amount) FROM payments p WHERE p.invoice_id = invoices.id AND p.status = 'completed'), 0)"
+
+type InvoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// withPaidAmount selects invoices together with their computed paid amount
+func (r *InvoiceRepository) withPaidAmount() *gorm.DB {
+	return r.db.Model(&models.Invoice{}).Select("invoices.*, " + invoicePaidAmountSQL + " AS paid_amount")
+}
+
+func (r *InvoiceRepository) Create(invoice *models.Invoice) error {
+	return r.db.Create(invoice).Error
+}
+
+func (r *InvoiceRepository) FindByID(id uuid.UUID) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.withPaidAmount().
+		Preload("Payments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("transaction_date ASC")
+		}).
+		Preload("Payments.PaymentMethod").Preload("Payments.Category").
+		First(&invoice, "invoices.id = ?", id).Error
+	return &invoice, err
+}
+
+func (r *InvoiceRepository) FindByNumber(userID uuid.UUID, number string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.withPaidAmount().
+		Where("invoices.user_id = ? AND invoices.number = ?", userID, number).
+		First(&invoice).Error
+	return &invoice, err
+}
+
+// FindAll returns the invoices of a user, optionally filtered by derived status
+func (r *InvoiceRepository) FindAll(userID uuid.UUID, status string, now time.Time) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+
+	query := r.withPaidAmount().Where("invoices.user_id = ?", userID)
+
+	switch status {
+	case models.InvoiceStatusPaid:
+		query = query.Where(invoicePaidAmountSQL + " >= invoices.amount")
+	case models.InvoiceStatusOverdue:
+		query = query.Where(invoicePaidAmountSQL+" < invoices.amount AND invoices.due_date < ?", models.StartOfDay(now))
+	case models.InvoiceStatusPartiallyPaid:
+		query = query.Where(invoicePaidAmountSQL+" > 0 AND "+invoicePaidAmountSQL+" < invoices.amount AND invoices.due_date >= ?", models.StartOfDay(now))
+	case models.InvoiceStatusOpen:
+		query = query.Where(invoicePaidAmountSQL+" = 0 AND invoices.due_date >= ?", models.StartOfDay(now))
+	}
+
+	err := query.Order("invoices.due_date ASC").Find(&invoices).Error
+	return invoices, err
+}
+
+// FindOverdue returns unpaid invoices whose due date has passed
+func (r *InvoiceRepository) FindOverdue(userID uuid.UUID, now time.Time) ([]models.Invoice, error) {
+	return r.FindAll(userID, models.InvoiceStatusOverdue, now)
+}
+
+func (r *InvoiceRepository) Update(invoice *models.Invoice) error {
+	return r.db.Omit("Payments").Save(invoice).Error
+}
+
+// Delete removes an invoice and detaches any payments applied against it
+func (r *InvoiceRepository) Delete(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Payment{}).Where("invoice_id = ?", id).
+			Update("invoice_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Invoice{}, "id = ?", id).Error
+	})
+}
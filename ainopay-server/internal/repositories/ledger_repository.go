@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// Transaction runs fn in a database transaction
+func (r *LedgerRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a repository that runs its queries in tx
+func (r *LedgerRepository) WithTx(tx *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{db: tx}
+}
+
+// CreateAccount creates an account, doing nothing if the user already has
+// one with the same code. It reports whether the account was created.
+func (r *LedgerRepository) CreateAccount(account *models.LedgerAccount) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(account)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *LedgerRepository) FindAccountByID(id uuid.UUID) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	err := r.db.First(&account, "id = ?", id).Error
+	return &account, err
+}
+
+func (r *LedgerRepository) FindAccountByCode(userID uuid.UUID, code string) (*models.LedgerAccount, error) {
+	var account models.LedgerAccount
+	err := r.db.First(&account, "user_id = ? AND code = ?", userID, code).Error
+	return &account, err
+}
+
+func (r *LedgerRepository) FindAccounts(userID uuid.UUID) ([]models.LedgerAccount, error) {
+	var accounts []models.LedgerAccount
+	err := r.db.Where("user_id = ?", userID).Order("code ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// CreateEntry creates a journal entry together with its postings
+func (r *LedgerRepository) CreateEntry(entry *models.JournalEntry) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *LedgerRepository) FindEntryByID(id uuid.UUID) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := r.db.Preload("Postings.Account").First(&entry, "id = ?", id).Error
+	return &entry, err
+}
+
+// EntryFilter narrows down the journal entries returned by FindEntries
+type EntryFilter struct {
+	PaymentID *uuid.UUID
+	AccountID *uuid.UUID
+	StartDate *time.Time
+	EndDate   *time.Time
+	Limit     int
+	Offset    int
+}
+
+func (r *LedgerRepository) FindEntries(userID uuid.UUID, filter EntryFilter) ([]models.JournalEntry, int64, error) {
+	var entries []models.JournalEntry
+	var total int64
+
+	query := r.db.Model(&models.JournalEntry{}).Where("user_id = ?", userID)
+	if filter.PaymentID != nil {
+		query = query.Where("payment_id = ?", *filter.PaymentID)
+	}
+	if filter.AccountID != nil {
+		query = query.Where("id IN (?)", r.db.Model(&models.Posting{}).Select("journal_entry_id").Where("account_id = ?", *filter.AccountID))
+	}
+	if filter.StartDate != nil {
+		query = query.Where("entry_date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("entry_date <= ?", *filter.EndDate)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Preload("Postings.Account").Order("entry_date DESC, created_at DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	}
+
+	err := query.Find(&entries).Error
+	return entries, total, err
+}
+
+// Journal entry columns linking entries to the record they were posted for
+const (
+	EntrySourcePayment  = "payment_id"
+	EntrySourceTransfer = "transfer_id"
+	EntrySourceAccount  = "account_id"
+)
+
+// AccountNet is the net amount (debits minus credits) posted to an account
+type AccountNet struct {
+	AccountID uuid.UUID
+	Net       float64
+}
+
+// SourceBalances returns the net amount posted to each account by the
+// entries linked to one record through the source column
+func (r *LedgerRepository) SourceBalances(source string, sourceID uuid.UUID) ([]AccountNet, error) {
+	var balances []AccountNet
+	err := r.db.Table("postings").
+		Select("postings.account_id, SUM(postings.debit) - SUM(postings.credit) AS net").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("journal_entries."+source+" = ?", sourceID).
+		Group("postings.account_id").
+		Scan(&balances).Error
+	return balances, err
+}
+
+// PaymentAccountBalance is the net amount (debits minus credits) posted to an
+// account on behalf of a payment
+type PaymentAccountBalance struct {
+	PaymentID uuid.UUID
+	AccountID uuid.UUID
+	Net       float64
+}
+
+// PaymentBalances returns the net amount posted to each account for every
+// payment of the user
+func (r *LedgerRepository) PaymentBalances(userID uuid.UUID) ([]PaymentAccountBalance, error) {
+	var balances []PaymentAccountBalance
+	err := r.db.Table("postings").
+		Select("journal_entries.payment_id, postings.account_id, SUM(postings.debit) - SUM(postings.credit) AS net").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("journal_entries.user_id = ? AND journal_entries.payment_id IS NOT NULL", userID).
+		Group("journal_entries.payment_id, postings.account_id").
+		Scan(&balances).Error
+	return balances, err
+}
+
+// AccountTotals holds the debits and credits posted to an account
+type AccountTotals struct {
+	AccountID uuid.UUID
+	Debits    float64
+	Credits   float64
+}
+
+// AccountTotals sums the postings of every account of the user, counting only
+// entries dated on or before asOf when it is set
+func (r *LedgerRepository) AccountTotals(userID uuid.UUID, asOf *time.Time) ([]AccountTotals, error) {
+	var totals []AccountTotals
+	query := r.db.Table("postings").
+		Select("postings.account_id, SUM(postings.debit) AS debits, SUM(postings.credit) AS credits").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("journal_entries.user_id = ?", userID)
+	if asOf != nil {
+		query = query.Where("journal_entries.entry_date <= ?", *asOf)
+	}
+	err := query.Group("postings.account_id").Scan(&totals).Error
+	return totals, err
+}
+
+// EntryTotals holds the debits and credits of one journal entry
+type EntryTotals struct {
+	JournalEntryID uuid.UUID `json:"journal_entry_id"`
+	Debits         float64   `json:"debits"`
+	Credits        float64   `json:"credits"`
+}
+
+// FindUnbalancedEntries returns the user's journal entries whose debits and
+// credits differ
+func (r *LedgerRepository) FindUnbalancedEntries(userID uuid.UUID) ([]EntryTotals, error) {
+	var totals []EntryTotals
+	err := r.db.Table("postings").
+		Select("postings.journal_entry_id, SUM(postings.debit) AS debits, SUM(postings.credit) AS credits").
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("journal_entries.user_id = ?", userID).
+		Group("postings.journal_entry_id").
+		Having("SUM(postings.debit) <> SUM(postings.credit)").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// CountInvalidPostings counts postings of the user that are not exactly one
+// positive debit or credit
+func (r *LedgerRepository) CountInvalidPostings(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Posting{}).
+		Joins("JOIN journal_entries ON journal_entries.id = postings.journal_entry_id").
+		Where("journal_entries.user_id = ?", userID).
+		Where("postings.debit < 0 OR postings.credit < 0 OR (postings.debit > 0) = (postings.credit > 0)").
+		Count(&count).Error
+	return count, err
+}
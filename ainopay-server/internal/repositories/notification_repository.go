@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// Create stores a notification unless one was already stored for the same
+// user, type and event. It reports whether the notification was stored.
+func (r *NotificationRepository) Create(notification *models.Notification) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *NotificationRepository) FindByID(id uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.First(&notification, "id = ?", id).Error
+	return &notification, err
+}
+
+// FindByUserID returns a page of the user's notifications, newest first, and
+// the total number of them
+func (r *NotificationRepository) FindByUserID(userID uuid.UUID, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *NotificationRepository) CountUnread(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks a notification as read unless it already is
+func (r *NotificationRepository) MarkRead(id uuid.UUID, at time.Time) error {
+	return r.db.Model(&models.Notification{}).Where("id = ? AND read_at IS NULL", id).Update("read_at", at).Error
+}
+
+// MarkAllRead marks every unread notification of the user as read and
+// returns how many there were
+func (r *NotificationRepository) MarkAllRead(userID uuid.UUID, at time.Time) (int64, error) {
+	result := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
+
+func (r *NotificationRepository) FindPreferences(userID uuid.UUID) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	err := r.db.Where("user_id = ?", userID).Find(&preferences).Error
+	return preferences, err
+}
+
+// SavePreferences creates or replaces notification preferences
+func (r *NotificationRepository) SavePreferences(preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&preferences).Error
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Transaction runs fn in a database transaction
+func (r *OutboxRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a repository that runs its queries in tx
+func (r *OutboxRepository) WithTx(tx *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: tx}
+}
+
+func (r *OutboxRepository) Create(event *models.OutboxEvent) error {
+	return r.db.Create(event).Error
+}
+
+// FindReady returns due pending events that are the oldest pending event of
+// their aggregate, so that later events wait until earlier ones are relayed.
+// Dead events no longer hold back their aggregate.
+func (r *OutboxRepository) FindReady(now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox_events earlier
+			WHERE earlier.aggregate_type = outbox_events.aggregate_type
+			AND earlier.aggregate_id = outbox_events.aggregate_id
+			AND earlier.status = ?
+			AND earlier.sequence < outbox_events.sequence
+		)`, models.OutboxStatusPending).
+		Order("sequence ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// Claim pushes the next attempt of an event out by lease so that no other
+// relay picks it up while it is being handled. It reports false when the
+// event was already claimed elsewhere.
+func (r *OutboxRepository) Claim(event *models.OutboxEvent, lease time.Duration) (bool, error) {
+	result := r.db.Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", event.ID, models.OutboxStatusPending, event.NextAttemptAt).
+		Update("next_attempt_at", time.Now().Add(lease))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// MarkProcessed records that every handler accepted the event
+func (r *OutboxRepository) MarkProcessed(id uuid.UUID) error {
+	now := time.Now()
+	return r.db.Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.OutboxStatusProcessed,
+		"processed_at": &now,
+		"last_error":   "",
+	}).Error
+}
+
+// MarkFailed stores a failed attempt and when the event is retried, or marks
+// it dead when status is dead
+func (r *OutboxRepository) MarkFailed(event *models.OutboxEvent) error {
+	return r.db.Model(&models.OutboxEvent{}).Where("id = ?", event.ID).Updates(map[string]interface{}{
+		"status":          event.Status,
+		"attempts":        event.Attempts,
+		"last_error":      event.LastError,
+		"next_attempt_at": event.NextAttemptAt,
+	}).Error
+}
+
+// DeleteProcessedBefore removes relayed events older than cutoff
+func (r *OutboxRepository) DeleteProcessedBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("status = ? AND processed_at < ?", models.OutboxStatusProcessed, cutoff).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
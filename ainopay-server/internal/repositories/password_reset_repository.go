@@ -16,6 +16,11 @@ func NewPasswordResetRepository(db *gorm.DB) *PasswordResetRepository {
 	return &PasswordResetRepository{db: db}
 }
 
+// WithTx returns a repository that runs its queries in tx
+func (r *PasswordResetRepository) WithTx(tx *gorm.DB) *PasswordResetRepository {
+	return &PasswordResetRepository{db: tx}
+}
+
 // Create creates a new password reset token
 func (r *PasswordResetRepository) Create(token *models.PasswordResetToken) error {
 	return r.db.Create(token).Error
@@ -3,6 +3,7 @@ package repositories
 import (
 	"ainopay-server/internal/models"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -19,3 +20,11 @@ func (r *PaymentMethodRepository) FindAll() ([]models.PaymentMethod, error) {
 	err := r.db.Where("is_active = ?", true).Order("name ASC").Find(&methods).Error
 	return methods, err
 }
+
+// FindByIDs returns the payment methods with the given IDs, active or not, in
+// no particular order
+func (r *PaymentMethodRepository) FindByIDs(ids []uuid.UUID) ([]models.PaymentMethod, error) {
+	var methods []models.PaymentMethod
+	err := r.db.Where("id IN ?", ids).Find(&methods).Error
+	return methods, err
+}
@@ -2,11 +2,12 @@ package repositories
 
 import (
 	"ainopay-server/internal/models"
-	//"fmt"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository struct {
@@ -17,33 +18,108 @@ func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
 	return &PaymentRepository{db: db}
 }
 
+// WithTx returns a repository that runs its queries in tx
+func (r *PaymentRepository) WithTx(tx *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: tx}
+}
+
+// Create stores a payment and adds it to its daily summary
 func (r *PaymentRepository) Create(payment *models.Payment) error {
-	return r.db.Create(payment).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(payment).Error; err != nil {
+			return err
+		}
+		return adjustDailySummary(tx, payment.ID, 1)
+	})
 }
 
 func (r *PaymentRepository) FindByID(id uuid.UUID) (*models.Payment, error) {
 	var payment models.Payment
-	err := r.db.Preload("User").Preload("PaymentMethod").Preload("Category").
+	err := r.db.Preload("User").Preload("PaymentMethod").Preload("Category").Preload("Account").Preload("TaxCode").
 		First(&payment, "id = ?", id).Error
 	return &payment, err
 }
 
+// LockByID returns a payment without its relations, locking it until the
+// transaction ends
+func (r *PaymentRepository) LockByID(id uuid.UUID) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, "id = ?", id).Error
+	return &payment, err
+}
+
 // PaymentFilter options
 type PaymentFilter struct {
-	Limit     int
-	Offset    int
-	Status    string
-	Search    string
-	MinAmount *float64
-	MaxAmount *float64
-	StartDate *time.Time
-	EndDate   *time.Time
+	Limit           int
+	Offset          int
+	Status          string
+	Direction       string
+	Search          string
+	MinAmount       *float64
+	MaxAmount       *float64
+	StartDate       *time.Time
+	EndDate         *time.Time
+	Reconciled      *bool
+	PaymentMethodID *uuid.UUID
+	CategoryID      *uuid.UUID
 }
 
 func (r *PaymentRepository) FindAll(userID uuid.UUID, filter PaymentFilter) ([]models.Payment, int64, error) {
+	return r.findAll(userID, filter, true)
+}
+
+// FindAllWithoutRelations is FindAll for callers that load the category,
+// payment method, account and tax code of the payments themselves
+func (r *PaymentRepository) FindAllWithoutRelations(userID uuid.UUID, filter PaymentFilter) ([]models.Payment, int64, error) {
+	return r.findAll(userID, filter, false)
+}
+
+func (r *PaymentRepository) findAll(userID uuid.UUID, filter PaymentFilter, preload bool) ([]models.Payment, int64, error) {
 	var payments []models.Payment
 	var total int64
 
+	query := r.filtered(userID, filter)
+
+	// Get total count
+	query.Count(&total)
+
+	// Get paginated results, with preloaded relations unless the caller loads them
+	if preload {
+		query = query.Preload("User").Preload("PaymentMethod").Preload("Category").Preload("Account").Preload("TaxCode")
+	}
+	query = query.Order("transaction_date DESC, id DESC")
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	}
+
+	err := query.Find(&payments).Error
+
+	return payments, total, err
+}
+
+// FindBatch returns the next batch of up to limit payments matching the
+// filter, in the order of FindAll, that come after the given payment, or the
+// first batch when after is nil. It reads large result sets in batches that
+// stay consistent while payments are added or removed; Limit and Offset of
+// the filter are ignored.
+func (r *PaymentRepository) FindBatch(userID uuid.UUID, filter PaymentFilter, after *models.Payment, limit int) ([]models.Payment, error) {
+	var payments []models.Payment
+
+	query := r.filtered(userID, filter)
+	if after != nil {
+		query = query.Where("(transaction_date, id) < (?, ?)", after.TransactionDate, after.ID)
+	}
+
+	err := query.Preload("User").Preload("PaymentMethod").Preload("Category").Preload("Account").Preload("TaxCode").
+		Order("transaction_date DESC, id DESC").
+		Limit(limit).
+		Find(&payments).Error
+	return payments, err
+}
+
+// filtered returns the query for a user's payments matching the filter
+func (r *PaymentRepository) filtered(userID uuid.UUID, filter PaymentFilter) *gorm.DB {
 	query := r.db.Model(&models.Payment{}).Where("user_id = ?", userID)
 
 	// Filter by status
@@ -51,6 +127,11 @@ func (r *PaymentRepository) FindAll(userID uuid.UUID, filter PaymentFilter) ([]m
 		query = query.Where("status = ?", filter.Status)
 	}
 
+	// Filter by direction
+	if filter.Direction != "" {
+		query = query.Where("direction = ?", filter.Direction)
+	}
+
 	// Search in description
 	if filter.Search != "" {
 		query = query.Where("description ILIKE ?", "%"+filter.Search+"%")
@@ -73,62 +154,299 @@ func (r *PaymentRepository) FindAll(userID uuid.UUID, filter PaymentFilter) ([]m
 		query = query.Where("transaction_date <= ?", *filter.EndDate)
 	}
 
-	// Get total count
-	query.Count(&total)
-
-	// Get paginated results with preloaded relations
-	query = query.Preload("User").Preload("PaymentMethod").Preload("Category").
-		Order("transaction_date DESC")
+	// Payment method and category
+	if filter.PaymentMethodID != nil {
+		query = query.Where("payment_method_id = ?", *filter.PaymentMethodID)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
 
-	if filter.Limit > 0 {
-		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	// Reconciliation state
+	if filter.Reconciled != nil {
+		query = query.Where("reconciled = ?", *filter.Reconciled)
 	}
 
-	err := query.Find(&payments).Error
+	return query
+}
 
-	return payments, total, err
+// FindAllByStatus returns the payments of all users with the given status, oldest first
+func (r *PaymentRepository) FindAllByStatus(status string) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.Preload("User").Preload("PaymentMethod").Preload("Category").Preload("Account").Preload("TaxCode").
+		Where("status = ?", status).
+		Order("created_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// change runs an update of a payment, moving the payment to its new daily
+// summary
+func (r *PaymentRepository) change(id uuid.UUID, update func(tx *gorm.DB) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := adjustDailySummary(tx, id, -1); err != nil {
+			return err
+		}
+		if err := update(tx); err != nil {
+			return err
+		}
+		return adjustDailySummary(tx, id, 1)
+	})
 }
 
+// Update saves the payment's own columns. Loaded associations are not saved,
+// so they cannot override the IDs that were changed.
 func (r *PaymentRepository) Update(payment *models.Payment) error {
-	return r.db.Save(payment).Error
+	return r.change(payment.ID, func(tx *gorm.DB) error {
+		return tx.Omit(clause.Associations).Save(payment).Error
+	})
 }
 
+// Delete removes a payment and its share of its daily summary
 func (r *PaymentRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Payment{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := adjustDailySummary(tx, id, -1); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Payment{}, "id = ?", id).Error
+	})
+}
+
+// statisticsSQL selects a statisticsRow, where count is the number of
+// payments a row stands for
+func statisticsSQL(count string) string {
+	return "COALESCE(SUM(" + count + "), 0) AS total_payments, " +
+		"COALESCE(SUM(CASE WHEN status = 'completed' THEN " + count + " ELSE 0 END), 0) AS completed_count, " +
+		"COALESCE(SUM(CASE WHEN status = 'pending' THEN " + count + " ELSE 0 END), 0) AS pending_count, " +
+		"COALESCE(SUM(CASE WHEN status = 'completed' AND direction = '" + models.PaymentDirectionIncome + "' THEN amount ELSE 0 END), 0) AS income, " +
+		"COALESCE(SUM(CASE WHEN status = 'completed' AND direction = '" + models.PaymentDirectionExpense + "' THEN amount ELSE 0 END), 0) AS expense, " +
+		"COALESCE(SUM(CASE WHEN status = 'completed' THEN fee ELSE 0 END), 0) AS fees"
+}
+
+type statisticsRow struct {
+	TotalPayments  int64
+	CompletedCount int64
+	PendingCount   int64
+	Income         float64
+	Expense        float64
+	Fees           float64
+}
+
+func (row *statisticsRow) statistics() *models.PaymentStatistics {
+	return &models.PaymentStatistics{
+		TotalPayments:  row.TotalPayments,
+		CompletedCount: row.CompletedCount,
+		PendingCount:   row.PendingCount,
+		TotalAmount:    row.Expense,
+		TotalIncome:    row.Income,
+		TotalExpense:   row.Expense,
+		TotalFees:      row.Fees,
+		Net:            row.Income - row.Expense - row.Fees,
+	}
 }
 
-// GetStatistics returns payment statistics for a user
-func (r *PaymentRepository) GetStatistics(userID uuid.UUID) (map[string]interface{}, error) {
-	var totalPayments int64
-	var totalAmount float64
-	var completedCount int64
-	var pendingCount int64
+// GetStatistics returns payment statistics for a user between from and to
+// (to exclusive), either of which may be nil. Totals only include
+// completed payments.
+func (r *PaymentRepository) GetStatistics(userID uuid.UUID, from, to *time.Time) (*models.PaymentStatistics, error) {
+	var row statisticsRow
 
-	r.db.Model(&models.Payment{}).Where("user_id = ?", userID).Count(&totalPayments)
-	r.db.Model(&models.Payment{}).Where("user_id = ? AND status = ?", userID, "completed").Count(&completedCount)
-	r.db.Model(&models.Payment{}).Where("user_id = ? AND status = ?", userID, "pending").Count(&pendingCount)
-	r.db.Model(&models.Payment{}).Where("user_id = ? AND status = ?", userID, "completed").
-		Select("COALESCE(SUM(amount), 0)").Scan(&totalAmount)
+	query := r.db.Model(&models.Payment{}).Where("user_id = ?", userID)
+	if from != nil {
+		query = query.Where("transaction_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("transaction_date < ?", *to)
+	}
 
-	return map[string]interface{}{
-		"total_payments":    totalPayments,
-		"completed_count":   completedCount,
-		"pending_count":     pendingCount,
-		"total_amount":      totalAmount,
-	}, nil
+	if err := query.Select(statisticsSQL("1")).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	return row.statistics(), nil
 }
 
-// GetMonthlyEarnings returns earnings grouped by month for a specific year
-func (r *PaymentRepository) GetMonthlyEarnings(userID uuid.UUID, year int) ([]models.MonthlyStats, error) {
+// GetMonthlyExpenses returns completed expenses grouped by month for a specific year,
+// with months in the given location
+func (r *PaymentRepository) GetMonthlyExpenses(userID uuid.UUID, year int, loc *time.Location) ([]models.MonthlyStats, error) {
 	var stats []models.MonthlyStats
 
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+
 	// Postgres specific query
 	err := r.db.Model(&models.Payment{}).
-		Select("TO_CHAR(transaction_date, 'Mon') as month, SUM(amount) as total_amount, COUNT(*) as count").
-		Where("user_id = ? AND status = ? AND EXTRACT(YEAR FROM transaction_date) = ?", userID, "completed", year).
-		Group("TO_CHAR(transaction_date, 'Mon'), EXTRACT(MONTH FROM transaction_date)").
-		Order("EXTRACT(MONTH FROM transaction_date)").
+		Select("TO_CHAR(transaction_date AT TIME ZONE ?, 'Mon') as month, SUM(amount) as total_amount, COUNT(*) as count", loc.String()).
+		Where("user_id = ? AND status = ? AND direction = ? AND transaction_date >= ? AND transaction_date < ?", userID, "completed", models.PaymentDirectionExpense, start, end).
+		Group("month").
+		Order("MIN(transaction_date)").
 		Scan(&stats).Error
 
 	return stats, err
 }
+
+// GetCashFlow returns the money that came in and went out of the user's
+// accounts per month in the given location between start and end (end
+// exclusive). Months without payments are left out.
+func (r *PaymentRepository) GetCashFlow(userID uuid.UUID, start, end time.Time, loc *time.Location) ([]models.CashFlowPoint, error) {
+	var points []models.CashFlowPoint
+
+	err := r.db.Model(&models.Payment{}).
+		Select("TO_CHAR(transaction_date AT TIME ZONE ?, 'YYYY-MM') as month, "+
+			"COALESCE(SUM("+paymentInflowSQL+"), 0) as inflow, "+
+			"COALESCE(SUM("+paymentOutflowSQL+"), 0) as outflow", loc.String()).
+		Where("user_id = ? AND transaction_date >= ? AND transaction_date < ?", userID, start, end).
+		Group("month").
+		Order("month").
+		Scan(&points).Error
+
+	return points, err
+}
+
+// BreakdownFilter selects the payments aggregated by Breakdown
+type BreakdownFilter struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	Direction string
+	Status    string
+}
+
+// breakdownAggregates are the columns Breakdown computes for every group
+const breakdownAggregates = "COUNT(*) AS count, COALESCE(SUM(payments.amount), 0) AS total, COALESCE(AVG(payments.amount), 0) AS average"
+
+// Breakdown aggregates the user's payments by category, payment_method or
+// status, largest total first
+func (r *PaymentRepository) Breakdown(userID uuid.UUID, groupBy string, filter BreakdownFilter) ([]models.BreakdownRow, error) {
+	var rows []models.BreakdownRow
+
+	query := r.db.Model(&models.Payment{}).Where("payments.user_id = ?", userID)
+	switch groupBy {
+	case "category":
+		query = query.Select("CAST(payments.category_id AS TEXT) AS key, COALESCE(categories.name, '') AS label, " + breakdownAggregates).
+			Joins("LEFT JOIN categories ON categories.id = payments.category_id").
+			Group("payments.category_id, categories.name")
+	case "payment_method":
+		query = query.Select("CAST(payments.payment_method_id AS TEXT) AS key, COALESCE(payment_methods.name, '') AS label, " + breakdownAggregates).
+			Joins("LEFT JOIN payment_methods ON payment_methods.id = payments.payment_method_id").
+			Group("payments.payment_method_id, payment_methods.name")
+	case "status":
+		query = query.Select("payments.status AS key, payments.status AS label, " + breakdownAggregates).
+			Group("payments.status")
+	default:
+		return nil, fmt.Errorf("invalid breakdown grouping %q", groupBy)
+	}
+
+	if filter.StartDate != nil {
+		query = query.Where("payments.transaction_date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("payments.transaction_date <= ?", *filter.EndDate)
+	}
+	if filter.Direction != "" {
+		query = query.Where("payments.direction = ?", filter.Direction)
+	}
+	if filter.Status != "" {
+		query = query.Where("payments.status = ?", filter.Status)
+	}
+
+	err := query.Order("total DESC, label ASC").Scan(&rows).Error
+	return rows, err
+}
+
+// TaxSummary totals the tax of the user's completed income and expenses that
+// have a tax code or tax amount, dated between start and end (end exclusive),
+// per tax code, direction and period starting at the given unit (month,
+// quarter or year) in loc
+func (r *PaymentRepository) TaxSummary(userID uuid.UUID, unit string, start, end time.Time, direction string, loc *time.Location) ([]models.TaxSummaryRow, error) {
+	var rows []models.TaxSummaryRow
+
+	query := r.db.Model(&models.Payment{}).
+		Select("DATE_TRUNC(?, payments.transaction_date AT TIME ZONE ?) AS period_start, "+
+			"payments.tax_code_id, COALESCE(tax_codes.code, '') AS tax_code, COALESCE(tax_codes.name, '') AS tax_name, payments.direction, "+
+			"COUNT(*) AS count, "+
+			"COALESCE(SUM(CASE WHEN payments.tax_inclusive THEN payments.amount - payments.tax_amount ELSE payments.amount END), 0) AS net, "+
+			"COALESCE(SUM(payments.tax_amount), 0) AS tax, "+
+			"COALESCE(SUM(CASE WHEN payments.tax_inclusive THEN payments.amount ELSE payments.amount + payments.tax_amount END), 0) AS gross",
+			unit, loc.String()).
+		Joins("LEFT JOIN tax_codes ON tax_codes.id = payments.tax_code_id").
+		Where("payments.user_id = ? AND payments.status = ?", userID, "completed").
+		Where("payments.transaction_date >= ? AND payments.transaction_date < ?", start, end).
+		Where("payments.tax_code_id IS NOT NULL OR payments.tax_amount <> 0")
+	if direction != "" {
+		query = query.Where("payments.direction = ?", direction)
+	} else {
+		query = query.Where("payments.direction IN ?", []string{models.PaymentDirectionIncome, models.PaymentDirectionExpense})
+	}
+
+	err := query.Group("period_start, payments.tax_code_id, tax_codes.code, tax_codes.name, payments.direction").
+		Order("period_start, tax_code, payments.direction").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// FindExpenses returns the user's completed, pending and in-review expenses
+// dated between start and end (end exclusive), oldest first
+func (r *PaymentRepository) FindExpenses(userID uuid.UUID, start, end time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.Preload("Category").
+		Where("user_id = ? AND direction = ? AND status IN ?", userID, models.PaymentDirectionExpense, []string{"completed", "pending", "review"}).
+		Where("transaction_date >= ? AND transaction_date < ?", start, end).
+		Order("transaction_date ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+// FirstExpenseDates returns the date of the user's first completed expense
+// in each category
+func (r *PaymentRepository) FirstExpenseDates(userID uuid.UUID) (map[uuid.UUID]time.Time, error) {
+	var rows []struct {
+		CategoryID uuid.UUID
+		First      time.Time
+	}
+	err := r.db.Model(&models.Payment{}).
+		Select("category_id, MIN(transaction_date) AS first").
+		Where("user_id = ? AND direction = ? AND status = ?", userID, models.PaymentDirectionExpense, "completed").
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[uuid.UUID]time.Time, len(rows))
+	for _, row := range rows {
+		dates[row.CategoryID] = row.First
+	}
+	return dates, nil
+}
+
+// SetInvoice links a payment to an invoice, or unlinks it when invoiceID is nil
+func (r *PaymentRepository) SetInvoice(paymentID uuid.UUID, invoiceID *uuid.UUID) error {
+	return r.db.Model(&models.Payment{}).Where("id = ?", paymentID).Update("invoice_id", invoiceID).Error
+}
+
+// SetReconciled marks a payment as reconciled (or not) against a bank statement
+func (r *PaymentRepository) SetReconciled(paymentID uuid.UUID, reconciled bool) error {
+	return r.db.Model(&models.Payment{}).Where("id = ?", paymentID).Update("reconciled", reconciled).Error
+}
+
+// FindByProviderReference finds the payment charged at a provider under the given reference
+func (r *PaymentRepository) FindByProviderReference(provider, reference string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Preload("User").Preload("PaymentMethod").Preload("Category").Preload("Account").Preload("TaxCode").
+		First(&payment, "provider = ? AND provider_reference = ?", provider, reference).Error
+	return &payment, err
+}
+
+// UpdateCategorization sets the category, payee and tags of a payment
+func (r *PaymentRepository) UpdateCategorization(id, categoryID uuid.UUID, payee string, tags []string) error {
+	return r.change(id, func(tx *gorm.DB) error {
+		return tx.Model(&models.Payment{ID: id}).Select("category_id", "payee", "tags").
+			Updates(&models.Payment{CategoryID: categoryID, Payee: payee, Tags: tags}).Error
+	})
+}
+
+// UpdateFields updates selected columns of a payment
+func (r *PaymentRepository) UpdateFields(id uuid.UUID, fields map[string]interface{}) error {
+	return r.change(id, func(tx *gorm.DB) error {
+		return tx.Model(&models.Payment{}).Where("id = ?", id).Updates(fields).Error
+	})
+}
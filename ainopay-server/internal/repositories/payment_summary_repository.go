@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// summaryKey identifies a daily summary row; summarySelect selects it for a
+// payment p of user u
+const (
+	summaryKey    = "user_id, day, category_id, payment_method_id, status, direction"
+	summarySelect = "p.user_id, (p.transaction_date AT TIME ZONE u.timezone)::date, p.category_id, p.payment_method_id, p.status, p.direction"
+)
+
+// PaymentSummaryRepository reads and rebuilds the daily payment summaries.
+// PaymentRepository keeps them up to date as payments change.
+type PaymentSummaryRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentSummaryRepository(db *gorm.DB) *PaymentSummaryRepository {
+	return &PaymentSummaryRepository{db: db}
+}
+
+// WithTx returns a repository that runs its queries in tx
+func (r *PaymentSummaryRepository) WithTx(tx *gorm.DB) *PaymentSummaryRepository {
+	return &PaymentSummaryRepository{db: tx}
+}
+
+// adjustDailySummary adds a payment to its daily summary with a sign of 1, or
+// removes it with -1. The user row is locked in share mode so that the day
+// cannot move to another timezone while the change is pending.
+func adjustDailySummary(db *gorm.DB, paymentID uuid.UUID, sign int) error {
+	err := db.Exec("INSERT INTO payment_daily_summaries ("+summaryKey+", count, amount, fee) "+
+		"SELECT "+summarySelect+", ?, ? * p.amount, ? * p.fee "+
+		"FROM payments p JOIN users u ON u.id = p.user_id WHERE p.id = ? FOR SHARE OF u "+
+		"ON CONFLICT ("+summaryKey+") DO UPDATE SET "+
+		"count = payment_daily_summaries.count + EXCLUDED.count, "+
+		"amount = payment_daily_summaries.amount + EXCLUDED.amount, "+
+		"fee = payment_daily_summaries.fee + EXCLUDED.fee",
+		sign, sign, sign, paymentID).Error
+	if err != nil || sign > 0 {
+		return err
+	}
+
+	return db.Exec("DELETE FROM payment_daily_summaries WHERE count = 0 AND user_id = (SELECT user_id FROM payments WHERE id = ?)", paymentID).Error
+}
+
+// Lock keeps payments from changing the summaries until the transaction ends
+func (r *PaymentSummaryRepository) Lock() error {
+	return r.db.Exec("LOCK TABLE payment_daily_summaries IN SHARE ROW EXCLUSIVE MODE").Error
+}
+
+// Rebuild recomputes the summaries of a user, or of all users when userID is
+// nil, from their payments. It must run in a transaction.
+func (r *PaymentSummaryRepository) Rebuild(userID *uuid.UUID) error {
+	if err := r.Lock(); err != nil {
+		return err
+	}
+
+	remove := r.db.Where("1 = 1")
+	if userID != nil {
+		remove = r.db.Where("user_id = ?", *userID)
+	}
+	if err := remove.Delete(&models.PaymentDailySummary{}).Error; err != nil {
+		return err
+	}
+
+	query := "INSERT INTO payment_daily_summaries (" + summaryKey + ", count, amount, fee) " +
+		"SELECT " + summarySelect + ", COUNT(*), SUM(p.amount), SUM(p.fee) " +
+		"FROM payments p JOIN users u ON u.id = p.user_id"
+	var args []interface{}
+	if userID != nil {
+		query += " WHERE p.user_id = ?"
+		args = append(args, *userID)
+	}
+	query += " GROUP BY " + summarySelect
+	return r.db.Exec(query, args...).Error
+}
+
+// forDays selects the summaries of a user from the day of from through the
+// day before to, either of which may be nil. The bounds are expected to be
+// midnights in the user's timezone.
+func (r *PaymentSummaryRepository) forDays(userID uuid.UUID, from, to *time.Time) *gorm.DB {
+	query := r.db.Model(&models.PaymentDailySummary{}).Where("user_id = ?", userID)
+	if from != nil {
+		query = query.Where("day >= ?", from.Format("2006-01-02"))
+	}
+	if to != nil {
+		query = query.Where("day < ?", to.Format("2006-01-02"))
+	}
+	return query
+}
+
+// GetStatistics is PaymentRepository.GetStatistics computed from the summaries
+func (r *PaymentSummaryRepository) GetStatistics(userID uuid.UUID, from, to *time.Time) (*models.PaymentStatistics, error) {
+	var row statisticsRow
+	if err := r.forDays(userID, from, to).Select(statisticsSQL("count")).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+	return row.statistics(), nil
+}
+
+// GetMonthlyExpenses is PaymentRepository.GetMonthlyExpenses computed from
+// the summaries, with months in the user's timezone
+func (r *PaymentSummaryRepository) GetMonthlyExpenses(userID uuid.UUID, year int) ([]models.MonthlyStats, error) {
+	var stats []models.MonthlyStats
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+
+	err := r.forDays(userID, &start, &end).
+		Select("TO_CHAR(day, 'Mon') as month, SUM(amount) as total_amount, SUM(count) as count").
+		Where("status = ? AND direction = ?", "completed", models.PaymentDirectionExpense).
+		Group("month").
+		Order("MIN(day)").
+		Scan(&stats).Error
+
+	return stats, err
+}
+
+// GetCashFlow is PaymentRepository.GetCashFlow computed from the summaries,
+// with months in the user's timezone
+func (r *PaymentSummaryRepository) GetCashFlow(userID uuid.UUID, start, end time.Time) ([]models.CashFlowPoint, error) {
+	var points []models.CashFlowPoint
+
+	err := r.forDays(userID, &start, &end).
+		Select("TO_CHAR(day, 'YYYY-MM') as month, " +
+			"COALESCE(SUM(" + paymentInflowSQL + "), 0) as inflow, " +
+			"COALESCE(SUM(" + paymentOutflowSQL + "), 0) as outflow").
+		Group("month").
+		Order("month").
+		Scan(&points).Error
+
+	return points, err
+}
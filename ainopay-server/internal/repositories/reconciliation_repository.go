@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+// Transaction runs fn in a database transaction
+func (r *ReconciliationRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a repository that runs its queries in tx
+func (r *ReconciliationRepository) WithTx(tx *gorm.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: tx}
+}
+
+// CreateSession creates a session together with its statement lines
+func (r *ReconciliationRepository) CreateSession(session *models.ReconciliationSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *ReconciliationRepository) FindSessionByID(id uuid.UUID) (*models.ReconciliationSession, error) {
+	var session models.ReconciliationSession
+	err := r.db.
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("line_number ASC")
+		}).
+		Preload("Lines.Payment").Preload("Lines.Payment.Category").Preload("Lines.Payment.PaymentMethod").
+		First(&session, "id = ?", id).Error
+	return &session, err
+}
+
+func (r *ReconciliationRepository) FindSessionsByUserID(userID uuid.UUID) ([]models.ReconciliationSession, error) {
+	var sessions []models.ReconciliationSession
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *ReconciliationRepository) UpdateSessionStatus(id uuid.UUID, status string) error {
+	return r.db.Model(&models.ReconciliationSession{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *ReconciliationRepository) FindLine(sessionID, lineID uuid.UUID) (*models.StatementLine, error) {
+	var line models.StatementLine
+	err := r.db.First(&line, "id = ? AND session_id = ?", lineID, sessionID).Error
+	return &line, err
+}
+
+// LockLine is FindLine that locks the line until the transaction ends, so
+// that concurrent changes to it are applied one at a time
+func (r *ReconciliationRepository) LockLine(sessionID, lineID uuid.UUID) (*models.StatementLine, error) {
+	var line models.StatementLine
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&line, "id = ? AND session_id = ?", lineID, sessionID).Error
+	return &line, err
+}
+
+func (r *ReconciliationRepository) UpdateLine(line *models.StatementLine) error {
+	return r.db.Omit("Payment").Save(line).Error
+}
+
+// MatchCandidateFilter selects the payments a statement line can be matched to
+type MatchCandidateFilter struct {
+	MinAmount     float64
+	MaxAmount     float64
+	StartDate     time.Time
+	EndDate       time.Time
+	Income        bool      // income payments for credits, other directions for debits
+	Currency      string    // currency of the payment's account, models.DefaultCurrency without one; any when empty
+	ExcludeLineID uuid.UUID // line whose own claim on a payment is ignored
+}
+
+// FindMatchCandidates returns the user's unreconciled payments that are not
+// failed, match the filter and are not proposed for or matched to another line
+func (r *ReconciliationRepository) FindMatchCandidates(userID uuid.UUID, filter MatchCandidateFilter) ([]models.Payment, error) {
+	claimed := r.db.Session(&gorm.Session{NewDB: true}).Model(&models.StatementLine{}).Select("1").
+		Where("statement_lines.payment_id = payments.id AND statement_lines.id <> ? AND statement_lines.status IN ?", filter.ExcludeLineID,
+			[]string{models.StatementLineProposed, models.StatementLineConfirmed, models.StatementLineCreated})
+
+	query := r.db.Model(&models.Payment{}).
+		Where("payments.user_id = ? AND payments.reconciled = ? AND payments.status <> ?", userID, false, "failed").
+		Where("payments.amount >= ? AND payments.amount <= ?", filter.MinAmount, filter.MaxAmount).
+		Where("payments.transaction_date >= ? AND payments.transaction_date <= ?", filter.StartDate, filter.EndDate).
+		Where("NOT EXISTS (?)", claimed)
+
+	if filter.Income {
+		query = query.Where("payments.direction = ?", models.PaymentDirectionIncome)
+	} else {
+		query = query.Where("payments.direction <> ?", models.PaymentDirectionIncome)
+	}
+
+	if filter.Currency != "" {
+		query = query.Where("COALESCE((SELECT financial_accounts.currency FROM financial_accounts WHERE financial_accounts.id = payments.account_id), ?) = ?",
+			models.DefaultCurrency, filter.Currency)
+	}
+
+	var payments []models.Payment
+	err := query.Order("payments.transaction_date DESC").Find(&payments).Error
+	return payments, err
+}
+
+// IsPaymentClaimed reports whether a payment is already proposed for, or
+// matched to, a line other than excludeLineID
+func (r *ReconciliationRepository) IsPaymentClaimed(paymentID, excludeLineID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.StatementLine{}).
+		Where("payment_id = ? AND id <> ? AND status IN ?", paymentID, excludeLineID,
+			[]string{models.StatementLineProposed, models.StatementLineConfirmed, models.StatementLineCreated}).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReportSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewReportSubscriptionRepository(db *gorm.DB) *ReportSubscriptionRepository {
+	return &ReportSubscriptionRepository{db: db}
+}
+
+func (r *ReportSubscriptionRepository) Create(subscription *models.ReportSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *ReportSubscriptionRepository) FindByID(id uuid.UUID) (*models.ReportSubscription, error) {
+	var subscription models.ReportSubscription
+	err := r.db.First(&subscription, "id = ?", id).Error
+	return &subscription, err
+}
+
+func (r *ReportSubscriptionRepository) FindByUserID(userID uuid.UUID) ([]models.ReportSubscription, error) {
+	var subscriptions []models.ReportSubscription
+	err := r.db.Where("user_id = ?", userID).Order("frequency ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// ExistsForFrequency reports whether the user has a subscription with the
+// given frequency other than excludeID
+func (r *ReportSubscriptionRepository) ExistsForFrequency(userID uuid.UUID, frequency string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ReportSubscription{}).
+		Where("user_id = ? AND frequency = ? AND id <> ?", userID, frequency, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindDue returns active subscriptions whose next run is due, oldest first
+func (r *ReportSubscriptionRepository) FindDue(now time.Time, limit int) ([]models.ReportSubscription, error) {
+	var subscriptions []models.ReportSubscription
+	err := r.db.
+		Where("is_active = ? AND next_run_at <= ?", true, now).
+		Order("next_run_at ASC").
+		Limit(limit).
+		Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// Claim pushes the next run of a subscription out by lease so that no other
+// scheduler sends it at the same time. It reports false when the
+// subscription was already claimed or changed elsewhere.
+func (r *ReportSubscriptionRepository) Claim(subscription *models.ReportSubscription, lease time.Duration) (bool, error) {
+	result := r.db.Model(&models.ReportSubscription{}).
+		Where("id = ? AND next_run_at = ?", subscription.ID, subscription.NextRunAt).
+		Update("next_run_at", time.Now().Add(lease))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordRun stores the schedule and outcome of a run
+func (r *ReportSubscriptionRepository) RecordRun(subscription *models.ReportSubscription) error {
+	return r.db.Model(subscription).
+		Select("period_end", "next_run_at", "last_sent_at", "last_error").
+		Updates(subscription).Error
+}
+
+func (r *ReportSubscriptionRepository) Update(subscription *models.ReportSubscription) error {
+	return r.db.Save(subscription).Error
+}
+
+func (r *ReportSubscriptionRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.ReportSubscription{}, "id = ?", id).Error
+}
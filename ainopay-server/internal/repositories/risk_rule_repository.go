@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RiskRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewRiskRuleRepository(db *gorm.DB) *RiskRuleRepository {
+	return &RiskRuleRepository{db: db}
+}
+
+// WithTx returns a repository that runs its queries in tx
+func (r *RiskRuleRepository) WithTx(tx *gorm.DB) *RiskRuleRepository {
+	return &RiskRuleRepository{db: tx}
+}
+
+func (r *RiskRuleRepository) Create(rule *models.RiskRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *RiskRuleRepository) FindByID(id uuid.UUID) (*models.RiskRule, error) {
+	var rule models.RiskRule
+	err := r.db.First(&rule, "id = ?", id).Error
+	return &rule, err
+}
+
+func (r *RiskRuleRepository) FindAll() ([]models.RiskRule, error) {
+	var rules []models.RiskRule
+	err := r.db.Order("created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *RiskRuleRepository) FindActive() ([]models.RiskRule, error) {
+	var rules []models.RiskRule
+	err := r.db.Where("is_active = ?", true).Order("created_at ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *RiskRuleRepository) Update(rule *models.RiskRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *RiskRuleRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.RiskRule{}, "id = ?", id).Error
+}
+
+// CategoryHistory returns the average amount and number of the user's
+// completed payments in a category and direction since the given time
+func (r *RiskRuleRepository) CategoryHistory(userID, categoryID uuid.UUID, direction string, since time.Time) (float64, int64, error) {
+	var history struct {
+		Average float64
+		Count   int64
+	}
+	err := r.db.Model(&models.Payment{}).
+		Select("COALESCE(AVG(amount), 0) AS average, COUNT(*) AS count").
+		Where("user_id = ? AND category_id = ? AND direction = ? AND status = ?", userID, categoryID, direction, "completed").
+		Where("transaction_date >= ?", since).
+		Scan(&history).Error
+	return history.Average, history.Count, err
+}
+
+// CountCreatedSince counts the payments the user created since the given time
+func (r *RiskRuleRepository) CountCreatedSince(userID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Payment{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&count).Error
+	return count, err
+}
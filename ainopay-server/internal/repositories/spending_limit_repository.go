@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SpendingLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewSpendingLimitRepository(db *gorm.DB) *SpendingLimitRepository {
+	return &SpendingLimitRepository{db: db}
+}
+
+// WithTx returns a repository that runs its queries in tx
+func (r *SpendingLimitRepository) WithTx(tx *gorm.DB) *SpendingLimitRepository {
+	return &SpendingLimitRepository{db: tx}
+}
+
+func (r *SpendingLimitRepository) Create(limit *models.SpendingLimit) error {
+	return r.db.Create(limit).Error
+}
+
+func (r *SpendingLimitRepository) FindByID(id uuid.UUID) (*models.SpendingLimit, error) {
+	var limit models.SpendingLimit
+	err := r.db.Preload("PaymentMethod").Preload("Category").First(&limit, "id = ?", id).Error
+	return &limit, err
+}
+
+func (r *SpendingLimitRepository) FindByUserID(userID uuid.UUID) ([]models.SpendingLimit, error) {
+	var limits []models.SpendingLimit
+	err := r.db.Preload("PaymentMethod").Preload("Category").
+		Where("user_id = ?", userID).
+		Order("period ASC, created_at ASC").
+		Find(&limits).Error
+	return limits, err
+}
+
+// LockByUserID returns the user's limits, locking them until the transaction
+// ends so that concurrent payments of the user are checked one at a time
+func (r *SpendingLimitRepository) LockByUserID(userID uuid.UUID) ([]models.SpendingLimit, error) {
+	var limits []models.SpendingLimit
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&limits).Error
+	return limits, err
+}
+
+func (r *SpendingLimitRepository) Update(limit *models.SpendingLimit) error {
+	return r.db.Omit("PaymentMethod", "Category").Save(limit).Error
+}
+
+func (r *SpendingLimitRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.SpendingLimit{}, "id = ?", id).Error
+}
+
+// Spending sums the amount and fee of the pending, in review and completed expenses
+// between start and end (end exclusive) that a limit covers, leaving out excludeID
+func (r *SpendingLimitRepository) Spending(limit *models.SpendingLimit, start, end time.Time, excludeID uuid.UUID) (float64, error) {
+	query := r.db.Model(&models.Payment{}).
+		Where("user_id = ? AND direction = ? AND status IN ?", limit.UserID, models.PaymentDirectionExpense, []string{"pending", "review", "completed"}).
+		Where("transaction_date >= ? AND transaction_date < ?", start, end).
+		Where("id <> ?", excludeID)
+	if limit.PaymentMethodID != nil {
+		query = query.Where("payment_method_id = ?", *limit.PaymentMethodID)
+	}
+	if limit.CategoryID != nil {
+		query = query.Where("category_id = ?", *limit.CategoryID)
+	}
+
+	var total float64
+	err := query.Select("COALESCE(SUM(amount + fee), 0)").Scan(&total).Error
+	return total, err
+}
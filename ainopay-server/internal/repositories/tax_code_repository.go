@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaxCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxCodeRepository(db *gorm.DB) *TaxCodeRepository {
+	return &TaxCodeRepository{db: db}
+}
+
+func (r *TaxCodeRepository) Create(code *models.TaxCode) error {
+	return r.db.Create(code).Error
+}
+
+func (r *TaxCodeRepository) FindByID(id uuid.UUID) (*models.TaxCode, error) {
+	var code models.TaxCode
+	err := r.db.First(&code, "id = ?", id).Error
+	return &code, err
+}
+
+// FindByIDs returns the tax codes with the given IDs, in no particular order
+func (r *TaxCodeRepository) FindByIDs(ids []uuid.UUID) ([]models.TaxCode, error) {
+	var codes []models.TaxCode
+	err := r.db.Where("id IN ?", ids).Find(&codes).Error
+	return codes, err
+}
+
+// FindByCode finds a tax code by its code, ignoring case
+func (r *TaxCodeRepository) FindByCode(code string) (*models.TaxCode, error) {
+	var taxCode models.TaxCode
+	err := r.db.First(&taxCode, "LOWER(code) = LOWER(?)", code).Error
+	return &taxCode, err
+}
+
+// FindAll returns the tax codes, only the active ones unless all is set
+func (r *TaxCodeRepository) FindAll(all bool) ([]models.TaxCode, error) {
+	var codes []models.TaxCode
+	query := r.db.Order("code ASC")
+	if !all {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Find(&codes).Error
+	return codes, err
+}
+
+func (r *TaxCodeRepository) Update(code *models.TaxCode) error {
+	return r.db.Save(code).Error
+}
+
+func (r *TaxCodeRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&models.TaxCode{}, "id = ?", id).Error
+}
+
+// InUse reports whether any payment has the tax code
+func (r *TaxCodeRepository) InUse(id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Payment{}).Where("tax_code_id = ?", id).Limit(1).Count(&count).Error
+	return count > 0, err
+}
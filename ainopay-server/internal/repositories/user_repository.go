@@ -15,6 +15,11 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// WithTx returns a repository that runs its queries in tx
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{db: tx}
+}
+
 func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
@@ -31,6 +36,20 @@ func (r *UserRepository) FindByID(id uuid.UUID) (*models.User, error) {
 	return &user, err
 }
 
+// FindByRole returns the users with a role, such as admin
+func (r *UserRepository) FindByRole(role string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Where("role = ?", role).Order("created_at ASC").Find(&users).Error
+	return users, err
+}
+
+// FindTimezone returns the timezone preference of a user
+func (r *UserRepository) FindTimezone(id uuid.UUID) (string, error) {
+	var timezone string
+	err := r.db.Model(&models.User{}).Where("id = ?", id).Select("timezone").Scan(&timezone).Error
+	return timezone, err
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"ainopay-server/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *WebhookRepository) FindSubscriptionByID(id uuid.UUID) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	err := r.db.First(&subscription, "id = ?", id).Error
+	return &subscription, err
+}
+
+func (r *WebhookRepository) FindSubscriptionsByUserID(userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// FindActiveSubscriptions returns the subscriptions that should receive a user's events
+func (r *WebhookRepository) FindActiveSubscriptions(userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := r.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+func (r *WebhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	return r.db.Save(subscription).Error
+}
+
+// DeleteSubscription removes a subscription together with its delivery history
+func (r *WebhookRepository) DeleteSubscription(id uuid.UUID) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.WebhookSubscription{}, "id = ?", id).Error
+	})
+}
+
+func (r *WebhookRepository) CreateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *WebhookRepository) FindDeliveryByID(id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Preload("Attempts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("attempt_number ASC")
+	}).First(&delivery, "id = ?", id).Error
+	return &delivery, err
+}
+
+// DeliveryFilter options
+type DeliveryFilter struct {
+	Limit          int
+	Offset         int
+	Status         string
+	SubscriptionID *uuid.UUID
+}
+
+func (r *WebhookRepository) FindDeliveries(userID uuid.UUID, filter DeliveryFilter) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.db.Model(&models.WebhookDelivery{}).Where("user_id = ?", userID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.SubscriptionID != nil {
+		query = query.Where("subscription_id = ?", *filter.SubscriptionID)
+	}
+
+	query.Count(&total)
+
+	query = query.Order("created_at DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit).Offset(filter.Offset)
+	}
+
+	err := query.Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// DeliveryExists reports whether an event was already queued for a subscription
+func (r *WebhookRepository) DeliveryExists(subscriptionID, eventID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.WebhookDelivery{}).
+		Where("subscription_id = ? AND event_id = ?", subscriptionID, eventID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindDue returns deliveries whose next attempt is due
+func (r *WebhookRepository) FindDue(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.
+		Where("status IN ? AND next_attempt_at <= ?", []string{models.DeliveryStatusPending, models.DeliveryStatusFailed}, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// Claim pushes the next attempt of a delivery out by lease so that no other
+// worker picks it up while it is in flight. It reports false when the
+// delivery was already claimed elsewhere.
+func (r *WebhookRepository) Claim(delivery *models.WebhookDelivery, lease time.Duration) (bool, error) {
+	leaseUntil := time.Now().Add(lease)
+	result := r.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND next_attempt_at = ?", delivery.ID, delivery.NextAttemptAt).
+		Update("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RecordAttempt stores the outcome of an attempt and the delivery's new state
+func (r *WebhookRepository) RecordAttempt(delivery *models.WebhookDelivery, attempt *models.WebhookDeliveryAttempt) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(attempt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempt_count":   delivery.AttemptCount,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_error":      delivery.LastError,
+			"response_status": delivery.ResponseStatus,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+	})
+}
+
+// Requeue schedules a delivery for an immediate fresh round of attempts
+func (r *WebhookRepository) Requeue(id uuid.UUID) error {
+	now := time.Now()
+	return r.db.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          models.DeliveryStatusPending,
+		"attempt_count":   0,
+		"next_attempt_at": now,
+		"last_error":      "",
+	}).Error
+}
+
+// CountAttempts returns how many attempts a delivery has had over its lifetime
+func (r *WebhookRepository) CountAttempts(deliveryID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WebhookDeliveryAttempt{}).Where("delivery_id = ?", deliveryID).Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,1162 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: ainopay/v1/payment.proto
+
+package ainopayv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Payment struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Amount float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Fee    float64                `protobuf:"fixed64,3,opt,name=fee,proto3" json:"fee,omitempty"`
+	// pending, review, completed, failed or refunded
+	Status string `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	// income, expense or transfer
+	Direction     string         `protobuf:"bytes,5,opt,name=direction,proto3" json:"direction,omitempty"`
+	Category      *Category      `protobuf:"bytes,6,opt,name=category,proto3" json:"category,omitempty"`
+	PaymentMethod *PaymentMethod `protobuf:"bytes,7,opt,name=payment_method,json=paymentMethod,proto3" json:"payment_method,omitempty"`
+	// Empty when the payment is not linked to an account
+	AccountId       string                 `protobuf:"bytes,8,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Description     string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
+	Payee           string                 `protobuf:"bytes,10,opt,name=payee,proto3" json:"payee,omitempty"`
+	Tags            []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	TransactionTime *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=transaction_time,json=transactionTime,proto3" json:"transaction_time,omitempty"`
+	Tax             *PaymentTax            `protobuf:"bytes,13,opt,name=tax,proto3" json:"tax,omitempty"`
+	Reconciled      bool                   `protobuf:"varint,14,opt,name=reconciled,proto3" json:"reconciled,omitempty"`
+	OverLimit       bool                   `protobuf:"varint,15,opt,name=over_limit,json=overLimit,proto3" json:"over_limit,omitempty"`
+	CreateTime      *timestamppb.Timestamp `protobuf:"bytes,16,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime      *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Payment) Reset() {
+	*x = Payment{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Payment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Payment) ProtoMessage() {}
+
+func (x *Payment) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Payment.ProtoReflect.Descriptor instead.
+func (*Payment) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Payment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Payment) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Payment) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *Payment) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Payment) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *Payment) GetCategory() *Category {
+	if x != nil {
+		return x.Category
+	}
+	return nil
+}
+
+func (x *Payment) GetPaymentMethod() *PaymentMethod {
+	if x != nil {
+		return x.PaymentMethod
+	}
+	return nil
+}
+
+func (x *Payment) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Payment) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Payment) GetPayee() string {
+	if x != nil {
+		return x.Payee
+	}
+	return ""
+}
+
+func (x *Payment) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Payment) GetTransactionTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TransactionTime
+	}
+	return nil
+}
+
+func (x *Payment) GetTax() *PaymentTax {
+	if x != nil {
+		return x.Tax
+	}
+	return nil
+}
+
+func (x *Payment) GetReconciled() bool {
+	if x != nil {
+		return x.Reconciled
+	}
+	return false
+}
+
+func (x *Payment) GetOverLimit() bool {
+	if x != nil {
+		return x.OverLimit
+	}
+	return false
+}
+
+func (x *Payment) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Payment) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+type PaymentTax struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty when the tax was entered without a tax code
+	TaxCodeId string `protobuf:"bytes,1,opt,name=tax_code_id,json=taxCodeId,proto3" json:"tax_code_id,omitempty"`
+	// Percent
+	Rate   float64 `protobuf:"fixed64,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	Amount float64 `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// The payment amount includes the tax
+	Inclusive     bool   `protobuf:"varint,4,opt,name=inclusive,proto3" json:"inclusive,omitempty"`
+	InvoiceNumber string `protobuf:"bytes,5,opt,name=invoice_number,json=invoiceNumber,proto3" json:"invoice_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentTax) Reset() {
+	*x = PaymentTax{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentTax) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentTax) ProtoMessage() {}
+
+func (x *PaymentTax) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentTax.ProtoReflect.Descriptor instead.
+func (*PaymentTax) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PaymentTax) GetTaxCodeId() string {
+	if x != nil {
+		return x.TaxCodeId
+	}
+	return ""
+}
+
+func (x *PaymentTax) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *PaymentTax) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *PaymentTax) GetInclusive() bool {
+	if x != nil {
+		return x.Inclusive
+	}
+	return false
+}
+
+func (x *PaymentTax) GetInvoiceNumber() string {
+	if x != nil {
+		return x.InvoiceNumber
+	}
+	return ""
+}
+
+type PaymentTaxInput struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	TaxCodeId *string                `protobuf:"bytes,1,opt,name=tax_code_id,json=taxCodeId,proto3,oneof" json:"tax_code_id,omitempty"`
+	// Percent, defaults to the tax code's rate
+	Rate *float64 `protobuf:"fixed64,2,opt,name=rate,proto3,oneof" json:"rate,omitempty"`
+	// Computed from the rate when not set
+	Amount *float64 `protobuf:"fixed64,3,opt,name=amount,proto3,oneof" json:"amount,omitempty"`
+	// Defaults to true
+	Inclusive     *bool  `protobuf:"varint,4,opt,name=inclusive,proto3,oneof" json:"inclusive,omitempty"`
+	InvoiceNumber string `protobuf:"bytes,5,opt,name=invoice_number,json=invoiceNumber,proto3" json:"invoice_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentTaxInput) Reset() {
+	*x = PaymentTaxInput{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentTaxInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentTaxInput) ProtoMessage() {}
+
+func (x *PaymentTaxInput) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentTaxInput.ProtoReflect.Descriptor instead.
+func (*PaymentTaxInput) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PaymentTaxInput) GetTaxCodeId() string {
+	if x != nil && x.TaxCodeId != nil {
+		return *x.TaxCodeId
+	}
+	return ""
+}
+
+func (x *PaymentTaxInput) GetRate() float64 {
+	if x != nil && x.Rate != nil {
+		return *x.Rate
+	}
+	return 0
+}
+
+func (x *PaymentTaxInput) GetAmount() float64 {
+	if x != nil && x.Amount != nil {
+		return *x.Amount
+	}
+	return 0
+}
+
+func (x *PaymentTaxInput) GetInclusive() bool {
+	if x != nil && x.Inclusive != nil {
+		return *x.Inclusive
+	}
+	return false
+}
+
+func (x *PaymentTaxInput) GetInvoiceNumber() string {
+	if x != nil {
+		return x.InvoiceNumber
+	}
+	return ""
+}
+
+type CreatePaymentRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Amount float64                `protobuf:"fixed64,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Fee    float64                `protobuf:"fixed64,2,opt,name=fee,proto3" json:"fee,omitempty"`
+	// income, expense or transfer, default: expense
+	Direction string `protobuf:"bytes,3,opt,name=direction,proto3" json:"direction,omitempty"`
+	// Set by categorization rules when empty
+	CategoryId      string `protobuf:"bytes,4,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	PaymentMethodId string `protobuf:"bytes,5,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"`
+	// Defaults to the account linked to the payment method
+	AccountId       string                 `protobuf:"bytes,6,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Description     string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	Payee           string                 `protobuf:"bytes,8,opt,name=payee,proto3" json:"payee,omitempty"`
+	Tags            []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	TransactionTime *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=transaction_time,json=transactionTime,proto3" json:"transaction_time,omitempty"`
+	// No tax when not set
+	Tax *PaymentTaxInput `protobuf:"bytes,11,opt,name=tax,proto3" json:"tax,omitempty"`
+	// Create even if the payment looks like a duplicate
+	Force         bool `protobuf:"varint,12,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePaymentRequest) Reset() {
+	*x = CreatePaymentRequest{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePaymentRequest) ProtoMessage() {}
+
+func (x *CreatePaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePaymentRequest.ProtoReflect.Descriptor instead.
+func (*CreatePaymentRequest) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreatePaymentRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *CreatePaymentRequest) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *CreatePaymentRequest) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *CreatePaymentRequest) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+func (x *CreatePaymentRequest) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *CreatePaymentRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *CreatePaymentRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreatePaymentRequest) GetPayee() string {
+	if x != nil {
+		return x.Payee
+	}
+	return ""
+}
+
+func (x *CreatePaymentRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *CreatePaymentRequest) GetTransactionTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TransactionTime
+	}
+	return nil
+}
+
+func (x *CreatePaymentRequest) GetTax() *PaymentTaxInput {
+	if x != nil {
+		return x.Tax
+	}
+	return nil
+}
+
+func (x *CreatePaymentRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type CreatePaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePaymentResponse) Reset() {
+	*x = CreatePaymentResponse{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePaymentResponse) ProtoMessage() {}
+
+func (x *CreatePaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePaymentResponse.ProtoReflect.Descriptor instead.
+func (*CreatePaymentResponse) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreatePaymentResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+type GetPaymentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPaymentRequest) Reset() {
+	*x = GetPaymentRequest{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentRequest) ProtoMessage() {}
+
+func (x *GetPaymentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentRequest.ProtoReflect.Descriptor instead.
+func (*GetPaymentRequest) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetPaymentRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetPaymentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPaymentResponse) Reset() {
+	*x = GetPaymentResponse{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPaymentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPaymentResponse) ProtoMessage() {}
+
+func (x *GetPaymentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPaymentResponse.ProtoReflect.Descriptor instead.
+func (*GetPaymentResponse) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetPaymentResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+type PaymentFilter struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Status    string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Direction string                 `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"`
+	// Searched in the description
+	Search    string                 `protobuf:"bytes,3,opt,name=search,proto3" json:"search,omitempty"`
+	MinAmount *float64               `protobuf:"fixed64,4,opt,name=min_amount,json=minAmount,proto3,oneof" json:"min_amount,omitempty"`
+	MaxAmount *float64               `protobuf:"fixed64,5,opt,name=max_amount,json=maxAmount,proto3,oneof" json:"max_amount,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// Inclusive
+	EndTime         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	CategoryId      string                 `protobuf:"bytes,8,opt,name=category_id,json=categoryId,proto3" json:"category_id,omitempty"`
+	PaymentMethodId string                 `protobuf:"bytes,9,opt,name=payment_method_id,json=paymentMethodId,proto3" json:"payment_method_id,omitempty"`
+	Reconciled      *bool                  `protobuf:"varint,10,opt,name=reconciled,proto3,oneof" json:"reconciled,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PaymentFilter) Reset() {
+	*x = PaymentFilter{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentFilter) ProtoMessage() {}
+
+func (x *PaymentFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentFilter.ProtoReflect.Descriptor instead.
+func (*PaymentFilter) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PaymentFilter) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *PaymentFilter) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *PaymentFilter) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *PaymentFilter) GetMinAmount() float64 {
+	if x != nil && x.MinAmount != nil {
+		return *x.MinAmount
+	}
+	return 0
+}
+
+func (x *PaymentFilter) GetMaxAmount() float64 {
+	if x != nil && x.MaxAmount != nil {
+		return *x.MaxAmount
+	}
+	return 0
+}
+
+func (x *PaymentFilter) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *PaymentFilter) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *PaymentFilter) GetCategoryId() string {
+	if x != nil {
+		return x.CategoryId
+	}
+	return ""
+}
+
+func (x *PaymentFilter) GetPaymentMethodId() string {
+	if x != nil {
+		return x.PaymentMethodId
+	}
+	return ""
+}
+
+func (x *PaymentFilter) GetReconciled() bool {
+	if x != nil && x.Reconciled != nil {
+		return *x.Reconciled
+	}
+	return false
+}
+
+type ListPaymentsRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Filter *PaymentFilter         `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Default: 1
+	Page int32 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	// Default: 10, at most 100
+	PageSize      int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPaymentsRequest) Reset() {
+	*x = ListPaymentsRequest{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentsRequest) ProtoMessage() {}
+
+func (x *ListPaymentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentsRequest.ProtoReflect.Descriptor instead.
+func (*ListPaymentsRequest) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListPaymentsRequest) GetFilter() *PaymentFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListPaymentsRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPaymentsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListPaymentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payments      []*Payment             `protobuf:"bytes,1,rep,name=payments,proto3" json:"payments,omitempty"`
+	Total         int64                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPaymentsResponse) Reset() {
+	*x = ListPaymentsResponse{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentsResponse) ProtoMessage() {}
+
+func (x *ListPaymentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentsResponse.ProtoReflect.Descriptor instead.
+func (*ListPaymentsResponse) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListPaymentsResponse) GetPayments() []*Payment {
+	if x != nil {
+		return x.Payments
+	}
+	return nil
+}
+
+func (x *ListPaymentsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListPaymentsResponse) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListPaymentsResponse) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ExportPaymentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *PaymentFilter         `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportPaymentsRequest) Reset() {
+	*x = ExportPaymentsRequest{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportPaymentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportPaymentsRequest) ProtoMessage() {}
+
+func (x *ExportPaymentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportPaymentsRequest.ProtoReflect.Descriptor instead.
+func (*ExportPaymentsRequest) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExportPaymentsRequest) GetFilter() *PaymentFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type ExportPaymentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Payment       *Payment               `protobuf:"bytes,1,opt,name=payment,proto3" json:"payment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportPaymentsResponse) Reset() {
+	*x = ExportPaymentsResponse{}
+	mi := &file_ainopay_v1_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportPaymentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportPaymentsResponse) ProtoMessage() {}
+
+func (x *ExportPaymentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportPaymentsResponse.ProtoReflect.Descriptor instead.
+func (*ExportPaymentsResponse) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ExportPaymentsResponse) GetPayment() *Payment {
+	if x != nil {
+		return x.Payment
+	}
+	return nil
+}
+
+var File_ainopay_v1_payment_proto protoreflect.FileDescriptor
+
+const file_ainopay_v1_payment_proto_rawDesc = "" +
+	"\n" +
+	"\x18ainopay/v1/payment.proto\x12\n" +
+	"ainopay.v1\x1a\x19ainopay/v1/category.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x82\x05\n" +
+	"\aPayment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\x12\x10\n" +
+	"\x03fee\x18\x03 \x01(\x01R\x03fee\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x1c\n" +
+	"\tdirection\x18\x05 \x01(\tR\tdirection\x120\n" +
+	"\bcategory\x18\x06 \x01(\v2\x14.ainopay.v1.CategoryR\bcategory\x12@\n" +
+	"\x0epayment_method\x18\a \x01(\v2\x19.ainopay.v1.PaymentMethodR\rpaymentMethod\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\b \x01(\tR\taccountId\x12 \n" +
+	"\vdescription\x18\t \x01(\tR\vdescription\x12\x14\n" +
+	"\x05payee\x18\n" +
+	" \x01(\tR\x05payee\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12E\n" +
+	"\x10transaction_time\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\x0ftransactionTime\x12(\n" +
+	"\x03tax\x18\r \x01(\v2\x16.ainopay.v1.PaymentTaxR\x03tax\x12\x1e\n" +
+	"\n" +
+	"reconciled\x18\x0e \x01(\bR\n" +
+	"reconciled\x12\x1d\n" +
+	"\n" +
+	"over_limit\x18\x0f \x01(\bR\toverLimit\x12;\n" +
+	"\vcreate_time\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vupdate_time\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateTime\"\x9d\x01\n" +
+	"\n" +
+	"PaymentTax\x12\x1e\n" +
+	"\vtax_code_id\x18\x01 \x01(\tR\ttaxCodeId\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x01R\x04rate\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x01R\x06amount\x12\x1c\n" +
+	"\tinclusive\x18\x04 \x01(\bR\tinclusive\x12%\n" +
+	"\x0einvoice_number\x18\x05 \x01(\tR\rinvoiceNumber\"\xe8\x01\n" +
+	"\x0fPaymentTaxInput\x12#\n" +
+	"\vtax_code_id\x18\x01 \x01(\tH\x00R\ttaxCodeId\x88\x01\x01\x12\x17\n" +
+	"\x04rate\x18\x02 \x01(\x01H\x01R\x04rate\x88\x01\x01\x12\x1b\n" +
+	"\x06amount\x18\x03 \x01(\x01H\x02R\x06amount\x88\x01\x01\x12!\n" +
+	"\tinclusive\x18\x04 \x01(\bH\x03R\tinclusive\x88\x01\x01\x12%\n" +
+	"\x0einvoice_number\x18\x05 \x01(\tR\rinvoiceNumberB\x0e\n" +
+	"\f_tax_code_idB\a\n" +
+	"\x05_rateB\t\n" +
+	"\a_amountB\f\n" +
+	"\n" +
+	"_inclusive\"\xa2\x03\n" +
+	"\x14CreatePaymentRequest\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x01R\x06amount\x12\x10\n" +
+	"\x03fee\x18\x02 \x01(\x01R\x03fee\x12\x1c\n" +
+	"\tdirection\x18\x03 \x01(\tR\tdirection\x12\x1f\n" +
+	"\vcategory_id\x18\x04 \x01(\tR\n" +
+	"categoryId\x12*\n" +
+	"\x11payment_method_id\x18\x05 \x01(\tR\x0fpaymentMethodId\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x06 \x01(\tR\taccountId\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12\x14\n" +
+	"\x05payee\x18\b \x01(\tR\x05payee\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12E\n" +
+	"\x10transaction_time\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0ftransactionTime\x12-\n" +
+	"\x03tax\x18\v \x01(\v2\x1b.ainopay.v1.PaymentTaxInputR\x03tax\x12\x14\n" +
+	"\x05force\x18\f \x01(\bR\x05force\"F\n" +
+	"\x15CreatePaymentResponse\x12-\n" +
+	"\apayment\x18\x01 \x01(\v2\x13.ainopay.v1.PaymentR\apayment\"#\n" +
+	"\x11GetPaymentRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"C\n" +
+	"\x12GetPaymentResponse\x12-\n" +
+	"\apayment\x18\x01 \x01(\v2\x13.ainopay.v1.PaymentR\apayment\"\xb6\x03\n" +
+	"\rPaymentFilter\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12\x16\n" +
+	"\x06search\x18\x03 \x01(\tR\x06search\x12\"\n" +
+	"\n" +
+	"min_amount\x18\x04 \x01(\x01H\x00R\tminAmount\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"max_amount\x18\x05 \x01(\x01H\x01R\tmaxAmount\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1f\n" +
+	"\vcategory_id\x18\b \x01(\tR\n" +
+	"categoryId\x12*\n" +
+	"\x11payment_method_id\x18\t \x01(\tR\x0fpaymentMethodId\x12#\n" +
+	"\n" +
+	"reconciled\x18\n" +
+	" \x01(\bH\x02R\n" +
+	"reconciled\x88\x01\x01B\r\n" +
+	"\v_min_amountB\r\n" +
+	"\v_max_amountB\r\n" +
+	"\v_reconciled\"y\n" +
+	"\x13ListPaymentsRequest\x121\n" +
+	"\x06filter\x18\x01 \x01(\v2\x19.ainopay.v1.PaymentFilterR\x06filter\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"\x8e\x01\n" +
+	"\x14ListPaymentsResponse\x12/\n" +
+	"\bpayments\x18\x01 \x03(\v2\x13.ainopay.v1.PaymentR\bpayments\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x03R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"J\n" +
+	"\x15ExportPaymentsRequest\x121\n" +
+	"\x06filter\x18\x01 \x01(\v2\x19.ainopay.v1.PaymentFilterR\x06filter\"G\n" +
+	"\x16ExportPaymentsResponse\x12-\n" +
+	"\apayment\x18\x01 \x01(\v2\x13.ainopay.v1.PaymentR\apayment2\xe1\x02\n" +
+	"\x0ePaymentService\x12T\n" +
+	"\rCreatePayment\x12 .ainopay.v1.CreatePaymentRequest\x1a!.ainopay.v1.CreatePaymentResponse\x12K\n" +
+	"\n" +
+	"GetPayment\x12\x1d.ainopay.v1.GetPaymentRequest\x1a\x1e.ainopay.v1.GetPaymentResponse\x12Q\n" +
+	"\fListPayments\x12\x1f.ainopay.v1.ListPaymentsRequest\x1a .ainopay.v1.ListPaymentsResponse\x12Y\n" +
+	"\x0eExportPayments\x12!.ainopay.v1.ExportPaymentsRequest\x1a\".ainopay.v1.ExportPaymentsResponse0\x01B1Z/ainopay-server/internal/rpc/ainopayv1;ainopayv1b\x06proto3"
+
+var (
+	file_ainopay_v1_payment_proto_rawDescOnce sync.Once
+	file_ainopay_v1_payment_proto_rawDescData []byte
+)
+
+func file_ainopay_v1_payment_proto_rawDescGZIP() []byte {
+	file_ainopay_v1_payment_proto_rawDescOnce.Do(func() {
+		file_ainopay_v1_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ainopay_v1_payment_proto_rawDesc), len(file_ainopay_v1_payment_proto_rawDesc)))
+	})
+	return file_ainopay_v1_payment_proto_rawDescData
+}
+
+var file_ainopay_v1_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_ainopay_v1_payment_proto_goTypes = []any{
+	(*Payment)(nil),                // 0: ainopay.v1.Payment
+	(*PaymentTax)(nil),             // 1: ainopay.v1.PaymentTax
+	(*PaymentTaxInput)(nil),        // 2: ainopay.v1.PaymentTaxInput
+	(*CreatePaymentRequest)(nil),   // 3: ainopay.v1.CreatePaymentRequest
+	(*CreatePaymentResponse)(nil),  // 4: ainopay.v1.CreatePaymentResponse
+	(*GetPaymentRequest)(nil),      // 5: ainopay.v1.GetPaymentRequest
+	(*GetPaymentResponse)(nil),     // 6: ainopay.v1.GetPaymentResponse
+	(*PaymentFilter)(nil),          // 7: ainopay.v1.PaymentFilter
+	(*ListPaymentsRequest)(nil),    // 8: ainopay.v1.ListPaymentsRequest
+	(*ListPaymentsResponse)(nil),   // 9: ainopay.v1.ListPaymentsResponse
+	(*ExportPaymentsRequest)(nil),  // 10: ainopay.v1.ExportPaymentsRequest
+	(*ExportPaymentsResponse)(nil), // 11: ainopay.v1.ExportPaymentsResponse
+	(*Category)(nil),               // 12: ainopay.v1.Category
+	(*PaymentMethod)(nil),          // 13: ainopay.v1.PaymentMethod
+	(*timestamppb.Timestamp)(nil),  // 14: google.protobuf.Timestamp
+}
+var file_ainopay_v1_payment_proto_depIdxs = []int32{
+	12, // 0: ainopay.v1.Payment.category:type_name -> ainopay.v1.Category
+	13, // 1: ainopay.v1.Payment.payment_method:type_name -> ainopay.v1.PaymentMethod
+	14, // 2: ainopay.v1.Payment.transaction_time:type_name -> google.protobuf.Timestamp
+	1,  // 3: ainopay.v1.Payment.tax:type_name -> ainopay.v1.PaymentTax
+	14, // 4: ainopay.v1.Payment.create_time:type_name -> google.protobuf.Timestamp
+	14, // 5: ainopay.v1.Payment.update_time:type_name -> google.protobuf.Timestamp
+	14, // 6: ainopay.v1.CreatePaymentRequest.transaction_time:type_name -> google.protobuf.Timestamp
+	2,  // 7: ainopay.v1.CreatePaymentRequest.tax:type_name -> ainopay.v1.PaymentTaxInput
+	0,  // 8: ainopay.v1.CreatePaymentResponse.payment:type_name -> ainopay.v1.Payment
+	0,  // 9: ainopay.v1.GetPaymentResponse.payment:type_name -> ainopay.v1.Payment
+	14, // 10: ainopay.v1.PaymentFilter.start_time:type_name -> google.protobuf.Timestamp
+	14, // 11: ainopay.v1.PaymentFilter.end_time:type_name -> google.protobuf.Timestamp
+	7,  // 12: ainopay.v1.ListPaymentsRequest.filter:type_name -> ainopay.v1.PaymentFilter
+	0,  // 13: ainopay.v1.ListPaymentsResponse.payments:type_name -> ainopay.v1.Payment
+	7,  // 14: ainopay.v1.ExportPaymentsRequest.filter:type_name -> ainopay.v1.PaymentFilter
+	0,  // 15: ainopay.v1.ExportPaymentsResponse.payment:type_name -> ainopay.v1.Payment
+	3,  // 16: ainopay.v1.PaymentService.CreatePayment:input_type -> ainopay.v1.CreatePaymentRequest
+	5,  // 17: ainopay.v1.PaymentService.GetPayment:input_type -> ainopay.v1.GetPaymentRequest
+	8,  // 18: ainopay.v1.PaymentService.ListPayments:input_type -> ainopay.v1.ListPaymentsRequest
+	10, // 19: ainopay.v1.PaymentService.ExportPayments:input_type -> ainopay.v1.ExportPaymentsRequest
+	4,  // 20: ainopay.v1.PaymentService.CreatePayment:output_type -> ainopay.v1.CreatePaymentResponse
+	6,  // 21: ainopay.v1.PaymentService.GetPayment:output_type -> ainopay.v1.GetPaymentResponse
+	9,  // 22: ainopay.v1.PaymentService.ListPayments:output_type -> ainopay.v1.ListPaymentsResponse
+	11, // 23: ainopay.v1.PaymentService.ExportPayments:output_type -> ainopay.v1.ExportPaymentsResponse
+	20, // [20:24] is the sub-list for method output_type
+	16, // [16:20] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_ainopay_v1_payment_proto_init() }
+func file_ainopay_v1_payment_proto_init() {
+	if File_ainopay_v1_payment_proto != nil {
+		return
+	}
+	file_ainopay_v1_category_proto_init()
+	file_ainopay_v1_payment_proto_msgTypes[2].OneofWrappers = []any{}
+	file_ainopay_v1_payment_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ainopay_v1_payment_proto_rawDesc), len(file_ainopay_v1_payment_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ainopay_v1_payment_proto_goTypes,
+		DependencyIndexes: file_ainopay_v1_payment_proto_depIdxs,
+		MessageInfos:      file_ainopay_v1_payment_proto_msgTypes,
+	}.Build()
+	File_ainopay_v1_payment_proto = out.File
+	file_ainopay_v1_payment_proto_goTypes = nil
+	file_ainopay_v1_payment_proto_depIdxs = nil
+}
@@ -0,0 +1,649 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: ainopay/v1/stats.proto
+
+package ainopayv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetDashboardStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// this_month, last_month, this_quarter, last_quarter, ytd or last_year
+	Preset string `protobuf:"bytes,1,opt,name=preset,proto3" json:"preset,omitempty"`
+	// YYYY-MM-DD
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	// YYYY-MM-DD, inclusive, default: today when from is set
+	To string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// Compare with the previous period
+	Compare bool `protobuf:"varint,4,opt,name=compare,proto3" json:"compare,omitempty"`
+	// IANA timezone dates are interpreted in, default: the user's preference
+	Timezone      string `protobuf:"bytes,5,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDashboardStatsRequest) Reset() {
+	*x = GetDashboardStatsRequest{}
+	mi := &file_ainopay_v1_stats_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDashboardStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDashboardStatsRequest) ProtoMessage() {}
+
+func (x *GetDashboardStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_stats_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDashboardStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetDashboardStatsRequest) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_stats_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetDashboardStatsRequest) GetPreset() string {
+	if x != nil {
+		return x.Preset
+	}
+	return ""
+}
+
+func (x *GetDashboardStatsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *GetDashboardStatsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *GetDashboardStatsRequest) GetCompare() bool {
+	if x != nil {
+		return x.Compare
+	}
+	return false
+}
+
+func (x *GetDashboardStatsRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+type PaymentStatistics struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalPayments  int64                  `protobuf:"varint,1,opt,name=total_payments,json=totalPayments,proto3" json:"total_payments,omitempty"`
+	CompletedCount int64                  `protobuf:"varint,2,opt,name=completed_count,json=completedCount,proto3" json:"completed_count,omitempty"`
+	PendingCount   int64                  `protobuf:"varint,3,opt,name=pending_count,json=pendingCount,proto3" json:"pending_count,omitempty"`
+	TotalIncome    float64                `protobuf:"fixed64,4,opt,name=total_income,json=totalIncome,proto3" json:"total_income,omitempty"`
+	TotalExpense   float64                `protobuf:"fixed64,5,opt,name=total_expense,json=totalExpense,proto3" json:"total_expense,omitempty"`
+	TotalFees      float64                `protobuf:"fixed64,6,opt,name=total_fees,json=totalFees,proto3" json:"total_fees,omitempty"`
+	// Income minus expenses and fees
+	Net           float64 `protobuf:"fixed64,7,opt,name=net,proto3" json:"net,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentStatistics) Reset() {
+	*x = PaymentStatistics{}
+	mi := &file_ainopay_v1_stats_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentStatistics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentStatistics) ProtoMessage() {}
+
+func (x *PaymentStatistics) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_stats_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentStatistics.ProtoReflect.Descriptor instead.
+func (*PaymentStatistics) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_stats_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PaymentStatistics) GetTotalPayments() int64 {
+	if x != nil {
+		return x.TotalPayments
+	}
+	return 0
+}
+
+func (x *PaymentStatistics) GetCompletedCount() int64 {
+	if x != nil {
+		return x.CompletedCount
+	}
+	return 0
+}
+
+func (x *PaymentStatistics) GetPendingCount() int64 {
+	if x != nil {
+		return x.PendingCount
+	}
+	return 0
+}
+
+func (x *PaymentStatistics) GetTotalIncome() float64 {
+	if x != nil {
+		return x.TotalIncome
+	}
+	return 0
+}
+
+func (x *PaymentStatistics) GetTotalExpense() float64 {
+	if x != nil {
+		return x.TotalExpense
+	}
+	return 0
+}
+
+func (x *PaymentStatistics) GetTotalFees() float64 {
+	if x != nil {
+		return x.TotalFees
+	}
+	return 0
+}
+
+func (x *PaymentStatistics) GetNet() float64 {
+	if x != nil {
+		return x.Net
+	}
+	return 0
+}
+
+type StatsPeriod struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Preset string                 `protobuf:"bytes,1,opt,name=preset,proto3" json:"preset,omitempty"`
+	// YYYY-MM-DD
+	From string `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	// YYYY-MM-DD, inclusive
+	To            string `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsPeriod) Reset() {
+	*x = StatsPeriod{}
+	mi := &file_ainopay_v1_stats_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsPeriod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsPeriod) ProtoMessage() {}
+
+func (x *StatsPeriod) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_stats_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsPeriod.ProtoReflect.Descriptor instead.
+func (*StatsPeriod) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_stats_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StatsPeriod) GetPreset() string {
+	if x != nil {
+		return x.Preset
+	}
+	return ""
+}
+
+func (x *StatsPeriod) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *StatsPeriod) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type StatChange struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Absolute float64                `protobuf:"fixed64,1,opt,name=absolute,proto3" json:"absolute,omitempty"`
+	// Not set when the previous value is zero
+	Percent       *float64 `protobuf:"fixed64,2,opt,name=percent,proto3,oneof" json:"percent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatChange) Reset() {
+	*x = StatChange{}
+	mi := &file_ainopay_v1_stats_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatChange) ProtoMessage() {}
+
+func (x *StatChange) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_stats_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatChange.ProtoReflect.Descriptor instead.
+func (*StatChange) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_stats_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StatChange) GetAbsolute() float64 {
+	if x != nil {
+		return x.Absolute
+	}
+	return 0
+}
+
+func (x *StatChange) GetPercent() float64 {
+	if x != nil && x.Percent != nil {
+		return *x.Percent
+	}
+	return 0
+}
+
+type StatsChanges struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TotalPayments  *StatChange            `protobuf:"bytes,1,opt,name=total_payments,json=totalPayments,proto3" json:"total_payments,omitempty"`
+	CompletedCount *StatChange            `protobuf:"bytes,2,opt,name=completed_count,json=completedCount,proto3" json:"completed_count,omitempty"`
+	PendingCount   *StatChange            `protobuf:"bytes,3,opt,name=pending_count,json=pendingCount,proto3" json:"pending_count,omitempty"`
+	TotalIncome    *StatChange            `protobuf:"bytes,4,opt,name=total_income,json=totalIncome,proto3" json:"total_income,omitempty"`
+	TotalExpense   *StatChange            `protobuf:"bytes,5,opt,name=total_expense,json=totalExpense,proto3" json:"total_expense,omitempty"`
+	TotalFees      *StatChange            `protobuf:"bytes,6,opt,name=total_fees,json=totalFees,proto3" json:"total_fees,omitempty"`
+	Net            *StatChange            `protobuf:"bytes,7,opt,name=net,proto3" json:"net,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StatsChanges) Reset() {
+	*x = StatsChanges{}
+	mi := &file_ainopay_v1_stats_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsChanges) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsChanges) ProtoMessage() {}
+
+func (x *StatsChanges) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_stats_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsChanges.ProtoReflect.Descriptor instead.
+func (*StatsChanges) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_stats_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StatsChanges) GetTotalPayments() *StatChange {
+	if x != nil {
+		return x.TotalPayments
+	}
+	return nil
+}
+
+func (x *StatsChanges) GetCompletedCount() *StatChange {
+	if x != nil {
+		return x.CompletedCount
+	}
+	return nil
+}
+
+func (x *StatsChanges) GetPendingCount() *StatChange {
+	if x != nil {
+		return x.PendingCount
+	}
+	return nil
+}
+
+func (x *StatsChanges) GetTotalIncome() *StatChange {
+	if x != nil {
+		return x.TotalIncome
+	}
+	return nil
+}
+
+func (x *StatsChanges) GetTotalExpense() *StatChange {
+	if x != nil {
+		return x.TotalExpense
+	}
+	return nil
+}
+
+func (x *StatsChanges) GetTotalFees() *StatChange {
+	if x != nil {
+		return x.TotalFees
+	}
+	return nil
+}
+
+func (x *StatsChanges) GetNet() *StatChange {
+	if x != nil {
+		return x.Net
+	}
+	return nil
+}
+
+type StatsComparison struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Period        *StatsPeriod           `protobuf:"bytes,1,opt,name=period,proto3" json:"period,omitempty"`
+	Previous      *PaymentStatistics     `protobuf:"bytes,2,opt,name=previous,proto3" json:"previous,omitempty"`
+	Changes       *StatsChanges          `protobuf:"bytes,3,opt,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsComparison) Reset() {
+	*x = StatsComparison{}
+	mi := &file_ainopay_v1_stats_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsComparison) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsComparison) ProtoMessage() {}
+
+func (x *StatsComparison) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_stats_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsComparison.ProtoReflect.Descriptor instead.
+func (*StatsComparison) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_stats_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StatsComparison) GetPeriod() *StatsPeriod {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *StatsComparison) GetPrevious() *PaymentStatistics {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *StatsComparison) GetChanges() *StatsChanges {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type GetDashboardStatsResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Statistics *PaymentStatistics     `protobuf:"bytes,1,opt,name=statistics,proto3" json:"statistics,omitempty"`
+	Period     *StatsPeriod           `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	// Set when comparing
+	Comparison    *StatsComparison `protobuf:"bytes,3,opt,name=comparison,proto3" json:"comparison,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDashboardStatsResponse) Reset() {
+	*x = GetDashboardStatsResponse{}
+	mi := &file_ainopay_v1_stats_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDashboardStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDashboardStatsResponse) ProtoMessage() {}
+
+func (x *GetDashboardStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ainopay_v1_stats_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDashboardStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetDashboardStatsResponse) Descriptor() ([]byte, []int) {
+	return file_ainopay_v1_stats_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetDashboardStatsResponse) GetStatistics() *PaymentStatistics {
+	if x != nil {
+		return x.Statistics
+	}
+	return nil
+}
+
+func (x *GetDashboardStatsResponse) GetPeriod() *StatsPeriod {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *GetDashboardStatsResponse) GetComparison() *StatsComparison {
+	if x != nil {
+		return x.Comparison
+	}
+	return nil
+}
+
+var File_ainopay_v1_stats_proto protoreflect.FileDescriptor
+
+const file_ainopay_v1_stats_proto_rawDesc = "" +
+	"\n" +
+	"\x16ainopay/v1/stats.proto\x12\n" +
+	"ainopay.v1\"\x8c\x01\n" +
+	"\x18GetDashboardStatsRequest\x12\x16\n" +
+	"\x06preset\x18\x01 \x01(\tR\x06preset\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x18\n" +
+	"\acompare\x18\x04 \x01(\bR\acompare\x12\x1a\n" +
+	"\btimezone\x18\x05 \x01(\tR\btimezone\"\x81\x02\n" +
+	"\x11PaymentStatistics\x12%\n" +
+	"\x0etotal_payments\x18\x01 \x01(\x03R\rtotalPayments\x12'\n" +
+	"\x0fcompleted_count\x18\x02 \x01(\x03R\x0ecompletedCount\x12#\n" +
+	"\rpending_count\x18\x03 \x01(\x03R\fpendingCount\x12!\n" +
+	"\ftotal_income\x18\x04 \x01(\x01R\vtotalIncome\x12#\n" +
+	"\rtotal_expense\x18\x05 \x01(\x01R\ftotalExpense\x12\x1d\n" +
+	"\n" +
+	"total_fees\x18\x06 \x01(\x01R\ttotalFees\x12\x10\n" +
+	"\x03net\x18\a \x01(\x01R\x03net\"I\n" +
+	"\vStatsPeriod\x12\x16\n" +
+	"\x06preset\x18\x01 \x01(\tR\x06preset\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\"S\n" +
+	"\n" +
+	"StatChange\x12\x1a\n" +
+	"\babsolute\x18\x01 \x01(\x01R\babsolute\x12\x1d\n" +
+	"\apercent\x18\x02 \x01(\x01H\x00R\apercent\x88\x01\x01B\n" +
+	"\n" +
+	"\b_percent\"\xa4\x03\n" +
+	"\fStatsChanges\x12=\n" +
+	"\x0etotal_payments\x18\x01 \x01(\v2\x16.ainopay.v1.StatChangeR\rtotalPayments\x12?\n" +
+	"\x0fcompleted_count\x18\x02 \x01(\v2\x16.ainopay.v1.StatChangeR\x0ecompletedCount\x12;\n" +
+	"\rpending_count\x18\x03 \x01(\v2\x16.ainopay.v1.StatChangeR\fpendingCount\x129\n" +
+	"\ftotal_income\x18\x04 \x01(\v2\x16.ainopay.v1.StatChangeR\vtotalIncome\x12;\n" +
+	"\rtotal_expense\x18\x05 \x01(\v2\x16.ainopay.v1.StatChangeR\ftotalExpense\x125\n" +
+	"\n" +
+	"total_fees\x18\x06 \x01(\v2\x16.ainopay.v1.StatChangeR\ttotalFees\x12(\n" +
+	"\x03net\x18\a \x01(\v2\x16.ainopay.v1.StatChangeR\x03net\"\xb1\x01\n" +
+	"\x0fStatsComparison\x12/\n" +
+	"\x06period\x18\x01 \x01(\v2\x17.ainopay.v1.StatsPeriodR\x06period\x129\n" +
+	"\bprevious\x18\x02 \x01(\v2\x1d.ainopay.v1.PaymentStatisticsR\bprevious\x122\n" +
+	"\achanges\x18\x03 \x01(\v2\x18.ainopay.v1.StatsChangesR\achanges\"\xc8\x01\n" +
+	"\x19GetDashboardStatsResponse\x12=\n" +
+	"\n" +
+	"statistics\x18\x01 \x01(\v2\x1d.ainopay.v1.PaymentStatisticsR\n" +
+	"statistics\x12/\n" +
+	"\x06period\x18\x02 \x01(\v2\x17.ainopay.v1.StatsPeriodR\x06period\x12;\n" +
+	"\n" +
+	"comparison\x18\x03 \x01(\v2\x1b.ainopay.v1.StatsComparisonR\n" +
+	"comparison2p\n" +
+	"\fStatsService\x12`\n" +
+	"\x11GetDashboardStats\x12$.ainopay.v1.GetDashboardStatsRequest\x1a%.ainopay.v1.GetDashboardStatsResponseB1Z/ainopay-server/internal/rpc/ainopayv1;ainopayv1b\x06proto3"
+
+var (
+	file_ainopay_v1_stats_proto_rawDescOnce sync.Once
+	file_ainopay_v1_stats_proto_rawDescData []byte
+)
+
+func file_ainopay_v1_stats_proto_rawDescGZIP() []byte {
+	file_ainopay_v1_stats_proto_rawDescOnce.Do(func() {
+		file_ainopay_v1_stats_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ainopay_v1_stats_proto_rawDesc), len(file_ainopay_v1_stats_proto_rawDesc)))
+	})
+	return file_ainopay_v1_stats_proto_rawDescData
+}
+
+var file_ainopay_v1_stats_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_ainopay_v1_stats_proto_goTypes = []any{
+	(*GetDashboardStatsRequest)(nil),  // 0: ainopay.v1.GetDashboardStatsRequest
+	(*PaymentStatistics)(nil),         // 1: ainopay.v1.PaymentStatistics
+	(*StatsPeriod)(nil),               // 2: ainopay.v1.StatsPeriod
+	(*StatChange)(nil),                // 3: ainopay.v1.StatChange
+	(*StatsChanges)(nil),              // 4: ainopay.v1.StatsChanges
+	(*StatsComparison)(nil),           // 5: ainopay.v1.StatsComparison
+	(*GetDashboardStatsResponse)(nil), // 6: ainopay.v1.GetDashboardStatsResponse
+}
+var file_ainopay_v1_stats_proto_depIdxs = []int32{
+	3,  // 0: ainopay.v1.StatsChanges.total_payments:type_name -> ainopay.v1.StatChange
+	3,  // 1: ainopay.v1.StatsChanges.completed_count:type_name -> ainopay.v1.StatChange
+	3,  // 2: ainopay.v1.StatsChanges.pending_count:type_name -> ainopay.v1.StatChange
+	3,  // 3: ainopay.v1.StatsChanges.total_income:type_name -> ainopay.v1.StatChange
+	3,  // 4: ainopay.v1.StatsChanges.total_expense:type_name -> ainopay.v1.StatChange
+	3,  // 5: ainopay.v1.StatsChanges.total_fees:type_name -> ainopay.v1.StatChange
+	3,  // 6: ainopay.v1.StatsChanges.net:type_name -> ainopay.v1.StatChange
+	2,  // 7: ainopay.v1.StatsComparison.period:type_name -> ainopay.v1.StatsPeriod
+	1,  // 8: ainopay.v1.StatsComparison.previous:type_name -> ainopay.v1.PaymentStatistics
+	4,  // 9: ainopay.v1.StatsComparison.changes:type_name -> ainopay.v1.StatsChanges
+	1,  // 10: ainopay.v1.GetDashboardStatsResponse.statistics:type_name -> ainopay.v1.PaymentStatistics
+	2,  // 11: ainopay.v1.GetDashboardStatsResponse.period:type_name -> ainopay.v1.StatsPeriod
+	5,  // 12: ainopay.v1.GetDashboardStatsResponse.comparison:type_name -> ainopay.v1.StatsComparison
+	0,  // 13: ainopay.v1.StatsService.GetDashboardStats:input_type -> ainopay.v1.GetDashboardStatsRequest
+	6,  // 14: ainopay.v1.StatsService.GetDashboardStats:output_type -> ainopay.v1.GetDashboardStatsResponse
+	14, // [14:15] is the sub-list for method output_type
+	13, // [13:14] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_ainopay_v1_stats_proto_init() }
+func file_ainopay_v1_stats_proto_init() {
+	if File_ainopay_v1_stats_proto != nil {
+		return
+	}
+	file_ainopay_v1_stats_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ainopay_v1_stats_proto_rawDesc), len(file_ainopay_v1_stats_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ainopay_v1_stats_proto_goTypes,
+		DependencyIndexes: file_ainopay_v1_stats_proto_depIdxs,
+		MessageInfos:      file_ainopay_v1_stats_proto_msgTypes,
+	}.Build()
+	File_ainopay_v1_stats_proto = out.File
+	file_ainopay_v1_stats_proto_goTypes = nil
+	file_ainopay_v1_stats_proto_depIdxs = nil
+}
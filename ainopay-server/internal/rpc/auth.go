@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"ainopay-server/internal/config"
+	"ainopay-server/internal/utils"
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys calls authenticate with
+const (
+	authorizationKey = "authorization" // Bearer <JWT> of the user
+	apiKeyKey        = "x-api-key"     // key of an internal client
+	userIDKey        = "x-user-id"     // user an internal client acts for
+)
+
+// authenticator resolves the user a call acts for: the user of a JWT, as
+// the REST API does, or the user named by an internal client with an API key
+type authenticator struct {
+	jwtSecret string
+	apiKeys   [][]byte
+}
+
+func newAuthenticator(cfg *config.Config) *authenticator {
+	a := &authenticator{jwtSecret: cfg.JWT.Secret}
+	for _, key := range strings.Split(cfg.GRPC.APIKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			a.apiKeys = append(a.apiKeys, []byte(key))
+		}
+	}
+	return a
+}
+
+type userContextKey struct{}
+
+// userID returns the ID of the user an authenticated call acts for
+func userID(ctx context.Context) uuid.UUID {
+	return ctx.Value(userContextKey{}).(uuid.UUID)
+}
+
+// authenticate returns ctx with the ID of the user the call acts for
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(authorizationKey); len(values) > 0 {
+		token, ok := strings.CutPrefix(values[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization format")
+		}
+		claims, err := utils.ValidateToken(token, a.jwtSecret)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		return context.WithValue(ctx, userContextKey{}, claims.UserID), nil
+	}
+
+	if values := md.Get(apiKeyKey); len(values) > 0 {
+		if !a.validAPIKey(values[0]) {
+			return nil, status.Error(codes.Unauthenticated, "invalid API key")
+		}
+		ids := md.Get(userIDKey)
+		if len(ids) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "x-user-id is required with an API key")
+		}
+		id, err := uuid.Parse(ids[0])
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid x-user-id")
+		}
+		return context.WithValue(ctx, userContextKey{}, id), nil
+	}
+
+	return nil, status.Error(codes.Unauthenticated, "authorization or x-api-key metadata required")
+}
+
+// validAPIKey compares the key with every configured key in constant time
+func (a *authenticator) validAPIKey(key string) bool {
+	valid := false
+	for _, apiKey := range a.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(key), apiKey) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// authenticatedStream is a server stream whose context carries the user
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,39 @@
+package rpc
+
+import (
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/rpc/ainopayv1"
+	"context"
+)
+
+type categoryServer struct {
+	ainopayv1.UnimplementedCategoryServiceServer
+	categoryRepo      *repositories.CategoryRepository
+	paymentMethodRepo *repositories.PaymentMethodRepository
+}
+
+func (s *categoryServer) ListCategories(ctx context.Context, req *ainopayv1.ListCategoriesRequest) (*ainopayv1.ListCategoriesResponse, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &ainopayv1.ListCategoriesResponse{Categories: make([]*ainopayv1.Category, len(categories))}
+	for i := range categories {
+		response.Categories[i] = categoryMessage(&categories[i])
+	}
+	return response, nil
+}
+
+func (s *categoryServer) ListPaymentMethods(ctx context.Context, req *ainopayv1.ListPaymentMethodsRequest) (*ainopayv1.ListPaymentMethodsResponse, error) {
+	methods, err := s.paymentMethodRepo.FindAll()
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &ainopayv1.ListPaymentMethodsResponse{PaymentMethods: make([]*ainopayv1.PaymentMethod, len(methods))}
+	for i := range methods {
+		response.PaymentMethods[i] = paymentMethodMessage(&methods[i])
+	}
+	return response, nil
+}
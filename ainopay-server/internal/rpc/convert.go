@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/rpc/ainopayv1"
+	"ainopay-server/internal/services"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// paymentMessage converts a payment loaded with its category and payment method
+func paymentMessage(p *models.Payment) *ainopayv1.Payment {
+	payment := &ainopayv1.Payment{
+		Id:              p.ID.String(),
+		Amount:          p.Amount,
+		Fee:             p.Fee,
+		Status:          p.Status,
+		Direction:       p.Direction,
+		Category:        categoryMessage(&p.Category),
+		PaymentMethod:   paymentMethodMessage(&p.PaymentMethod),
+		Description:     p.Description,
+		Payee:           p.Payee,
+		Tags:            p.Tags,
+		TransactionTime: timestamppb.New(p.TransactionDate),
+		Reconciled:      p.Reconciled,
+		OverLimit:       p.OverLimit,
+		CreateTime:      timestamppb.New(p.CreatedAt),
+		UpdateTime:      timestamppb.New(p.UpdatedAt),
+	}
+	if p.AccountID != nil {
+		payment.AccountId = p.AccountID.String()
+	}
+	if p.TaxCodeID != nil || p.TaxAmount != 0 {
+		payment.Tax = &ainopayv1.PaymentTax{
+			Rate:          p.TaxRate,
+			Amount:        p.TaxAmount,
+			Inclusive:     p.TaxInclusive,
+			InvoiceNumber: p.TaxInvoiceNumber,
+		}
+		if p.TaxCodeID != nil {
+			payment.Tax.TaxCodeId = p.TaxCodeID.String()
+		}
+	}
+	return payment
+}
+
+func categoryMessage(c *models.Category) *ainopayv1.Category {
+	return &ainopayv1.Category{
+		Id:          c.ID.String(),
+		Name:        c.Name,
+		Description: c.Description,
+	}
+}
+
+func paymentMethodMessage(m *models.PaymentMethod) *ainopayv1.PaymentMethod {
+	return &ainopayv1.PaymentMethod{
+		Id:       m.ID.String(),
+		Name:     m.Name,
+		Code:     m.Code,
+		IsActive: m.IsActive,
+	}
+}
+
+func statisticsMessage(s *models.PaymentStatistics) *ainopayv1.PaymentStatistics {
+	return &ainopayv1.PaymentStatistics{
+		TotalPayments:  s.TotalPayments,
+		CompletedCount: s.CompletedCount,
+		PendingCount:   s.PendingCount,
+		TotalIncome:    s.TotalIncome,
+		TotalExpense:   s.TotalExpense,
+		TotalFees:      s.TotalFees,
+		Net:            s.Net,
+	}
+}
+
+func statsPeriodMessage(p *services.StatsPeriod) *ainopayv1.StatsPeriod {
+	return &ainopayv1.StatsPeriod{Preset: p.Preset, From: p.From, To: p.To}
+}
+
+func statChangeMessage(c *services.StatChange) *ainopayv1.StatChange {
+	return &ainopayv1.StatChange{Absolute: c.Absolute, Percent: c.Percent}
+}
+
+func dashboardStatsMessage(stats *services.DashboardStats) *ainopayv1.GetDashboardStatsResponse {
+	response := &ainopayv1.GetDashboardStatsResponse{
+		Statistics: statisticsMessage(&stats.PaymentStatistics),
+		Period:     statsPeriodMessage(&stats.Period),
+	}
+	if c := stats.Comparison; c != nil {
+		response.Comparison = &ainopayv1.StatsComparison{
+			Period:   statsPeriodMessage(&c.Period),
+			Previous: statisticsMessage(&c.Previous),
+			Changes: &ainopayv1.StatsChanges{
+				TotalPayments:  statChangeMessage(&c.Changes.TotalPayments),
+				CompletedCount: statChangeMessage(&c.Changes.CompletedCount),
+				PendingCount:   statChangeMessage(&c.Changes.PendingCount),
+				TotalIncome:    statChangeMessage(&c.Changes.TotalIncome),
+				TotalExpense:   statChangeMessage(&c.Changes.TotalExpense),
+				TotalFees:      statChangeMessage(&c.Changes.TotalFees),
+				Net:            statChangeMessage(&c.Changes.Net),
+			},
+		}
+	}
+	return response
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/services"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain identifies the service in ErrorInfo details
+const errorDomain = "ainopay"
+
+// statusError converts service errors to statuses with the codes matching
+// the status codes of the REST API
+func statusError(err error) error {
+	var duplicateErr *services.DuplicatePaymentError
+	if errors.As(err, &duplicateErr) {
+		ids := make([]string, len(duplicateErr.CandidateIDs))
+		for i, id := range duplicateErr.CandidateIDs {
+			ids[i] = id.String()
+		}
+		return withDetails(status.New(codes.AlreadyExists, "possible duplicate payment; set force to create it anyway"), &errdetails.ErrorInfo{
+			Reason:   "DUPLICATE_PAYMENT",
+			Domain:   errorDomain,
+			Metadata: map[string]string{"candidate_ids": strings.Join(ids, ",")},
+		})
+	}
+
+	var limitErr *services.SpendingLimitError
+	if errors.As(err, &limitErr) {
+		failure := &errdetails.PreconditionFailure{}
+		for _, v := range limitErr.Violations {
+			failure.Violations = append(failure.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        "SPENDING_LIMIT",
+				Subject:     v.LimitID.String(),
+				Description: fmt.Sprintf("%s limit of %.2f has %.2f remaining for an amount of %.2f", v.Period, v.Limit, v.Remaining, v.Amount),
+			})
+		}
+		return withDetails(status.New(codes.FailedPrecondition, err.Error()), failure)
+	}
+
+	switch {
+	case errors.Is(err, services.ErrFinancialAccountNotFound), errors.Is(err, services.ErrFinancialAccountInactive),
+		errors.Is(err, services.ErrCategoryRequired), errors.Is(err, services.ErrTaxCodeNotFound),
+		errors.Is(err, services.ErrTaxCodeInactive), errors.Is(err, services.ErrInvalidTax),
+		errors.Is(err, services.ErrInvalidStatsPeriod):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, services.ErrPaymentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, services.ErrPaymentInReview), errors.Is(err, services.ErrPaymentNotInReview),
+		errors.Is(err, services.ErrPaymentCharged):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// validationError reports the fields of a request that failed validation
+func validationError(validationErrors []middleware.ValidationError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, e := range validationErrors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       e.Field,
+			Description: e.Message,
+		})
+	}
+	return withDetails(status.New(codes.InvalidArgument, "validation failed"), badRequest)
+}
+
+// invalidArgument reports a request field that cannot be parsed
+func invalidArgument(message string) error {
+	return status.Error(codes.InvalidArgument, message)
+}
+
+// withDetails attaches details to a status, returning it without them if
+// they cannot be encoded
+func withDetails(st *status.Status, details protoadapt.MessageV1) error {
+	if detailed, err := st.WithDetails(details); err == nil {
+		return detailed.Err()
+	}
+	return st.Err()
+}
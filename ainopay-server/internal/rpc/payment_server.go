@@ -0,0 +1,237 @@
+package rpc
+
+import (
+	"ainopay-server/internal/middleware"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/rpc/ainopayv1"
+	"ainopay-server/internal/services"
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// maxPageSize caps the number of payments of one page
+const maxPageSize = 100
+
+// exportBatchSize is the number of payments ExportPayments reads at a time
+const exportBatchSize = 500
+
+type paymentServer struct {
+	ainopayv1.UnimplementedPaymentServiceServer
+	paymentService *services.PaymentService
+	paymentRepo    *repositories.PaymentRepository
+}
+
+// createPaymentInput holds the fields of a create request checked with the
+// same rules as the REST API
+type createPaymentInput struct {
+	Amount           float64  `validate:"gt=0"`
+	Fee              float64  `validate:"gte=0"`
+	Direction        string   `validate:"omitempty,oneof=income expense transfer"`
+	Description      string   `validate:"max=500"`
+	Payee            string   `validate:"max=255"`
+	Tags             []string `validate:"max=20,dive,max=50"`
+	TaxRate          *float64 `validate:"omitempty,gte=0,lte=100"`
+	TaxAmount        *float64 `validate:"omitempty,gte=0"`
+	TaxInvoiceNumber string   `validate:"max=50"`
+}
+
+func (s *paymentServer) CreatePayment(ctx context.Context, req *ainopayv1.CreatePaymentRequest) (*ainopayv1.CreatePaymentResponse, error) {
+	tax := req.GetTax()
+	input := createPaymentInput{
+		Amount:           req.GetAmount(),
+		Fee:              req.GetFee(),
+		Direction:        req.GetDirection(),
+		Description:      req.GetDescription(),
+		Payee:            req.GetPayee(),
+		Tags:             req.GetTags(),
+		TaxInvoiceNumber: tax.GetInvoiceNumber(),
+	}
+	if tax != nil {
+		input.TaxRate, input.TaxAmount = tax.Rate, tax.Amount
+	}
+	if validationErrors := middleware.ValidateStruct(&input); len(validationErrors) > 0 {
+		return nil, validationError(validationErrors)
+	}
+	if !req.GetTransactionTime().IsValid() {
+		return nil, invalidArgument("transaction_time is required")
+	}
+
+	serviceReq := &services.CreatePaymentRequest{
+		Amount:          input.Amount,
+		Fee:             input.Fee,
+		Direction:       input.Direction,
+		Description:     input.Description,
+		Payee:           input.Payee,
+		Tags:            input.Tags,
+		TransactionDate: req.GetTransactionTime().AsTime(),
+		Force:           req.GetForce(),
+	}
+
+	var err error
+	if req.GetCategoryId() != "" {
+		if serviceReq.CategoryID, err = uuid.Parse(req.GetCategoryId()); err != nil {
+			return nil, invalidArgument("invalid category_id")
+		}
+	}
+	if serviceReq.PaymentMethodID, err = uuid.Parse(req.GetPaymentMethodId()); err != nil {
+		return nil, invalidArgument("invalid payment_method_id")
+	}
+	if serviceReq.AccountID, err = parseOptionalID(req.GetAccountId()); err != nil {
+		return nil, invalidArgument("invalid account_id")
+	}
+	if tax != nil {
+		taxCodeID, err := parseOptionalID(tax.GetTaxCodeId())
+		if err != nil {
+			return nil, invalidArgument("invalid tax.tax_code_id")
+		}
+		serviceReq.Tax = &services.PaymentTaxRequest{
+			TaxCodeID:     taxCodeID,
+			Rate:          tax.Rate,
+			Amount:        tax.Amount,
+			Inclusive:     tax.Inclusive,
+			InvoiceNumber: tax.GetInvoiceNumber(),
+		}
+	}
+
+	payment, err := s.paymentService.Create(userID(ctx), serviceReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &ainopayv1.CreatePaymentResponse{Payment: paymentMessage(payment)}, nil
+}
+
+func (s *paymentServer) GetPayment(ctx context.Context, req *ainopayv1.GetPaymentRequest) (*ainopayv1.GetPaymentResponse, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, invalidArgument("invalid id")
+	}
+
+	payment, err := s.paymentService.GetOwned(userID(ctx), id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &ainopayv1.GetPaymentResponse{Payment: paymentMessage(payment)}, nil
+}
+
+func (s *paymentServer) ListPayments(ctx context.Context, req *ainopayv1.ListPaymentsRequest) (*ainopayv1.ListPaymentsResponse, error) {
+	page, pageSize := req.GetPage(), req.GetPageSize()
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = 10
+	}
+	if page < 1 || pageSize < 1 || pageSize > maxPageSize {
+		return nil, invalidArgument("page must be at least 1 and page_size between 1 and 100")
+	}
+
+	filter, err := paymentFilter(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = int(pageSize)
+	filter.Offset = int((page - 1) * pageSize)
+
+	payments, total, err := s.paymentRepo.FindAll(userID(ctx), filter)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &ainopayv1.ListPaymentsResponse{
+		Payments: make([]*ainopayv1.Payment, len(payments)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for i := range payments {
+		response.Payments[i] = paymentMessage(&payments[i])
+	}
+	return response, nil
+}
+
+func (s *paymentServer) ExportPayments(req *ainopayv1.ExportPaymentsRequest, stream grpc.ServerStreamingServer[ainopayv1.ExportPaymentsResponse]) error {
+	filter, err := paymentFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	// Read the payments in batches, sending each before reading the next, so
+	// that large exports stay small in memory and stop when the client goes away
+	ctx := stream.Context()
+	var last *models.Payment
+	for {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		payments, err := s.paymentRepo.FindBatch(userID(ctx), filter, last, exportBatchSize)
+		if err != nil {
+			return statusError(err)
+		}
+
+		for i := range payments {
+			if err := stream.Send(&ainopayv1.ExportPaymentsResponse{Payment: paymentMessage(&payments[i])}); err != nil {
+				return err
+			}
+		}
+		if len(payments) < exportBatchSize {
+			return nil
+		}
+		last = &payments[len(payments)-1]
+	}
+}
+
+// paymentFilter converts a filter, which may be nil
+func paymentFilter(f *ainopayv1.PaymentFilter) (repositories.PaymentFilter, error) {
+	var filter repositories.PaymentFilter
+	if f == nil {
+		return filter, nil
+	}
+
+	filter.Status = f.Status
+	filter.Direction = f.Direction
+	filter.Search = f.Search
+	filter.MinAmount = f.MinAmount
+	filter.MaxAmount = f.MaxAmount
+	filter.Reconciled = f.Reconciled
+
+	if f.StartTime != nil {
+		if !f.StartTime.IsValid() {
+			return filter, invalidArgument("invalid filter.start_time")
+		}
+		t := f.StartTime.AsTime()
+		filter.StartDate = &t
+	}
+	if f.EndTime != nil {
+		if !f.EndTime.IsValid() {
+			return filter, invalidArgument("invalid filter.end_time")
+		}
+		t := f.EndTime.AsTime()
+		filter.EndDate = &t
+	}
+
+	var err error
+	if filter.CategoryID, err = parseOptionalID(f.CategoryId); err != nil {
+		return filter, invalidArgument("invalid filter.category_id")
+	}
+	if filter.PaymentMethodID, err = parseOptionalID(f.PaymentMethodId); err != nil {
+		return filter, invalidArgument("invalid filter.payment_method_id")
+	}
+	return filter, nil
+}
+
+// parseOptionalID parses an optional ID, returning nil for an empty string
+func parseOptionalID(val string) (*uuid.UUID, error) {
+	if val == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(val)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
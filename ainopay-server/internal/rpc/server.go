@@ -0,0 +1,41 @@
+// Package rpc serves the gRPC API for internal clients. The services are
+// defined in proto/ainopay/v1 and the generated code lives in ainopayv1;
+// run make proto after changing the definitions.
+package rpc
+
+import (
+	"ainopay-server/internal/config"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/rpc/ainopayv1"
+	"ainopay-server/internal/services"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+)
+
+// NewServer returns a gRPC server with the payment, category and stats
+// services, authenticating every call. userLocation returns the timezone
+// preference of a user.
+func NewServer(cfg *config.Config, paymentService *services.PaymentService, reportService *services.ReportService, paymentRepo *repositories.PaymentRepository, categoryRepo *repositories.CategoryRepository, paymentMethodRepo *repositories.PaymentMethodRepository, userLocation func(userID uuid.UUID) (*time.Location, error)) *grpc.Server {
+	auth := newAuthenticator(cfg)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auth.unaryInterceptor),
+		grpc.ChainStreamInterceptor(auth.streamInterceptor),
+	)
+
+	ainopayv1.RegisterPaymentServiceServer(server, &paymentServer{
+		paymentService: paymentService,
+		paymentRepo:    paymentRepo,
+	})
+	ainopayv1.RegisterCategoryServiceServer(server, &categoryServer{
+		categoryRepo:      categoryRepo,
+		paymentMethodRepo: paymentMethodRepo,
+	})
+	ainopayv1.RegisterStatsServiceServer(server, &statsServer{
+		reportService: reportService,
+		userLocation:  userLocation,
+	})
+
+	return server
+}
@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/rpc/ainopayv1"
+	"ainopay-server/internal/services"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type statsServer struct {
+	ainopayv1.UnimplementedStatsServiceServer
+	reportService *services.ReportService
+	userLocation  func(userID uuid.UUID) (*time.Location, error)
+}
+
+func (s *statsServer) GetDashboardStats(ctx context.Context, req *ainopayv1.GetDashboardStatsRequest) (*ainopayv1.GetDashboardStatsResponse, error) {
+	id := userID(ctx)
+
+	// The timezone of the request, else the user's preference, else UTC
+	loc := time.UTC
+	if req.GetTimezone() != "" {
+		override, err := models.LoadTimezone(req.GetTimezone())
+		if err != nil {
+			return nil, invalidArgument("invalid timezone, expected an IANA name such as Asia/Jakarta")
+		}
+		loc = override
+	} else if preferred, err := s.userLocation(id); err == nil {
+		loc = preferred
+	}
+
+	statsReq := &services.StatsRequest{Preset: req.GetPreset(), Compare: req.GetCompare(), Location: loc}
+	if req.GetFrom() != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.GetFrom(), loc)
+		if err != nil {
+			return nil, invalidArgument("invalid from date format")
+		}
+		statsReq.From = &t
+	}
+	if req.GetTo() != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.GetTo(), loc)
+		if err != nil {
+			return nil, invalidArgument("invalid to date format")
+		}
+		statsReq.To = &t
+	}
+
+	stats, err := s.reportService.GetDashboardStats(id, statsReq)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return dashboardStatsMessage(stats), nil
+}
@@ -0,0 +1,477 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxBalancePoints limits the length of a balance history
+const maxBalancePoints = 1000
+
+var (
+	ErrFinancialAccountNotFound = errors.New("account not found")
+	ErrFinancialAccountInactive = errors.New("account is inactive")
+	ErrFinancialAccountInUse    = errors.New("account has payments or transfers; deactivate it instead")
+	ErrInvalidFinancialAccount  = errors.New("invalid account type")
+	ErrTransferNotFound         = errors.New("transfer not found")
+	ErrSameAccountTransfer      = errors.New("cannot transfer to the same account")
+	ErrCrossCurrencyTransfer    = errors.New("cannot transfer between accounts in different currencies")
+	ErrInvalidCurrency          = errors.New("currency must be an ISO 4217 code")
+	ErrCurrencyInUse            = errors.New("account has payments or transfers; its currency cannot be changed")
+	ErrInvalidBalanceInterval   = errors.New("interval must be day, week or month")
+	ErrBalanceRangeTooLarge     = errors.New("date range has too many periods for the interval")
+)
+
+// AccountService manages the user's financial accounts and transfers between them
+type AccountService struct {
+	accountRepo   *repositories.AccountRepository
+	ledgerService *LedgerService
+}
+
+func NewAccountService(accountRepo *repositories.AccountRepository, ledgerService *LedgerService) *AccountService {
+	return &AccountService{accountRepo: accountRepo, ledgerService: ledgerService}
+}
+
+type FinancialAccountRequest struct {
+	Name            string
+	Type            string
+	PaymentMethodID *uuid.UUID
+	Currency        string // ISO 4217 code, defaults to models.DefaultCurrency on create and is unchanged on update when empty
+	OpeningBalance  float64
+	IsActive        *bool
+}
+
+type CreateTransferRequest struct {
+	FromAccountID uuid.UUID
+	ToAccountID   uuid.UUID
+	Amount        float64
+	Description   string
+	TransferDate  time.Time
+}
+
+// FinancialAccountResponse is an account with its current balance
+type FinancialAccountResponse struct {
+	models.FinancialAccount
+	Balance float64 `json:"balance"`
+}
+
+type TransferListResponse struct {
+	Transfers []models.Transfer `json:"transfers"`
+	Total     int64             `json:"total"`
+	Page      int               `json:"page"`
+	Limit     int               `json:"limit"`
+}
+
+type BalanceHistoryResponse struct {
+	AccountID      uuid.UUID             `json:"account_id"`
+	Interval       string                `json:"interval"`
+	StartDate      time.Time             `json:"start_date"`
+	EndDate        time.Time             `json:"end_date"`
+	OpeningBalance float64               `json:"opening_balance"` // balance before start_date
+	Points         []models.BalancePoint `json:"points"`
+}
+
+// currencyValidator checks currency codes against ISO 4217
+var currencyValidator = validator.New()
+
+// normalizeCurrency returns the upper-cased ISO 4217 code of currency
+func normalizeCurrency(currency string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(currency))
+	if currencyValidator.Var(code, "required,iso4217") != nil {
+		return "", ErrInvalidCurrency
+	}
+	return code, nil
+}
+
+func validFinancialAccountType(t string) bool {
+	switch t {
+	case models.FinancialAccountBank, models.FinancialAccountEWallet,
+		models.FinancialAccountCash, models.FinancialAccountCreditCard:
+		return true
+	}
+	return false
+}
+
+// GetOwned returns an account of the user
+func (s *AccountService) GetOwned(userID, id uuid.UUID) (*models.FinancialAccount, error) {
+	account, err := s.accountRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFinancialAccountNotFound
+		}
+		return nil, err
+	}
+	if account.UserID != userID {
+		return nil, ErrFinancialAccountNotFound
+	}
+	return account, nil
+}
+
+// balance returns the balance of an account before end, or its current balance
+func (s *AccountService) balance(account *models.FinancialAccount, end *time.Time) (float64, error) {
+	movements, err := s.accountRepo.Movements(account.ID, nil, end, "", time.UTC)
+	if err != nil {
+		return 0, err
+	}
+
+	cents := toCents(account.OpeningBalance)
+	for _, m := range movements {
+		cents += toCents(m.Inflow) - toCents(m.Outflow)
+	}
+	return fromCents(cents), nil
+}
+
+func (s *AccountService) withBalance(account *models.FinancialAccount) (*FinancialAccountResponse, error) {
+	balance, err := s.balance(account, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FinancialAccountResponse{FinancialAccount: *account, Balance: balance}, nil
+}
+
+func (s *AccountService) GetAll(userID uuid.UUID) ([]FinancialAccountResponse, error) {
+	accounts, err := s.accountRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]FinancialAccountResponse, 0, len(accounts))
+	for i := range accounts {
+		account, err := s.withBalance(&accounts[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *account)
+	}
+	return result, nil
+}
+
+func (s *AccountService) GetByID(userID, id uuid.UUID) (*FinancialAccountResponse, error) {
+	account, err := s.GetOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withBalance(account)
+}
+
+func (s *AccountService) Create(userID uuid.UUID, req *FinancialAccountRequest) (*FinancialAccountResponse, error) {
+	if !validFinancialAccountType(req.Type) {
+		return nil, ErrInvalidFinancialAccount
+	}
+
+	account := &models.FinancialAccount{
+		UserID:          userID,
+		Name:            req.Name,
+		Type:            req.Type,
+		PaymentMethodID: req.PaymentMethodID,
+		Currency:        models.DefaultCurrency,
+		OpeningBalance:  req.OpeningBalance,
+		IsActive:        true,
+	}
+	if req.Currency != "" {
+		currency, err := normalizeCurrency(req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		account.Currency = currency
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+
+	err := s.accountRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.accountRepo.WithTx(tx).Create(account); err != nil {
+			return err
+		}
+		return s.ledgerService.PostOpeningBalance(tx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(userID, account.ID)
+}
+
+func (s *AccountService) Update(userID, id uuid.UUID, req *FinancialAccountRequest) (*FinancialAccountResponse, error) {
+	if !validFinancialAccountType(req.Type) {
+		return nil, ErrInvalidFinancialAccount
+	}
+
+	account, err := s.GetOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	account.Name = req.Name
+	account.Type = req.Type
+	account.PaymentMethodID = req.PaymentMethodID
+	account.OpeningBalance = req.OpeningBalance
+	if req.Currency != "" {
+		currency, err := normalizeCurrency(req.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if currency != account.Currency {
+			// Amounts already booked to the account are in its old currency
+			used, err := s.accountRepo.CountUsage(id)
+			if err != nil {
+				return nil, err
+			}
+			if used > 0 {
+				return nil, ErrCurrencyInUse
+			}
+			account.Currency = currency
+		}
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+
+	err = s.accountRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.accountRepo.WithTx(tx).Update(account); err != nil {
+			return err
+		}
+		return s.ledgerService.PostOpeningBalance(tx, account)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(userID, id)
+}
+
+// Delete removes an account that was never used
+func (s *AccountService) Delete(userID, id uuid.UUID) error {
+	account, err := s.GetOwned(userID, id)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.accountRepo.CountUsage(id)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrFinancialAccountInUse
+	}
+
+	return s.accountRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.accountRepo.WithTx(tx).Delete(id); err != nil {
+			return err
+		}
+		return s.ledgerService.ReverseOpeningBalance(tx, account)
+	})
+}
+
+// ResolveForPayment returns the account a payment is made from: the given
+// account, which must be an active account of the user, or else the default
+// account of the payment method. It returns nil when there is neither.
+func (s *AccountService) ResolveForPayment(userID uuid.UUID, accountID *uuid.UUID, paymentMethodID uuid.UUID) (*uuid.UUID, error) {
+	account, err := s.resolveAccountForPayment(userID, accountID, paymentMethodID)
+	if err != nil || account == nil {
+		return nil, err
+	}
+	return &account.ID, nil
+}
+
+// resolveAccountForPayment returns the account ResolveForPayment resolves,
+// or nil when the payment is not linked to one
+func (s *AccountService) resolveAccountForPayment(userID uuid.UUID, accountID *uuid.UUID, paymentMethodID uuid.UUID) (*models.FinancialAccount, error) {
+	if accountID != nil {
+		account, err := s.GetOwned(userID, *accountID)
+		if err != nil {
+			return nil, err
+		}
+		if !account.IsActive {
+			return nil, ErrFinancialAccountInactive
+		}
+		return account, nil
+	}
+
+	account, err := s.accountRepo.FindDefaultForPaymentMethod(userID, paymentMethodID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *AccountService) CreateTransfer(userID uuid.UUID, req *CreateTransferRequest) (*models.Transfer, error) {
+	if req.FromAccountID == req.ToAccountID {
+		return nil, ErrSameAccountTransfer
+	}
+	currencies := make(map[string]bool, 2)
+	for _, id := range []uuid.UUID{req.FromAccountID, req.ToAccountID} {
+		account, err := s.GetOwned(userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if !account.IsActive {
+			return nil, ErrFinancialAccountInactive
+		}
+		currencies[account.Currency] = true
+	}
+	if len(currencies) > 1 {
+		return nil, ErrCrossCurrencyTransfer
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		TransferDate:  req.TransferDate,
+	}
+
+	err := s.accountRepo.Transaction(func(tx *gorm.DB) error {
+		accountRepo := s.accountRepo.WithTx(tx)
+		if err := accountRepo.CreateTransfer(transfer); err != nil {
+			return err
+		}
+
+		created, err := accountRepo.FindTransferByID(transfer.ID)
+		if err != nil {
+			return err
+		}
+		transfer = created
+		return s.ledgerService.PostTransfer(tx, transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (s *AccountService) GetTransfers(userID uuid.UUID, page, limit int, filter repositories.TransferFilter) (*TransferListResponse, error) {
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	transfers, total, err := s.accountRepo.FindTransfers(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransferListResponse{
+		Transfers: transfers,
+		Total:     total,
+		Page:      page,
+		Limit:     limit,
+	}, nil
+}
+
+func (s *AccountService) DeleteTransfer(userID, id uuid.UUID) error {
+	transfer, err := s.accountRepo.FindTransferByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTransferNotFound
+		}
+		return err
+	}
+	if transfer.UserID != userID {
+		return ErrTransferNotFound
+	}
+
+	return s.accountRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.accountRepo.WithTx(tx).DeleteTransfer(id); err != nil {
+			return err
+		}
+		return s.ledgerService.ReverseTransfer(tx, transfer)
+	})
+}
+
+// truncatePeriod returns the start of the day, ISO week or month containing t
+func truncatePeriod(t time.Time, interval string) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch interval {
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7 // Monday starts the week
+		return t.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+func nextPeriod(t time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// GetBalanceHistory returns the balance of an account at the end of every
+// day, week or month in the given location between start and end, computed
+// from its payments and transfers
+func (s *AccountService) GetBalanceHistory(userID, id uuid.UUID, start, end time.Time, interval string, loc *time.Location) (*BalanceHistoryResponse, error) {
+	switch interval {
+	case "day", "week", "month":
+	default:
+		return nil, ErrInvalidBalanceInterval
+	}
+
+	account, err := s.GetOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	start = truncatePeriod(start.In(loc), interval)
+	end = nextPeriod(truncatePeriod(end.In(loc), interval), interval)
+
+	periods := 0
+	for p := start; p.Before(end); p = nextPeriod(p, interval) {
+		if periods++; periods > maxBalancePoints {
+			return nil, ErrBalanceRangeTooLarge
+		}
+	}
+
+	opening, err := s.balance(account, &start)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.accountRepo.Movements(id, &start, &end, interval, loc)
+	if err != nil {
+		return nil, err
+	}
+	byPeriod := make(map[string]repositories.AccountMovement, len(movements))
+	for _, m := range movements {
+		byPeriod[m.Period.Format("2006-01-02")] = m
+	}
+
+	result := &BalanceHistoryResponse{
+		AccountID:      id,
+		Interval:       interval,
+		StartDate:      start,
+		EndDate:        end,
+		OpeningBalance: opening,
+		Points:         make([]models.BalancePoint, 0, periods),
+	}
+
+	balance := toCents(opening)
+	for p := start; p.Before(end); p = nextPeriod(p, interval) {
+		m := byPeriod[p.Format("2006-01-02")]
+		balance += toCents(m.Inflow) - toCents(m.Outflow)
+		result.Points = append(result.Points, models.BalancePoint{
+			Period:  p.Format("2006-01-02"),
+			Inflow:  math.Round(m.Inflow*100) / 100,
+			Outflow: math.Round(m.Outflow*100) / 100,
+			Balance: fromCents(balance),
+		})
+	}
+	return result, nil
+}
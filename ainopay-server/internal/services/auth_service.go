@@ -5,19 +5,23 @@ import (
 	"ainopay-server/internal/models"
 	"ainopay-server/internal/repositories"
 	"ainopay-server/internal/utils"
+	"encoding/json"
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var ErrInvalidTimezone = errors.New("timezone must be an IANA time zone name such as Asia/Jakarta")
+
 type AuthService struct {
 	userRepo          *repositories.UserRepository
 	refreshTokenRepo  *repositories.RefreshTokenRepository
 	passwordResetRepo *repositories.PasswordResetRepository
+	summaryRepo       *repositories.PaymentSummaryRepository
 	emailService      *EmailService
+	outboxService     *OutboxService
 	cfg               *config.Config
 }
 
@@ -25,14 +29,18 @@ func NewAuthService(
 	userRepo *repositories.UserRepository,
 	refreshTokenRepo *repositories.RefreshTokenRepository,
 	passwordResetRepo *repositories.PasswordResetRepository,
+	summaryRepo *repositories.PaymentSummaryRepository,
 	emailService *EmailService,
+	outboxService *OutboxService,
 	cfg *config.Config,
 ) *AuthService {
 	return &AuthService{
 		userRepo:          userRepo,
 		refreshTokenRepo:  refreshTokenRepo,
 		passwordResetRepo: passwordResetRepo,
+		summaryRepo:       summaryRepo,
 		emailService:      emailService,
+		outboxService:     outboxService,
 		cfg:               cfg,
 	}
 }
@@ -41,6 +49,13 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
+	Timezone string `json:"timezone"` // defaults to UTC
+}
+
+// UpdateProfileRequest changes the given fields of the user's profile
+type UpdateProfileRequest struct {
+	FullName *string
+	Timezone *string
 }
 
 type LoginRequest struct {
@@ -65,6 +80,14 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		return nil, err
 	}
 
+	timezone := "UTC"
+	if req.Timezone != "" {
+		if _, err := models.LoadTimezone(req.Timezone); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+		timezone = req.Timezone
+	}
+
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -77,9 +100,16 @@ func (s *AuthService) Register(req *RegisterRequest) (*AuthResponse, error) {
 		PasswordHash: hashedPassword,
 		FullName:     req.FullName,
 		Role:         "user",
+		Timezone:     timezone,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	err = s.outboxService.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).Create(user); err != nil {
+			return err
+		}
+		return s.outboxService.Record(tx, models.AggregateUser, user.ID, user.ID, models.EventUserRegistered, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -144,6 +174,73 @@ func (s *AuthService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return s.userRepo.FindByID(id)
 }
 
+// UpdateProfile changes the user's name and timezone preference. A new
+// timezone moves the user's payments to the days of that timezone in the
+// daily summaries.
+func (s *AuthService) UpdateProfile(id uuid.UUID, req *UpdateProfileRequest) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+	}
+	timezoneChanged := false
+	if req.Timezone != nil {
+		if _, err := models.LoadTimezone(*req.Timezone); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+		timezoneChanged = *req.Timezone != user.Timezone
+		user.Timezone = *req.Timezone
+	}
+
+	if !timezoneChanged {
+		if err := s.userRepo.Update(user); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+
+	err = s.outboxService.Transaction(func(tx *gorm.DB) error {
+		// Lock the summaries before the user row, in the order payment changes take them
+		summaryRepo := s.summaryRepo.WithTx(tx)
+		if err := summaryRepo.Lock(); err != nil {
+			return err
+		}
+		if err := s.userRepo.WithTx(tx).Update(user); err != nil {
+			return err
+		}
+		return summaryRepo.Rebuild(&user.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetLocation returns the location of the user's timezone preference, or UTC
+// when it is not set
+func (s *AuthService) GetLocation(id uuid.UUID) (*time.Location, error) {
+	return findUserLocation(s.userRepo, id)
+}
+
+// findUserLocation loads the location of a user's timezone preference, or UTC
+// when it is not set
+func findUserLocation(userRepo *repositories.UserRepository, id uuid.UUID) (*time.Location, error) {
+	timezone, err := userRepo.FindTimezone(id)
+	if err != nil {
+		return nil, err
+	}
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	return models.LoadTimezone(timezone)
+}
+
 // GenerateRefreshToken creates a new refresh token for a user
 func (s *AuthService) GenerateRefreshToken(userID uuid.UUID) (string, error) {
 	// Delete old refresh tokens for this user
@@ -222,19 +319,44 @@ func (s *AuthService) ForgotPassword(email string) error {
 		ExpiresAt: time.Now().Add(1 * time.Hour), // 1 hour expiry
 	}
 
-	if err := s.passwordResetRepo.Create(token); err != nil {
+	// The email is sent by the outbox relay once the token is committed
+	return s.outboxService.Transaction(func(tx *gorm.DB) error {
+		if err := s.passwordResetRepo.WithTx(tx).Create(token); err != nil {
+			return err
+		}
+		return s.outboxService.Record(tx, models.AggregateUser, user.ID, user.ID, models.EventPasswordResetRequested, PasswordResetRequestedEvent{
+			Email: user.Email,
+			Token: token.Token,
+		})
+	})
+}
+
+// PasswordResetRequestedEvent is the payload of user.password_reset_requested events
+type PasswordResetRequestedEvent struct {
+	Email string `json:"email"`
+	Token string `json:"token"`
+}
+
+// HandlePasswordResetRequested sends the reset email for an event relayed
+// from the outbox. Tokens that were used or expired in the meantime are skipped.
+func (s *AuthService) HandlePasswordResetRequested(event *models.OutboxEvent) error {
+	var data PasswordResetRequestedEvent
+	if err := json.Unmarshal([]byte(event.Payload), &data); err != nil {
 		return err
 	}
 
-	// Send email
-	// Run in goroutine to not block response
-	go func() {
-		if err := s.emailService.SendPasswordResetEmail(user.Email, token.Token); err != nil {
-			fmt.Printf("Failed to send email: %v\n", err)
+	token, err := s.passwordResetRepo.FindByToken(data.Token)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
 		}
-	}()
+		return err
+	}
+	if !token.IsValid() {
+		return nil
+	}
 
-	return nil
+	return s.emailService.SendPasswordResetEmail(data.Email, data.Token)
 }
 
 // ResetPassword resets user password using token
@@ -265,14 +387,16 @@ func (s *AuthService) ResetPassword(tokenString, newPassword string) error {
 	// Looking at UserRepo, it has Create, FindBy... but maybe no Update?
 	// Let's assume we can add Update to UserRepo or use DB directly if we had access (we don't here).
 	// Let's add Update to UserRepo in next step. For now, calling a method we will create.
-	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
-		return err
-	}
+	return s.outboxService.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).UpdatePassword(user.ID, hashedPassword); err != nil {
+			return err
+		}
 
-	// Mark token as used
-	if err := s.passwordResetRepo.MarkAsUsed(token.ID); err != nil {
-		// Log error but critical part (password change) succeeded
-	}
+		// Mark token as used
+		if err := s.passwordResetRepo.WithTx(tx).MarkAsUsed(token.ID); err != nil {
+			return err
+		}
 
-	return nil
+		return s.outboxService.Record(tx, models.AggregateUser, user.ID, user.ID, models.EventPasswordChanged, map[string]interface{}{"user_id": user.ID})
+	})
 }
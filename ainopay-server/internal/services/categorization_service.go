@@ -0,0 +1,307 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategorizationRuleNotFound = errors.New("categorization rule not found")
+	ErrInvalidCategorizationRule  = errors.New("invalid categorization rule")
+	ErrCategoryRequired           = errors.New("category_id is required when no categorization rule sets the category")
+)
+
+// CategorizationService manages the user's categorization rules and applies
+// them to payments
+type CategorizationService struct {
+	ruleRepo    *repositories.CategorizationRuleRepository
+	paymentRepo *repositories.PaymentRepository
+}
+
+func NewCategorizationService(ruleRepo *repositories.CategorizationRuleRepository, paymentRepo *repositories.PaymentRepository) *CategorizationService {
+	return &CategorizationService{ruleRepo: ruleRepo, paymentRepo: paymentRepo}
+}
+
+type CategorizationRuleRequest struct {
+	Name                string
+	Priority            int
+	DescriptionContains string
+	DescriptionRegex    string
+	MinAmount           *float64
+	MaxAmount           *float64
+	PaymentMethodID     *uuid.UUID
+	CategoryID          *uuid.UUID
+	Payee               string
+	Tags                []string
+	IsActive            *bool
+}
+
+// Categorization is what the matching rules set on a payment
+type Categorization struct {
+	CategoryID *uuid.UUID
+	Payee      string
+	Tags       []string
+	RuleIDs    []uuid.UUID // rules that matched
+}
+
+// CategorizationChange is a change the rules make to an existing payment
+type CategorizationChange struct {
+	PaymentID     uuid.UUID   `json:"payment_id"`
+	Description   string      `json:"description"`
+	CategoryID    uuid.UUID   `json:"category_id"`
+	NewCategoryID uuid.UUID   `json:"new_category_id"`
+	Payee         string      `json:"payee"`
+	NewPayee      string      `json:"new_payee"`
+	Tags          []string    `json:"tags"`
+	NewTags       []string    `json:"new_tags"`
+	RuleIDs       []uuid.UUID `json:"rule_ids"`
+}
+
+// compiledRule is a rule with its regular expression compiled
+type compiledRule struct {
+	rule  *models.CategorizationRule
+	regex *regexp.Regexp
+}
+
+func compileRule(rule *models.CategorizationRule) (*compiledRule, error) {
+	compiled := &compiledRule{rule: rule}
+	if rule.DescriptionRegex != "" {
+		regex, err := regexp.Compile("(?i)" + rule.DescriptionRegex)
+		if err != nil {
+			return nil, err
+		}
+		compiled.regex = regex
+	}
+	return compiled, nil
+}
+
+func (r *compiledRule) matches(description string, amount float64, paymentMethodID uuid.UUID) bool {
+	rule := r.rule
+	if rule.DescriptionContains != "" && !strings.Contains(strings.ToLower(description), strings.ToLower(rule.DescriptionContains)) {
+		return false
+	}
+	if r.regex != nil && !r.regex.MatchString(description) {
+		return false
+	}
+	if rule.MinAmount != nil && amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && amount > *rule.MaxAmount {
+		return false
+	}
+	return rule.PaymentMethodID == nil || *rule.PaymentMethodID == paymentMethodID
+}
+
+// normalizeTags lowercases and trims tags, dropping empty and repeated ones
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func validateCategorizationRule(req *CategorizationRuleRequest) error {
+	if req.DescriptionContains == "" && req.DescriptionRegex == "" && req.MinAmount == nil &&
+		req.MaxAmount == nil && req.PaymentMethodID == nil {
+		return fmt.Errorf("%w: at least one condition is required", ErrInvalidCategorizationRule)
+	}
+	if req.CategoryID == nil && req.Payee == "" && len(normalizeTags(req.Tags)) == 0 {
+		return fmt.Errorf("%w: the rule must set a category, payee or tags", ErrInvalidCategorizationRule)
+	}
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		return fmt.Errorf("%w: min_amount is greater than max_amount", ErrInvalidCategorizationRule)
+	}
+	if req.DescriptionRegex != "" {
+		if _, err := regexp.Compile(req.DescriptionRegex); err != nil {
+			return fmt.Errorf("%w: description_regex: %v", ErrInvalidCategorizationRule, err)
+		}
+	}
+	return nil
+}
+
+func (s *CategorizationService) GetAll(userID uuid.UUID) ([]models.CategorizationRule, error) {
+	return s.ruleRepo.FindByUserID(userID)
+}
+
+// GetOwned returns a rule of the user
+func (s *CategorizationService) GetOwned(userID, id uuid.UUID) (*models.CategorizationRule, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && rule.UserID != userID) {
+		return nil, ErrCategorizationRuleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *CategorizationService) Create(userID uuid.UUID, req *CategorizationRuleRequest) (*models.CategorizationRule, error) {
+	if err := validateCategorizationRule(req); err != nil {
+		return nil, err
+	}
+
+	rule := &models.CategorizationRule{UserID: userID, IsActive: true}
+	applyCategorizationRuleRequest(rule, req)
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return s.ruleRepo.FindByID(rule.ID)
+}
+
+func (s *CategorizationService) Update(userID, id uuid.UUID, req *CategorizationRuleRequest) (*models.CategorizationRule, error) {
+	if err := validateCategorizationRule(req); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.GetOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyCategorizationRuleRequest(rule, req)
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return s.ruleRepo.FindByID(id)
+}
+
+func (s *CategorizationService) Delete(userID, id uuid.UUID) error {
+	if _, err := s.GetOwned(userID, id); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(id)
+}
+
+func applyCategorizationRuleRequest(rule *models.CategorizationRule, req *CategorizationRuleRequest) {
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	rule.DescriptionContains = req.DescriptionContains
+	rule.DescriptionRegex = req.DescriptionRegex
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.PaymentMethodID = req.PaymentMethodID
+	rule.CategoryID = req.CategoryID
+	rule.Category = nil
+	rule.Payee = strings.TrimSpace(req.Payee)
+	rule.Tags = normalizeTags(req.Tags)
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+}
+
+// activeRules returns the user's active rules, or only the given rule
+func (s *CategorizationService) activeRules(userID uuid.UUID, ruleID *uuid.UUID) ([]*compiledRule, error) {
+	var rules []models.CategorizationRule
+	if ruleID != nil {
+		rule, err := s.GetOwned(userID, *ruleID)
+		if err != nil {
+			return nil, err
+		}
+		rules = []models.CategorizationRule{*rule}
+	} else {
+		var err error
+		if rules, err = s.ruleRepo.FindActiveByUserID(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i := range rules {
+		rule, err := compileRule(&rules[i])
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
+
+func categorize(rules []*compiledRule, description string, amount float64, paymentMethodID uuid.UUID) *Categorization {
+	result := &Categorization{}
+	var tags []string
+	for _, compiled := range rules {
+		if !compiled.matches(description, amount, paymentMethodID) {
+			continue
+		}
+
+		rule := compiled.rule
+		result.RuleIDs = append(result.RuleIDs, rule.ID)
+		if result.CategoryID == nil && rule.CategoryID != nil {
+			categoryID := *rule.CategoryID
+			result.CategoryID = &categoryID
+		}
+		if result.Payee == "" {
+			result.Payee = rule.Payee
+		}
+		tags = append(tags, rule.Tags...)
+	}
+	result.Tags = normalizeTags(tags)
+	return result
+}
+
+// Categorize applies the user's active rules to a new payment
+func (s *CategorizationService) Categorize(userID uuid.UUID, description string, amount float64, paymentMethodID uuid.UUID) (*Categorization, error) {
+	rules, err := s.activeRules(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return categorize(rules, description, amount, paymentMethodID), nil
+}
+
+// Preview returns the changes the user's active rules, or only the given
+// rule, would make to existing payments. A matching rule replaces the
+// category and payee and adds its tags.
+func (s *CategorizationService) Preview(userID uuid.UUID, ruleID *uuid.UUID) ([]CategorizationChange, error) {
+	rules, err := s.activeRules(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, _, err := s.paymentRepo.FindAll(userID, repositories.PaymentFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []CategorizationChange{}
+	for _, payment := range payments {
+		result := categorize(rules, payment.Description, payment.Amount, payment.PaymentMethodID)
+		if len(result.RuleIDs) == 0 {
+			continue
+		}
+
+		change := CategorizationChange{
+			PaymentID:     payment.ID,
+			Description:   payment.Description,
+			CategoryID:    payment.CategoryID,
+			NewCategoryID: payment.CategoryID,
+			Payee:         payment.Payee,
+			NewPayee:      payment.Payee,
+			Tags:          normalizeTags(payment.Tags),
+			NewTags:       normalizeTags(append(slices.Clone(payment.Tags), result.Tags...)),
+			RuleIDs:       result.RuleIDs,
+		}
+		if result.CategoryID != nil {
+			change.NewCategoryID = *result.CategoryID
+		}
+		if result.Payee != "" {
+			change.NewPayee = result.Payee
+		}
+
+		if change.NewCategoryID != change.CategoryID || change.NewPayee != change.Payee || !slices.Equal(change.NewTags, change.Tags) {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
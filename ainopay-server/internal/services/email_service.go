@@ -1,6 +1,7 @@
 package services
 
 import (
+	"ainopay-server/internal/models"
 	"fmt"
 	"log"
 )
@@ -30,3 +31,31 @@ func (s *EmailService) SendPasswordResetEmail(email, token string) error {
 	
 	return nil
 }
+
+// SendSummaryEmail sends a periodic summary email to the user
+func (s *EmailService) SendSummaryEmail(email string, summary *SummaryEmail) error {
+	// For MVP/Demo, we'll just log the plain-text body to the console
+
+	log.Printf("----------------------------------------------------------------")
+	log.Printf("📧 EMAIL SIMULATION - Summary")
+	log.Printf("To: %s", email)
+	log.Printf("Subject: %s", summary.Subject)
+	log.Printf("Body:\n%s", summary.Text)
+	log.Printf("----------------------------------------------------------------")
+
+	return nil
+}
+
+// SendNotificationEmail sends a notification to the user by email
+func (s *EmailService) SendNotificationEmail(email string, notification *models.Notification) error {
+	// For MVP/Demo, we'll just log the notification to the console
+
+	log.Printf("----------------------------------------------------------------")
+	log.Printf("📧 EMAIL SIMULATION - Notification")
+	log.Printf("To: %s", email)
+	log.Printf("Subject: %s", notification.Title)
+	log.Printf("Body: %s", notification.Message)
+	log.Printf("----------------------------------------------------------------")
+
+	return nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"ainopay-server/internal/config"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/providers"
+	"ainopay-server/internal/repositories"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const providerRequestTimeout = 15 * time.Second
+
+var (
+	ErrPaymentNotChargeable = errors.New("only pending payments that have not been charged can be charged")
+	ErrPaymentNotCharged    = errors.New("payment has not been charged through a provider")
+	ErrPaymentNotRefundable = errors.New("only completed payments can be refunded")
+)
+
+// GatewayService charges, settles and refunds payments through payment providers
+type GatewayService struct {
+	paymentRepo     *repositories.PaymentRepository
+	paymentService  *PaymentService
+	registry        *providers.Registry
+	callbackBaseURL string
+}
+
+func NewGatewayService(
+	paymentRepo *repositories.PaymentRepository,
+	paymentService *PaymentService,
+	registry *providers.Registry,
+	cfg *config.Config,
+) *GatewayService {
+	return &GatewayService{
+		paymentRepo:     paymentRepo,
+		paymentService:  paymentService,
+		registry:        registry,
+		callbackBaseURL: strings.TrimRight(cfg.Provider.CallbackBaseURL, "/"),
+	}
+}
+
+// Providers lists the names of the available providers
+func (s *GatewayService) Providers() []string {
+	return s.registry.Names()
+}
+
+// ownedPayment loads a payment and checks that it belongs to the user
+func (s *GatewayService) ownedPayment(userID, paymentID uuid.UUID) (*models.Payment, error) {
+	payment, err := s.paymentRepo.FindByID(paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	if payment.UserID != userID {
+		return nil, ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+// Charge sends a pending payment to a provider for collection. The payment
+// stays pending until the provider reports the outcome.
+func (s *GatewayService) Charge(userID, paymentID uuid.UUID, providerName string) (*models.Payment, error) {
+	payment, err := s.ownedPayment(userID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != providers.StatusPending || payment.ProviderReference != "" {
+		return nil, ErrPaymentNotChargeable
+	}
+
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+	defer cancel()
+
+	result, err := provider.CreateCharge(ctx, providers.ChargeRequest{
+		PaymentID:   payment.ID,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency(),
+		Description: payment.Description,
+		CallbackURL: s.callbackBaseURL + "/" + provider.Name(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Another charge of the payment may have finished in the meantime
+	return s.paymentService.applyStatus(payment.ID, func(current *models.Payment) (string, error) {
+		if current.Status != providers.StatusPending || current.ProviderReference != "" {
+			return "", ErrPaymentNotChargeable
+		}
+		return result.Status, nil
+	}, map[string]interface{}{
+		"provider":           provider.Name(),
+		"provider_reference": result.Reference,
+		"provider_payload":   string(result.Raw),
+	})
+}
+
+// SyncStatus asks the provider for the current state of a charge and applies it
+func (s *GatewayService) SyncStatus(userID, paymentID uuid.UUID) (*models.Payment, error) {
+	payment, err := s.ownedPayment(userID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.ProviderReference == "" {
+		return nil, ErrPaymentNotCharged
+	}
+
+	provider, err := s.registry.Get(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+	defer cancel()
+
+	result, err := provider.QueryStatus(ctx, payment.ProviderReference)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.paymentService.applyStatus(payment.ID, func(current *models.Payment) (string, error) {
+		if current.Status == providers.StatusPending {
+			return result.Status, nil
+		}
+		return current.Status, nil
+	}, map[string]interface{}{
+		"provider_payload": string(result.Raw),
+	})
+}
+
+// Refund returns the full amount of a completed charge
+func (s *GatewayService) Refund(userID, paymentID uuid.UUID) (*models.Payment, error) {
+	payment, err := s.ownedPayment(userID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.ProviderReference == "" {
+		return nil, ErrPaymentNotCharged
+	}
+	if payment.Status != providers.StatusCompleted {
+		return nil, ErrPaymentNotRefundable
+	}
+
+	provider, err := s.registry.Get(payment.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+	defer cancel()
+
+	result, err := provider.Refund(ctx, payment.ProviderReference, payment.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.paymentService.applyStatus(payment.ID, func(current *models.Payment) (string, error) {
+		if current.Status != providers.StatusCompleted {
+			return "", ErrPaymentNotRefundable
+		}
+		return result.Status, nil
+	}, map[string]interface{}{
+		"provider_payload": string(result.Raw),
+	})
+}
+
+// HandleCallback verifies an inbound provider notification and settles the
+// matching payment. Only pending payments move to completed or failed, and
+// the status is checked with the payment locked, so repeated, concurrent or
+// late notifications are harmless.
+func (s *GatewayService) HandleCallback(providerName string, body []byte, header http.Header) (*models.Payment, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil || providerName == "" {
+		return nil, providers.ErrUnknownProvider
+	}
+
+	notification, err := provider.ParseCallback(body, header)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.paymentRepo.FindByProviderReference(provider.Name(), notification.Reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+
+	return s.paymentService.applyStatus(payment.ID, func(current *models.Payment) (string, error) {
+		if current.Status == providers.StatusPending &&
+			(notification.Status == providers.StatusCompleted || notification.Status == providers.StatusFailed) {
+			return notification.Status, nil
+		}
+		if notification.Status != current.Status {
+			log.Printf("Ignoring %s callback for payment %s: %s -> %s", provider.Name(), current.ID, current.Status, notification.Status)
+		}
+		return current.Status, nil
+	}, map[string]interface{}{
+		"callback_payload": string(notification.Raw),
+	})
+}
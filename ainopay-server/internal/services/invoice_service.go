@@ -0,0 +1,166 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotFound       = errors.New("invoice not found")
+	ErrInvoiceNumberTaken    = errors.New("invoice number already exists")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrPaymentAlreadyApplied = errors.New("payment is already applied to another invoice")
+	ErrPaymentNotOnInvoice   = errors.New("payment is not applied to this invoice")
+)
+
+type InvoiceService struct {
+	invoiceRepo *repositories.InvoiceRepository
+	paymentRepo *repositories.PaymentRepository
+}
+
+func NewInvoiceService(invoiceRepo *repositories.InvoiceRepository, paymentRepo *repositories.PaymentRepository) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepo: invoiceRepo,
+		paymentRepo: paymentRepo,
+	}
+}
+
+type CreateInvoiceRequest struct {
+	Number  string    `json:"number"`
+	Payee   string    `json:"payee"`
+	DueDate time.Time `json:"due_date"`
+	Amount  float64   `json:"amount"`
+}
+
+type UpdateInvoiceRequest struct {
+	Number  string    `json:"number"`
+	Payee   string    `json:"payee"`
+	DueDate time.Time `json:"due_date"`
+	Amount  float64   `json:"amount"`
+}
+
+func (s *InvoiceService) Create(userID uuid.UUID, req *CreateInvoiceRequest) (*models.Invoice, error) {
+	if _, err := s.invoiceRepo.FindByNumber(userID, req.Number); err == nil {
+		return nil, ErrInvoiceNumberTaken
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	invoice := &models.Invoice{
+		UserID:  userID,
+		Number:  req.Number,
+		Payee:   req.Payee,
+		DueDate: req.DueDate,
+		Amount:  req.Amount,
+	}
+
+	if err := s.invoiceRepo.Create(invoice); err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRepo.FindByID(invoice.ID)
+}
+
+// GetByID returns an invoice owned by the user, including its applied payments
+func (s *InvoiceService) GetByID(userID, id uuid.UUID) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	if invoice.UserID != userID {
+		return nil, ErrInvoiceNotFound
+	}
+	return invoice, nil
+}
+
+func (s *InvoiceService) GetAll(userID uuid.UUID, status string) ([]models.Invoice, error) {
+	return s.invoiceRepo.FindAll(userID, status, time.Now())
+}
+
+func (s *InvoiceService) GetOverdue(userID uuid.UUID) ([]models.Invoice, error) {
+	return s.invoiceRepo.FindOverdue(userID, time.Now())
+}
+
+func (s *InvoiceService) Update(userID, id uuid.UUID, req *UpdateInvoiceRequest) (*models.Invoice, error) {
+	invoice, err := s.GetByID(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Number != invoice.Number {
+		if _, err := s.invoiceRepo.FindByNumber(userID, req.Number); err == nil {
+			return nil, ErrInvoiceNumberTaken
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	invoice.Number = req.Number
+	invoice.Payee = req.Payee
+	invoice.DueDate = req.DueDate
+	invoice.Amount = req.Amount
+
+	if err := s.invoiceRepo.Update(invoice); err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRepo.FindByID(id)
+}
+
+func (s *InvoiceService) Delete(userID, id uuid.UUID) error {
+	if _, err := s.GetByID(userID, id); err != nil {
+		return err
+	}
+	return s.invoiceRepo.Delete(id)
+}
+
+// ApplyPayment links a payment to an invoice. Only completed payments count
+// towards the paid amount, so pending installments can be linked up front.
+func (s *InvoiceService) ApplyPayment(userID, invoiceID, paymentID uuid.UUID) (*models.Invoice, error) {
+	if _, err := s.GetByID(userID, invoiceID); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.paymentRepo.FindByID(paymentID)
+	if err != nil || payment.UserID != userID {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.InvoiceID != nil && *payment.InvoiceID != invoiceID {
+		return nil, ErrPaymentAlreadyApplied
+	}
+
+	if err := s.paymentRepo.SetInvoice(paymentID, &invoiceID); err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRepo.FindByID(invoiceID)
+}
+
+// RemovePayment detaches a payment from an invoice
+func (s *InvoiceService) RemovePayment(userID, invoiceID, paymentID uuid.UUID) (*models.Invoice, error) {
+	if _, err := s.GetByID(userID, invoiceID); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.paymentRepo.FindByID(paymentID)
+	if err != nil || payment.UserID != userID {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.InvoiceID == nil || *payment.InvoiceID != invoiceID {
+		return nil, ErrPaymentNotOnInvoice
+	}
+
+	if err := s.paymentRepo.SetInvoice(paymentID, nil); err != nil {
+		return nil, err
+	}
+
+	return s.invoiceRepo.FindByID(invoiceID)
+}
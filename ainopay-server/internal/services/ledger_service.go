@@ -0,0 +1,907 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLedgerAccountNotFound = errors.New("ledger account not found")
+	ErrAccountCodeTaken      = errors.New("an account with this code already exists")
+	ErrReservedAccountCode   = errors.New("account code is reserved for system accounts")
+	ErrInvalidAccountType    = errors.New("invalid account type")
+	ErrJournalEntryNotFound  = errors.New("journal entry not found")
+	ErrUnbalancedEntry       = errors.New("journal entry debits and credits must be equal")
+	ErrInvalidPosting        = errors.New("each posting must have either a positive debit or a positive credit")
+	ErrMixedCurrencyEntry    = errors.New("all postings of a journal entry must be in the same currency")
+)
+
+// Prefixes of the codes of accounts created for financial accounts, payment
+// methods and categories
+const (
+	accountPrefix        = "ACCT-"
+	cashAccountPrefix    = "CASH-"
+	expenseAccountPrefix = "EXP-"
+	incomeAccountPrefix  = "INC-"
+)
+
+var (
+	receivableAccount = models.LedgerAccount{Code: models.AccountCodeReceivable, Name: "Accounts Receivable", Type: models.AccountTypeAsset, IsSystem: true}
+	equityAccount     = models.LedgerAccount{Code: models.AccountCodeEquity, Name: "Opening Balance Equity", Type: models.AccountTypeEquity, IsSystem: true}
+	feesAccount       = models.LedgerAccount{Code: models.AccountCodeFees, Name: "Payment Fees", Type: models.AccountTypeExpense, IsSystem: true}
+	clearingAccount   = models.LedgerAccount{Code: models.AccountCodeClearing, Name: "Transfers Clearing", Type: models.AccountTypeAsset, IsSystem: true}
+	systemAccounts    = []models.LedgerAccount{receivableAccount, equityAccount, feesAccount, clearingAccount}
+)
+
+// LedgerService keeps a double-entry ledger of the user's money. Payments are
+// posted automatically: a completed expense debits the expense account of its
+// category and credits the cash account it was paid from, income debits the
+// cash account and credits the income account of its category, and fees are
+// always debited to the fee account. Amounts are booked in the currency of the
+// payment's account, to accounts in that currency.
+type LedgerService struct {
+	ledgerRepo  *repositories.LedgerRepository
+	paymentRepo *repositories.PaymentRepository
+}
+
+func NewLedgerService(ledgerRepo *repositories.LedgerRepository, paymentRepo *repositories.PaymentRepository) *LedgerService {
+	return &LedgerService{ledgerRepo: ledgerRepo, paymentRepo: paymentRepo}
+}
+
+type CreateLedgerAccountRequest struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"` // defaults to models.DefaultCurrency
+}
+
+type JournalLine struct {
+	AccountID uuid.UUID `json:"account_id"`
+	Debit     float64   `json:"debit"`
+	Credit    float64   `json:"credit"`
+}
+
+type CreateJournalEntryRequest struct {
+	Description string        `json:"description"`
+	EntryDate   time.Time     `json:"entry_date"`
+	Lines       []JournalLine `json:"lines"`
+}
+
+type JournalEntryListResponse struct {
+	Entries []models.JournalEntry `json:"entries"`
+	Total   int64                 `json:"total"`
+	Page    int                   `json:"page"`
+	Limit   int                   `json:"limit"`
+}
+
+// LedgerAccountBalance is an account with the totals posted to it. Balance is
+// positive on the account's normal side.
+type LedgerAccountBalance struct {
+	models.LedgerAccount
+	Debits  float64 `json:"debits"`
+	Credits float64 `json:"credits"`
+	Balance float64 `json:"balance"`
+}
+
+type TrialBalanceRow struct {
+	Account models.LedgerAccount `json:"account"`
+	Debit   float64              `json:"debit"`  // net debit balance
+	Credit  float64              `json:"credit"` // net credit balance
+}
+
+// CurrencyTotals are the debits and credits of the accounts in one currency.
+// Amounts in different currencies are never added up.
+type CurrencyTotals struct {
+	Currency     string  `json:"currency"`
+	TotalDebits  float64 `json:"total_debits"`
+	TotalCredits float64 `json:"total_credits"`
+	Balanced     bool    `json:"balanced"`
+}
+
+type TrialBalanceResponse struct {
+	AsOf     *time.Time        `json:"as_of"`
+	Rows     []TrialBalanceRow `json:"rows"`
+	Totals   []CurrencyTotals  `json:"totals"`   // per currency
+	Balanced bool              `json:"balanced"` // every currency balances
+}
+
+// LedgerCheckResponse reports violations of the ledger invariants
+type LedgerCheckResponse struct {
+	OK                bool                       `json:"ok"`
+	Totals            []CurrencyTotals           `json:"totals"` // per currency
+	UnbalancedEntries []repositories.EntryTotals `json:"unbalanced_entries"`
+	InvalidPostings   int64                      `json:"invalid_postings"`
+	UnsyncedPayments  []uuid.UUID                `json:"unsynced_payments"` // postings differ from the payment's current state
+}
+
+type LedgerSyncResponse struct {
+	Synced []uuid.UUID `json:"synced"`
+}
+
+// toCents converts an amount to whole cents so that sums are exact
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+// accountBalances maps account IDs to net amounts in cents, debits positive
+type accountBalances map[uuid.UUID]int64
+
+func (b accountBalances) add(accountID uuid.UUID, cents int64) {
+	if cents == 0 {
+		return
+	}
+	b[accountID] += cents
+	if b[accountID] == 0 {
+		delete(b, accountID)
+	}
+}
+
+// currencyTotals sums debits and credits in cents per currency
+type currencyTotals map[string]*[2]int64
+
+func (t currencyTotals) add(currency string, debits, credits int64) {
+	if t[currency] == nil {
+		t[currency] = &[2]int64{}
+	}
+	t[currency][0] += debits
+	t[currency][1] += credits
+}
+
+// list returns the totals sorted by currency and whether every currency balances
+func (t currencyTotals) list() ([]CurrencyTotals, bool) {
+	result := make([]CurrencyTotals, 0, len(t))
+	balanced := true
+	for currency, sums := range t {
+		result = append(result, CurrencyTotals{
+			Currency:     currency,
+			TotalDebits:  fromCents(sums[0]),
+			TotalCredits: fromCents(sums[1]),
+			Balanced:     sums[0] == sums[1],
+		})
+		balanced = balanced && sums[0] == sums[1]
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Currency < result[j].Currency })
+	return result, balanced
+}
+
+// inCurrency returns the template of the account that holds amounts in
+// currency. Accounts in the default currency keep the template's code; the
+// others get the currency as a suffix.
+func inCurrency(template models.LedgerAccount, currency string) models.LedgerAccount {
+	template.Currency = currency
+	if currency != models.DefaultCurrency {
+		template.Code += "-" + currency
+		template.Name += " (" + currency + ")"
+	}
+	return template
+}
+
+// ensureAccount returns the user's account with the template's code, creating it if needed
+func (s *LedgerService) ensureAccount(repo *repositories.LedgerRepository, userID uuid.UUID, template models.LedgerAccount) (*models.LedgerAccount, error) {
+	account, err := repo.FindAccountByCode(userID, template.Code)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	template.ID = uuid.Nil
+	template.UserID = userID
+	if _, err := repo.CreateAccount(&template); err != nil {
+		return nil, err
+	}
+	return repo.FindAccountByCode(userID, template.Code)
+}
+
+func (s *LedgerService) ensureSystemAccounts(repo *repositories.LedgerRepository, userID uuid.UUID) error {
+	for _, template := range systemAccounts {
+		if _, err := s.ensureAccount(repo, userID, template); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountResolver returns the ID of the user's account with the template's code
+type accountResolver func(template models.LedgerAccount) (uuid.UUID, error)
+
+// creatingResolver resolves accounts, creating the missing ones
+func (s *LedgerService) creatingResolver(repo *repositories.LedgerRepository, userID uuid.UUID) accountResolver {
+	return func(template models.LedgerAccount) (uuid.UUID, error) {
+		account, err := s.ensureAccount(repo, userID, template)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return account.ID, nil
+	}
+}
+
+// lookupResolver resolves accounts without creating them. A missing account
+// gets a fresh ID, which nothing can have been posted to.
+func (s *LedgerService) lookupResolver(userID uuid.UUID) accountResolver {
+	return func(template models.LedgerAccount) (uuid.UUID, error) {
+		account, err := s.ledgerRepo.FindAccountByCode(userID, template.Code)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.New(), nil
+		}
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return account.ID, nil
+	}
+}
+
+// targetBalances returns what the ledger should hold for a payment in its
+// current state. Only completed and refunded payments move money; the fee of
+// a refunded payment is not returned.
+func (s *LedgerService) targetBalances(payment *models.Payment, resolve accountResolver) (accountBalances, error) {
+	target := accountBalances{}
+	if payment.Status != "completed" && payment.Status != "refunded" {
+		return target, nil
+	}
+
+	cash, err := resolve(paymentCashAccount(payment))
+	if err != nil {
+		return nil, err
+	}
+
+	currency := payment.Currency()
+	if fee := toCents(payment.Fee); fee != 0 {
+		fees, err := resolve(inCurrency(feesAccount, currency))
+		if err != nil {
+			return nil, err
+		}
+		target.add(fees, fee)
+		target.add(cash, -fee)
+	}
+
+	if payment.Status == "completed" {
+		counterpart, err := resolve(inCurrency(paymentCounterpartAccount(payment), currency))
+		if err != nil {
+			return nil, err
+		}
+		amount := toCents(payment.Amount)
+		if payment.Direction == models.PaymentDirectionIncome {
+			amount = -amount
+		}
+		target.add(counterpart, amount)
+		target.add(cash, -amount)
+	}
+
+	return target, nil
+}
+
+// paymentCounterpartAccount is the account on the other side of a payment's
+// cash account: the income or expense account of its category, or the
+// clearing account for transfers to untracked accounts
+func paymentCounterpartAccount(payment *models.Payment) models.LedgerAccount {
+	categoryID := payment.CategoryID
+	switch payment.Direction {
+	case models.PaymentDirectionTransfer:
+		return clearingAccount
+	case models.PaymentDirectionIncome:
+		return models.LedgerAccount{
+			Code:       incomeAccountPrefix + strings.ToUpper(payment.CategoryID.String()[:8]),
+			Name:       "Income - " + payment.Category.Name,
+			Type:       models.AccountTypeIncome,
+			CategoryID: &categoryID,
+			IsSystem:   true,
+		}
+	default:
+		return models.LedgerAccount{
+			Code:       expenseAccountPrefix + strings.ToUpper(payment.CategoryID.String()[:8]),
+			Name:       "Expense - " + payment.Category.Name,
+			Type:       models.AccountTypeExpense,
+			CategoryID: &categoryID,
+			IsSystem:   true,
+		}
+	}
+}
+
+// paymentCashAccount is the account a payment is paid from or into: its
+// financial account, or the cash account of its payment method if it has
+// none, which holds the default currency
+func paymentCashAccount(payment *models.Payment) models.LedgerAccount {
+	if payment.AccountID != nil && payment.Account != nil {
+		return financialLedgerAccount(payment.Account)
+	}
+
+	paymentMethodID := payment.PaymentMethodID
+	return models.LedgerAccount{
+		Code:            cashAccountPrefix + strings.ToUpper(payment.PaymentMethod.Code),
+		Name:            "Cash - " + payment.PaymentMethod.Name,
+		Type:            models.AccountTypeAsset,
+		PaymentMethodID: &paymentMethodID,
+		Currency:        models.DefaultCurrency,
+		IsSystem:        true,
+	}
+}
+
+// financialLedgerAccount is the ledger account mirroring a financial account
+func financialLedgerAccount(account *models.FinancialAccount) models.LedgerAccount {
+	accountID := account.ID
+	return models.LedgerAccount{
+		Code:      accountPrefix + strings.ToUpper(account.ID.String()[:8]),
+		Name:      "Account - " + account.Name,
+		Type:      models.AccountTypeAsset,
+		AccountID: &accountID,
+		Currency:  account.Currency,
+		IsSystem:  true,
+	}
+}
+
+// postedBalances returns what was posted for one record
+func (s *LedgerService) postedBalances(repo *repositories.LedgerRepository, source string, sourceID uuid.UUID) (accountBalances, error) {
+	rows, err := repo.SourceBalances(source, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	posted := accountBalances{}
+	for _, row := range rows {
+		posted.add(row.AccountID, toCents(row.Net))
+	}
+	return posted, nil
+}
+
+// paymentBalances groups the postings made for payments by payment
+func (s *LedgerService) paymentBalances(userID uuid.UUID) (map[uuid.UUID]accountBalances, error) {
+	rows, err := s.ledgerRepo.PaymentBalances(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[uuid.UUID]accountBalances)
+	for _, row := range rows {
+		if balances[row.PaymentID] == nil {
+			balances[row.PaymentID] = accountBalances{}
+		}
+		balances[row.PaymentID].add(row.AccountID, toCents(row.Net))
+	}
+	return balances, nil
+}
+
+// PostPayment brings the ledger in line with the current state of a payment
+// by posting the difference between what it should hold and what was posted
+// before. It must run in the transaction that changed the payment and is a
+// no-op when nothing changed.
+func (s *LedgerService) PostPayment(tx *gorm.DB, payment *models.Payment) error {
+	repo := s.ledgerRepo.WithTx(tx)
+	target, err := s.targetBalances(payment, s.creatingResolver(repo, payment.UserID))
+	if err != nil {
+		return err
+	}
+	return s.postPayment(repo, payment, target)
+}
+
+// ReversePayment reverses everything posted for a payment that is being deleted
+func (s *LedgerService) ReversePayment(tx *gorm.DB, payment *models.Payment) error {
+	return s.postPayment(s.ledgerRepo.WithTx(tx), payment, accountBalances{})
+}
+
+func (s *LedgerService) postPayment(repo *repositories.LedgerRepository, payment *models.Payment, target accountBalances) error {
+	posted, err := s.postedBalances(repo, repositories.EntrySourcePayment, payment.ID)
+	if err != nil {
+		return err
+	}
+
+	kind := journalKind(models.JournalKindPayment, len(posted) > 0, len(target) > 0, payment.Status == "refunded")
+	entryDate := time.Now()
+	if kind == models.JournalKindPayment {
+		entryDate = payment.TransactionDate
+	}
+
+	paymentID := payment.ID
+	return s.postDifference(repo, &models.JournalEntry{
+		UserID:      payment.UserID,
+		PaymentID:   &paymentID,
+		Kind:        kind,
+		Description: fmt.Sprintf("%s: %s", journalKindLabels[kind], payment.Description),
+		EntryDate:   entryDate,
+	}, posted, target)
+}
+
+// PostTransfer posts a transfer between two financial accounts
+func (s *LedgerService) PostTransfer(tx *gorm.DB, transfer *models.Transfer) error {
+	repo := s.ledgerRepo.WithTx(tx)
+	resolve := s.creatingResolver(repo, transfer.UserID)
+
+	from, err := resolve(financialLedgerAccount(&transfer.FromAccount))
+	if err != nil {
+		return err
+	}
+	to, err := resolve(financialLedgerAccount(&transfer.ToAccount))
+	if err != nil {
+		return err
+	}
+
+	target := accountBalances{}
+	amount := toCents(transfer.Amount)
+	target.add(to, amount)
+	target.add(from, -amount)
+	return s.postTransfer(repo, transfer, target)
+}
+
+// ReverseTransfer reverses a transfer that is being deleted
+func (s *LedgerService) ReverseTransfer(tx *gorm.DB, transfer *models.Transfer) error {
+	return s.postTransfer(s.ledgerRepo.WithTx(tx), transfer, accountBalances{})
+}
+
+func (s *LedgerService) postTransfer(repo *repositories.LedgerRepository, transfer *models.Transfer, target accountBalances) error {
+	posted, err := s.postedBalances(repo, repositories.EntrySourceTransfer, transfer.ID)
+	if err != nil {
+		return err
+	}
+
+	kind := journalKind(models.JournalKindTransfer, len(posted) > 0, len(target) > 0, false)
+	entryDate := time.Now()
+	if kind == models.JournalKindTransfer {
+		entryDate = transfer.TransferDate
+	}
+
+	transferID := transfer.ID
+	return s.postDifference(repo, &models.JournalEntry{
+		UserID:      transfer.UserID,
+		TransferID:  &transferID,
+		Kind:        kind,
+		Description: fmt.Sprintf("%s: %s to %s", journalKindLabels[kind], transfer.FromAccount.Name, transfer.ToAccount.Name),
+		EntryDate:   entryDate,
+	}, posted, target)
+}
+
+// PostOpeningBalance posts the opening balance of a financial account against
+// opening balance equity, adjusting it when it was changed
+func (s *LedgerService) PostOpeningBalance(tx *gorm.DB, account *models.FinancialAccount) error {
+	repo := s.ledgerRepo.WithTx(tx)
+	resolve := s.creatingResolver(repo, account.UserID)
+
+	target := accountBalances{}
+	if amount := toCents(account.OpeningBalance); amount != 0 {
+		cash, err := resolve(financialLedgerAccount(account))
+		if err != nil {
+			return err
+		}
+		equity, err := resolve(inCurrency(equityAccount, account.Currency))
+		if err != nil {
+			return err
+		}
+		target.add(cash, amount)
+		target.add(equity, -amount)
+	}
+	return s.postOpeningBalance(repo, account, target)
+}
+
+// ReverseOpeningBalance reverses the opening balance of an account being deleted
+func (s *LedgerService) ReverseOpeningBalance(tx *gorm.DB, account *models.FinancialAccount) error {
+	return s.postOpeningBalance(s.ledgerRepo.WithTx(tx), account, accountBalances{})
+}
+
+func (s *LedgerService) postOpeningBalance(repo *repositories.LedgerRepository, account *models.FinancialAccount, target accountBalances) error {
+	posted, err := s.postedBalances(repo, repositories.EntrySourceAccount, account.ID)
+	if err != nil {
+		return err
+	}
+
+	kind := journalKind(models.JournalKindOpening, len(posted) > 0, len(target) > 0, false)
+	accountID := account.ID
+	return s.postDifference(repo, &models.JournalEntry{
+		UserID:      account.UserID,
+		AccountID:   &accountID,
+		Kind:        kind,
+		Description: fmt.Sprintf("%s: %s", journalKindLabels[kind], account.Name),
+		EntryDate:   time.Now(),
+	}, posted, target)
+}
+
+// postDifference creates entry with the postings that move what was posted
+// to target. Nothing is posted when they already match.
+func (s *LedgerService) postDifference(repo *repositories.LedgerRepository, entry *models.JournalEntry, posted, target accountBalances) error {
+	delta := accountBalances{}
+	for accountID, cents := range target {
+		delta.add(accountID, cents)
+	}
+	for accountID, cents := range posted {
+		delta.add(accountID, -cents)
+	}
+	if len(delta) == 0 {
+		return nil
+	}
+
+	entry.Postings = postingsFor(delta)
+	return repo.CreateEntry(entry)
+}
+
+var journalKindLabels = map[string]string{
+	models.JournalKindPayment:    "Payment",
+	models.JournalKindRefund:     "Refund",
+	models.JournalKindReversal:   "Reversal",
+	models.JournalKindAdjustment: "Adjustment",
+	models.JournalKindTransfer:   "Transfer",
+	models.JournalKindOpening:    "Opening balance",
+}
+
+// journalKind names the entry that moves a record's postings from what was
+// posted to its current state. initial is the kind of the first entry.
+func journalKind(initial string, hasPosted, hasTarget, refunded bool) string {
+	switch {
+	case !hasPosted:
+		return initial
+	case !hasTarget:
+		return models.JournalKindReversal
+	case refunded:
+		return models.JournalKindRefund
+	default:
+		return models.JournalKindAdjustment
+	}
+}
+
+// postingsFor turns net amounts into postings ordered debits first
+func postingsFor(balances accountBalances) []models.Posting {
+	postings := make([]models.Posting, 0, len(balances))
+	for accountID, cents := range balances {
+		posting := models.Posting{AccountID: accountID}
+		if cents > 0 {
+			posting.Debit = fromCents(cents)
+		} else {
+			posting.Credit = fromCents(-cents)
+		}
+		postings = append(postings, posting)
+	}
+	sort.Slice(postings, func(i, j int) bool {
+		if (postings[i].Debit > 0) != (postings[j].Debit > 0) {
+			return postings[i].Debit > 0
+		}
+		return postings[i].AccountID.String() < postings[j].AccountID.String()
+	})
+	return postings
+}
+
+// GetAccounts lists the user's accounts with their balances
+func (s *LedgerService) GetAccounts(userID uuid.UUID) ([]LedgerAccountBalance, error) {
+	if err := s.ensureSystemAccounts(s.ledgerRepo, userID); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.ledgerRepo.FindAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.accountTotals(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]LedgerAccountBalance, 0, len(accounts))
+	for _, account := range accounts {
+		t := totals[account.ID]
+		balance := toCents(t.Debits) - toCents(t.Credits)
+		if !account.DebitNormal() {
+			balance = -balance
+		}
+		result = append(result, LedgerAccountBalance{
+			LedgerAccount: account,
+			Debits:        t.Debits,
+			Credits:       t.Credits,
+			Balance:       fromCents(balance),
+		})
+	}
+	return result, nil
+}
+
+func (s *LedgerService) accountTotals(userID uuid.UUID, asOf *time.Time) (map[uuid.UUID]repositories.AccountTotals, error) {
+	rows, err := s.ledgerRepo.AccountTotals(userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	totals := make(map[uuid.UUID]repositories.AccountTotals, len(rows))
+	for _, row := range rows {
+		totals[row.AccountID] = row
+	}
+	return totals, nil
+}
+
+// CreateAccount adds a user-defined account, e.g. for manual entries
+func (s *LedgerService) CreateAccount(userID uuid.UUID, req *CreateLedgerAccountRequest) (*models.LedgerAccount, error) {
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	for _, prefix := range []string{accountPrefix, cashAccountPrefix, expenseAccountPrefix, incomeAccountPrefix} {
+		if strings.HasPrefix(code, prefix) {
+			return nil, ErrReservedAccountCode
+		}
+	}
+	for _, system := range systemAccounts {
+		if code == system.Code || strings.HasPrefix(code, system.Code+"-") {
+			return nil, ErrReservedAccountCode
+		}
+	}
+
+	switch req.Type {
+	case models.AccountTypeAsset, models.AccountTypeLiability, models.AccountTypeEquity,
+		models.AccountTypeIncome, models.AccountTypeExpense:
+	default:
+		return nil, ErrInvalidAccountType
+	}
+
+	currency := models.DefaultCurrency
+	if req.Currency != "" {
+		var err error
+		if currency, err = normalizeCurrency(req.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	account := &models.LedgerAccount{
+		UserID:   userID,
+		Code:     code,
+		Name:     req.Name,
+		Type:     req.Type,
+		Currency: currency,
+	}
+	created, err := s.ledgerRepo.CreateAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrAccountCodeTaken
+	}
+	return account, nil
+}
+
+// CreateEntry posts a manual journal entry, which must balance and post to
+// accounts in a single currency
+func (s *LedgerService) CreateEntry(userID uuid.UUID, req *CreateJournalEntryRequest) (*models.JournalEntry, error) {
+	var debits, credits int64
+	var currency string
+	postings := make([]models.Posting, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		debit, credit := toCents(line.Debit), toCents(line.Credit)
+		if debit < 0 || credit < 0 || (debit > 0) == (credit > 0) {
+			return nil, ErrInvalidPosting
+		}
+
+		account, err := s.ledgerRepo.FindAccountByID(line.AccountID)
+		if err != nil || account.UserID != userID {
+			if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrLedgerAccountNotFound, line.AccountID)
+			}
+			return nil, err
+		}
+		if currency == "" {
+			currency = account.Currency
+		} else if account.Currency != currency {
+			return nil, ErrMixedCurrencyEntry
+		}
+
+		debits += debit
+		credits += credit
+		postings = append(postings, models.Posting{
+			AccountID: line.AccountID,
+			Debit:     fromCents(debit),
+			Credit:    fromCents(credit),
+		})
+	}
+	if len(postings) < 2 || debits != credits {
+		return nil, ErrUnbalancedEntry
+	}
+
+	entryDate := req.EntryDate
+	if entryDate.IsZero() {
+		entryDate = time.Now()
+	}
+
+	entry := &models.JournalEntry{
+		UserID:      userID,
+		Kind:        models.JournalKindManual,
+		Description: req.Description,
+		EntryDate:   entryDate,
+		Postings:    postings,
+	}
+	if err := s.ledgerRepo.CreateEntry(entry); err != nil {
+		return nil, err
+	}
+	return s.ledgerRepo.FindEntryByID(entry.ID)
+}
+
+func (s *LedgerService) GetEntry(userID, id uuid.UUID) (*models.JournalEntry, error) {
+	entry, err := s.ledgerRepo.FindEntryByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJournalEntryNotFound
+		}
+		return nil, err
+	}
+	if entry.UserID != userID {
+		return nil, ErrJournalEntryNotFound
+	}
+	return entry, nil
+}
+
+func (s *LedgerService) GetEntries(userID uuid.UUID, page, limit int, filter repositories.EntryFilter) (*JournalEntryListResponse, error) {
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	entries, total, err := s.ledgerRepo.FindEntries(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournalEntryListResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	}, nil
+}
+
+// GetTrialBalance lists the net balance of every account with postings,
+// optionally as of the end of a given day
+func (s *LedgerService) GetTrialBalance(userID uuid.UUID, asOf *time.Time) (*TrialBalanceResponse, error) {
+	accounts, err := s.ledgerRepo.FindAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.accountTotals(userID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TrialBalanceResponse{AsOf: asOf, Rows: []TrialBalanceRow{}}
+	sums := currencyTotals{}
+	for _, account := range accounts {
+		t, ok := totals[account.ID]
+		if !ok {
+			continue
+		}
+
+		row := TrialBalanceRow{Account: account}
+		net := toCents(t.Debits) - toCents(t.Credits)
+		if net >= 0 {
+			row.Debit = fromCents(net)
+			sums.add(account.Currency, net, 0)
+		} else {
+			row.Credit = fromCents(-net)
+			sums.add(account.Currency, 0, -net)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	result.Totals, result.Balanced = sums.list()
+	return result, nil
+}
+
+// Check verifies the ledger invariants: every entry balances, postings are
+// one-sided, total debits equal total credits in every currency and every
+// payment's postings match its current state
+func (s *LedgerService) Check(userID uuid.UUID) (*LedgerCheckResponse, error) {
+	result := &LedgerCheckResponse{UnsyncedPayments: []uuid.UUID{}}
+
+	accounts, err := s.ledgerRepo.FindAccounts(userID)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.accountTotals(userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	sums := currencyTotals{}
+	for _, account := range accounts {
+		if t, ok := totals[account.ID]; ok {
+			sums.add(account.Currency, toCents(t.Debits), toCents(t.Credits))
+		}
+	}
+	var balanced bool
+	result.Totals, balanced = sums.list()
+
+	if result.UnbalancedEntries, err = s.ledgerRepo.FindUnbalancedEntries(userID); err != nil {
+		return nil, err
+	}
+	if result.UnbalancedEntries == nil {
+		result.UnbalancedEntries = []repositories.EntryTotals{}
+	}
+
+	if result.InvalidPostings, err = s.ledgerRepo.CountInvalidPostings(userID); err != nil {
+		return nil, err
+	}
+
+	unsynced, err := s.unsyncedPayments(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range unsynced {
+		result.UnsyncedPayments = append(result.UnsyncedPayments, payment.ID)
+	}
+
+	result.OK = balanced && len(result.UnbalancedEntries) == 0 &&
+		result.InvalidPostings == 0 && len(result.UnsyncedPayments) == 0
+	return result, nil
+}
+
+// unsyncedPayments returns the payments whose postings differ from their
+// current state, including deleted payments that still have postings
+func (s *LedgerService) unsyncedPayments(userID uuid.UUID) ([]models.Payment, error) {
+	payments, _, err := s.paymentRepo.FindAll(userID, repositories.PaymentFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.paymentBalances(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolve := s.lookupResolver(userID)
+	var unsynced []models.Payment
+	for i := range payments {
+		payment := &payments[i]
+		posted := current[payment.ID]
+		delete(current, payment.ID)
+
+		target, err := s.targetBalances(payment, resolve)
+		if err != nil {
+			return nil, err
+		}
+		if !balancesEqual(target, posted) {
+			unsynced = append(unsynced, *payment)
+		}
+	}
+
+	// Whatever is left belongs to payments that no longer exist
+	for paymentID, posted := range current {
+		if len(posted) > 0 {
+			unsynced = append(unsynced, models.Payment{ID: paymentID, UserID: userID, Status: "deleted"})
+		}
+	}
+	return unsynced, nil
+}
+
+func balancesEqual(a, b accountBalances) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for accountID, cents := range a {
+		if b[accountID] != cents {
+			return false
+		}
+	}
+	return true
+}
+
+// SyncPayments posts whatever is needed to bring every payment of the user in
+// line with the ledger, e.g. for payments completed before the ledger existed
+func (s *LedgerService) SyncPayments(userID uuid.UUID) (*LedgerSyncResponse, error) {
+	unsynced, err := s.unsyncedPayments(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LedgerSyncResponse{Synced: []uuid.UUID{}}
+	for i := range unsynced {
+		payment := &unsynced[i]
+		err := s.ledgerRepo.Transaction(func(tx *gorm.DB) error {
+			if payment.Status == "deleted" {
+				return s.ReversePayment(tx, payment)
+			}
+			return s.PostPayment(tx, payment)
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Synced = append(result.Synced, payment.ID)
+	}
+	return result, nil
+}
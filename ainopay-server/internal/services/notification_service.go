@@ -0,0 +1,273 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrInvalidNotificationType = errors.New("invalid notification type")
+)
+
+// NotificationService keeps the users' notification centers and emails
+// notifications to users who want them by email. Notifications are raised by
+// payment events relayed from the outbox.
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+	userRepo         *repositories.UserRepository
+	emailService     *EmailService
+}
+
+func NewNotificationService(notificationRepo *repositories.NotificationRepository, userRepo *repositories.UserRepository, emailService *EmailService) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo, userRepo: userRepo, emailService: emailService}
+}
+
+type NotificationListResponse struct {
+	Notifications []models.Notification `json:"notifications"`
+	Total         int64                 `json:"total"`
+	Page          int                   `json:"page"`
+	Limit         int                   `json:"limit"`
+}
+
+// GetAll returns a page of the user's notifications, newest first
+func (s *NotificationService) GetAll(userID uuid.UUID, unreadOnly bool, page, limit int) (*NotificationListResponse, error) {
+	notifications, total, err := s.notificationRepo.FindByUserID(userID, unreadOnly, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NotificationListResponse{
+		Notifications: notifications,
+		Total:         total,
+		Page:          page,
+		Limit:         limit,
+	}, nil
+}
+
+func (s *NotificationService) UnreadCount(userID uuid.UUID) (int64, error) {
+	return s.notificationRepo.CountUnread(userID)
+}
+
+// MarkRead marks a notification of the user as read
+func (s *NotificationService) MarkRead(userID, id uuid.UUID) (*models.Notification, error) {
+	notification, err := s.notificationRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && notification.UserID != userID) {
+		return nil, ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if notification.ReadAt != nil {
+		return notification, nil
+	}
+
+	now := time.Now()
+	if err := s.notificationRepo.MarkRead(id, now); err != nil {
+		return nil, err
+	}
+	notification.ReadAt = &now
+	return notification, nil
+}
+
+// MarkAllRead marks all of the user's notifications as read and returns how
+// many were unread
+func (s *NotificationService) MarkAllRead(userID uuid.UUID) (int64, error) {
+	return s.notificationRepo.MarkAllRead(userID, time.Now())
+}
+
+// GetPreferences returns the user's channels for every notification type
+func (s *NotificationService) GetPreferences(userID uuid.UUID) ([]models.NotificationPreference, error) {
+	saved, err := s.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preference := models.DefaultNotificationPreference(notificationType)
+		for _, p := range saved {
+			if p.Type == notificationType {
+				preference = p
+			}
+		}
+		preference.UserID = userID
+		preferences = append(preferences, preference)
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences sets the user's channels for the given notification
+// types, leaving the other types as they are
+func (s *NotificationService) UpdatePreferences(userID uuid.UUID, preferences []models.NotificationPreference) ([]models.NotificationPreference, error) {
+	for i := range preferences {
+		if !slices.Contains(models.NotificationTypes, preferences[i].Type) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidNotificationType, preferences[i].Type)
+		}
+		preferences[i].UserID = userID
+	}
+
+	if err := s.notificationRepo.SavePreferences(preferences); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(userID)
+}
+
+// preference returns the user's channels for a notification type
+func (s *NotificationService) preference(userID uuid.UUID, notificationType string) (models.NotificationPreference, error) {
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		return models.NotificationPreference{}, err
+	}
+	for _, p := range preferences {
+		if p.Type == notificationType {
+			return p, nil
+		}
+	}
+	return models.DefaultNotificationPreference(notificationType), nil
+}
+
+// Notify delivers a notification to the user's notification center and by
+// email, as the user's preferences for its type say. A notification raised by
+// an event that was already delivered is not delivered again.
+func (s *NotificationService) Notify(user *models.User, notification *models.Notification) error {
+	preference, err := s.preference(user.ID, notification.Type)
+	if err != nil {
+		return err
+	}
+
+	notification.UserID = user.ID
+	if preference.InApp {
+		created, err := s.notificationRepo.Create(notification)
+		if err != nil || !created {
+			return err
+		}
+	}
+	if preference.Email {
+		return s.emailService.SendNotificationEmail(user.Email, notification)
+	}
+	return nil
+}
+
+// notifyAdmins delivers a copy of a notification to every admin
+func (s *NotificationService) notifyAdmins(notification models.Notification) error {
+	admins, err := s.userRepo.FindByRole("admin")
+	if err != nil {
+		return err
+	}
+	for i := range admins {
+		n := notification
+		if err := s.Notify(&admins[i], &n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifyOwner delivers a notification about a payment to its user
+func (s *NotificationService) notifyOwner(payment *models.Payment, notification models.Notification) error {
+	user, err := s.userRepo.FindByID(payment.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.Notify(user, &notification)
+}
+
+// paymentLabel describes a payment in notification messages
+func paymentLabel(payment *models.Payment) string {
+	label := fmt.Sprintf("%s of %s", payment.Direction, formatMoney(payment.Amount))
+	if payment.Description != "" {
+		label += fmt.Sprintf(" (%s)", payment.Description)
+	}
+	return label
+}
+
+// HandlePaymentCreated notifies the user of a payment that went over a
+// spending limit, and admins of a payment held for review
+func (s *NotificationService) HandlePaymentCreated(event *models.OutboxEvent) error {
+	var payment models.Payment
+	if err := json.Unmarshal([]byte(event.Payload), &payment); err != nil {
+		return err
+	}
+
+	if payment.OverLimit {
+		if err := s.notifyOwner(&payment, budgetExceeded(event, &payment)); err != nil {
+			return err
+		}
+	}
+
+	if payment.Status == "review" {
+		return s.notifyAdmins(approvalNeeded(event, &payment))
+	}
+	return nil
+}
+
+// HandlePaymentUpdated notifies the user of a payment that an update took
+// over a spending limit
+func (s *NotificationService) HandlePaymentUpdated(event *models.OutboxEvent) error {
+	var data PaymentUpdatedEvent
+	if err := json.Unmarshal([]byte(event.Payload), &data); err != nil {
+		return err
+	}
+	if data.Payment == nil || !data.OverLimit || data.PreviousOverLimit {
+		return nil
+	}
+	return s.notifyOwner(data.Payment, budgetExceeded(event, data.Payment))
+}
+
+// HandlePaymentStatusChanged notifies the user of a payment that failed, and
+// admins of a payment held for review
+func (s *NotificationService) HandlePaymentStatusChanged(event *models.OutboxEvent) error {
+	var data PaymentStatusChangedEvent
+	if err := json.Unmarshal([]byte(event.Payload), &data); err != nil {
+		return err
+	}
+	if data.Payment == nil {
+		return nil
+	}
+
+	switch data.Payment.Status {
+	case "failed":
+		return s.notifyOwner(data.Payment, models.Notification{
+			Type:      models.NotificationPaymentFailed,
+			EventID:   &event.ID,
+			Title:     "Payment failed",
+			Message:   fmt.Sprintf("Your %s failed.", paymentLabel(data.Payment)),
+			PaymentID: &data.Payment.ID,
+		})
+	case "review":
+		return s.notifyAdmins(approvalNeeded(event, data.Payment))
+	}
+	return nil
+}
+
+func budgetExceeded(event *models.OutboxEvent, payment *models.Payment) models.Notification {
+	return models.Notification{
+		Type:      models.NotificationBudgetExceeded,
+		EventID:   &event.ID,
+		Title:     "Spending limit exceeded",
+		Message:   fmt.Sprintf("Your %s went over a spending limit.", paymentLabel(payment)),
+		PaymentID: &payment.ID,
+	}
+}
+
+func approvalNeeded(event *models.OutboxEvent, payment *models.Payment) models.Notification {
+	return models.Notification{
+		Type:      models.NotificationApprovalNeeded,
+		EventID:   &event.ID,
+		Title:     "Payment needs approval",
+		Message:   fmt.Sprintf("The %s by %s scored %d on risk rules and is held for review.", paymentLabel(payment), payment.User.Email, payment.RiskScore),
+		PaymentID: &payment.ID,
+	}
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"ainopay-server/internal/config"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	outboxPollInterval    = 2 * time.Second
+	outboxBatchSize       = 100
+	outboxLease           = time.Minute
+	outboxMaxBackoff      = time.Hour
+	outboxRetention       = 7 * 24 * time.Hour
+	outboxCleanupInterval = time.Hour
+)
+
+// OutboxHandler reacts to a relayed event. Delivery is at least once, so
+// handlers must tolerate seeing the same event again.
+type OutboxHandler func(event *models.OutboxEvent) error
+
+// OutboxService records domain events inside the transaction of the change
+// that caused them and relays them to registered handlers once committed
+type OutboxService struct {
+	outboxRepo   *repositories.OutboxRepository
+	maxAttempts  int
+	retryBackoff time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string][]OutboxHandler
+	wake     chan struct{}
+}
+
+func NewOutboxService(outboxRepo *repositories.OutboxRepository, cfg *config.Config) *OutboxService {
+	maxAttempts, err := strconv.Atoi(cfg.Outbox.MaxAttempts)
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+
+	backoff, err := time.ParseDuration(cfg.Outbox.RetryBackoff)
+	if err != nil || backoff <= 0 {
+		backoff = 5 * time.Second
+	}
+
+	return &OutboxService{
+		outboxRepo:   outboxRepo,
+		maxAttempts:  maxAttempts,
+		retryBackoff: backoff,
+		handlers:     make(map[string][]OutboxHandler),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Subscribe registers a handler for an event type. Handlers run in the order
+// they were registered.
+func (s *OutboxService) Subscribe(eventType string, handler OutboxHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[eventType] = append(s.handlers[eventType], handler)
+}
+
+// Transaction runs fn in a database transaction and wakes the relay once it
+// has committed, so recorded events are handled without waiting for the next poll
+func (s *OutboxService) Transaction(fn func(tx *gorm.DB) error) error {
+	if err := s.outboxRepo.Transaction(fn); err != nil {
+		return err
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Record writes an event in tx. It must be called after the change to the
+// aggregate so that concurrent writers, serialised by the row lock, get
+// increasing sequence numbers.
+func (s *OutboxService) Record(tx *gorm.DB, aggregateType string, aggregateID, userID uuid.UUID, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return s.outboxRepo.WithTx(tx).Create(&models.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		UserID:        userID,
+		EventType:     eventType,
+		Payload:       string(payload),
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// StartRelay relays committed events in the background
+func (s *OutboxService) StartRelay() {
+	ticker := time.NewTicker(outboxPollInterval)
+	cleanup := time.NewTicker(outboxCleanupInterval)
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			case <-cleanup.C:
+				if _, err := s.outboxRepo.DeleteProcessedBefore(time.Now().Add(-outboxRetention)); err != nil {
+					log.Printf("Error cleaning up outbox events: %v", err)
+				}
+				continue
+			}
+			if err := s.ProcessPending(); err != nil {
+				log.Printf("Error relaying outbox events: %v", err)
+			}
+		}
+	}()
+}
+
+// ProcessPending relays every ready event, repeating until none are left so
+// that events queued behind a relayed one are not delayed by a poll interval
+func (s *OutboxService) ProcessPending() error {
+	for {
+		events, err := s.outboxRepo.FindReady(time.Now(), outboxBatchSize)
+		if err != nil {
+			return err
+		}
+
+		relayed := 0
+		for i := range events {
+			event := &events[i]
+			claimed, err := s.outboxRepo.Claim(event, outboxLease)
+			if err != nil {
+				return err
+			}
+			if !claimed {
+				continue
+			}
+			if err := s.relay(event); err != nil {
+				log.Printf("Error recording outbox event %s: %v", event.ID, err)
+				continue
+			}
+			if event.Status == models.OutboxStatusProcessed {
+				relayed++
+			}
+		}
+
+		if relayed == 0 {
+			return nil
+		}
+	}
+}
+
+// relay runs the handlers of an event and records the outcome
+func (s *OutboxService) relay(event *models.OutboxEvent) error {
+	s.mu.RLock()
+	handlers := s.handlers[event.EventType]
+	s.mu.RUnlock()
+
+	var handlerErr error
+	for _, handle := range handlers {
+		if handlerErr = s.safeHandle(handle, event); handlerErr != nil {
+			break
+		}
+	}
+
+	if handlerErr == nil {
+		event.Status = models.OutboxStatusProcessed
+		return s.outboxRepo.MarkProcessed(event.ID)
+	}
+
+	event.Attempts++
+	event.LastError = handlerErr.Error()
+	if event.Attempts >= s.maxAttempts {
+		event.Status = models.OutboxStatusDead
+		log.Printf("Outbox event %s (%s) failed %d times, giving up: %v", event.ID, event.EventType, event.Attempts, handlerErr)
+	} else {
+		event.NextAttemptAt = time.Now().Add(s.backoff(event.Attempts))
+	}
+	return s.outboxRepo.MarkFailed(event)
+}
+
+// safeHandle turns a handler panic into an error so one bad event cannot stop the relay
+func (s *OutboxService) safeHandle(handle OutboxHandler, event *models.OutboxEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handle(event)
+}
+
+// backoff returns the delay before the next attempt, doubling with every
+// failed attempt up to outboxMaxBackoff
+func (s *OutboxService) backoff(attempts int) time.Duration {
+	delay := s.retryBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= outboxMaxBackoff {
+			return outboxMaxBackoff
+		}
+	}
+	return delay
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/utils"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DuplicatePaymentError is returned when a new payment looks like one that
+// was already recorded. Callers can retry with Force to create it anyway.
+type DuplicatePaymentError struct {
+	CandidateIDs []uuid.UUID
+}
+
+func newDuplicatePaymentError(candidates []models.Payment) *DuplicatePaymentError {
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for _, p := range candidates {
+		ids = append(ids, p.ID)
+	}
+	return &DuplicatePaymentError{CandidateIDs: ids}
+}
+
+func (e *DuplicatePaymentError) Error() string {
+	return "possible duplicate payment"
+}
+
+// DuplicateGroup is a set of existing payments that look like the same payment
+type DuplicateGroup struct {
+	Payments []models.Payment `json:"payments"`
+}
+
+// findDuplicates returns payments with the same amount, direction, method and
+// category inside the duplicate window whose description is similar enough
+func (s *PaymentService) findDuplicates(tx *gorm.DB, userID, excludeID uuid.UUID, amount float64, direction string, methodID, categoryID uuid.UUID, description string, date time.Time) ([]models.Payment, error) {
+	minAmount := amount - 0.005
+	maxAmount := amount + 0.005
+	start := date.Add(-s.duplicateWindow)
+	end := date.Add(s.duplicateWindow)
+
+	payments, _, err := s.paymentRepo.WithTx(tx).FindAll(userID, repositories.PaymentFilter{
+		MinAmount:       &minAmount,
+		MaxAmount:       &maxAmount,
+		StartDate:       &start,
+		EndDate:         &end,
+		Direction:       direction,
+		PaymentMethodID: &methodID,
+		CategoryID:      &categoryID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []models.Payment
+	for _, p := range payments {
+		if p.ID == excludeID || p.Status == "failed" {
+			continue
+		}
+		if utils.DescriptionSimilarity(p.Description, description) >= s.duplicateSimilarity {
+			candidates = append(candidates, p)
+		}
+	}
+	return candidates, nil
+}
+
+// isDuplicatePair applies the duplicate rules to two existing payments
+func (s *PaymentService) isDuplicatePair(a, b *models.Payment) bool {
+	if a.PaymentMethodID != b.PaymentMethodID || a.CategoryID != b.CategoryID || a.Direction != b.Direction {
+		return false
+	}
+	if math.Abs(a.Amount-b.Amount) >= 0.005 {
+		return false
+	}
+	if a.TransactionDate.Sub(b.TransactionDate).Abs() > s.duplicateWindow {
+		return false
+	}
+	return utils.DescriptionSimilarity(a.Description, b.Description) >= s.duplicateSimilarity
+}
+
+// GetSuspectedDuplicates groups the user's existing payments that look like
+// duplicates of each other
+func (s *PaymentService) GetSuspectedDuplicates(userID uuid.UUID) ([]DuplicateGroup, error) {
+	payments, _, err := s.paymentRepo.FindAll(userID, repositories.PaymentFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only payments sharing method, category and amount can be duplicates,
+	// so compare within those buckets, ordered by date
+	sort.Slice(payments, func(i, j int) bool {
+		a, b := &payments[i], &payments[j]
+		if a.PaymentMethodID != b.PaymentMethodID {
+			return a.PaymentMethodID.String() < b.PaymentMethodID.String()
+		}
+		if a.CategoryID != b.CategoryID {
+			return a.CategoryID.String() < b.CategoryID.String()
+		}
+		if a.Amount != b.Amount {
+			return a.Amount < b.Amount
+		}
+		return a.TransactionDate.Before(b.TransactionDate)
+	})
+
+	// Union-find over the payments that match each other
+	parent := make([]int, len(payments))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range payments {
+		if payments[i].Status == "failed" {
+			continue
+		}
+		for j := i + 1; j < len(payments); j++ {
+			a, b := &payments[i], &payments[j]
+			if a.PaymentMethodID != b.PaymentMethodID || a.CategoryID != b.CategoryID ||
+				a.Amount != b.Amount || b.TransactionDate.Sub(a.TransactionDate) > s.duplicateWindow {
+				break
+			}
+			if b.Status != "failed" && s.isDuplicatePair(a, b) {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := map[int][]models.Payment{}
+	var order []int
+	for i := range payments {
+		root := find(i)
+		if _, seen := groups[root]; !seen {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], payments[i])
+	}
+
+	result := []DuplicateGroup{}
+	for _, root := range order {
+		if len(groups[root]) > 1 {
+			result = append(result, DuplicateGroup{Payments: groups[root]})
+		}
+	}
+	return result, nil
+}
@@ -1,39 +1,132 @@
 package services
 
 import (
+	"ainopay-server/internal/config"
 	"ainopay-server/internal/models"
 	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/statements"
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPaymentInReview    = errors.New("payment is held for review and cannot be changed until it is approved or rejected")
+	ErrPaymentNotInReview = errors.New("payment is not held for review")
+	ErrPaymentCharged     = errors.New("payment was charged through a provider; its status and amount cannot be changed")
+	ErrImportCurrency     = errors.New("currency does not match the account currency")
 )
 
 type PaymentService struct {
-	paymentRepo *repositories.PaymentRepository
+	paymentRepo           *repositories.PaymentRepository
+	outboxService         *OutboxService
+	ledgerService         *LedgerService
+	accountService        *AccountService
+	limitService          *SpendingLimitService
+	riskService           *RiskService
+	categorizationService *CategorizationService
+	taxService            *TaxService
+	duplicateWindow       time.Duration
+	duplicateSimilarity   float64
 }
 
-func NewPaymentService(paymentRepo *repositories.PaymentRepository) *PaymentService {
-	return &PaymentService{paymentRepo: paymentRepo}
+func NewPaymentService(
+	paymentRepo *repositories.PaymentRepository,
+	outboxService *OutboxService,
+	ledgerService *LedgerService,
+	accountService *AccountService,
+	limitService *SpendingLimitService,
+	riskService *RiskService,
+	categorizationService *CategorizationService,
+	taxService *TaxService,
+	cfg *config.Config,
+) *PaymentService {
+	window, err := time.ParseDuration(cfg.Payment.DuplicateWindow)
+	if err != nil || window <= 0 {
+		window = 72 * time.Hour
+	}
+
+	similarity, err := strconv.ParseFloat(cfg.Payment.DuplicateSimilarity, 64)
+	if err != nil || similarity < 0 || similarity > 1 {
+		similarity = 0.6
+	}
+
+	return &PaymentService{
+		paymentRepo:           paymentRepo,
+		outboxService:         outboxService,
+		ledgerService:         ledgerService,
+		accountService:        accountService,
+		limitService:          limitService,
+		riskService:           riskService,
+		categorizationService: categorizationService,
+		taxService:            taxService,
+		duplicateWindow:       window,
+		duplicateSimilarity:   similarity,
+	}
 }
 
 type CreatePaymentRequest struct {
-	Amount          float64   `json:"amount" binding:"required,gt=0"`
-	PaymentMethodID uuid.UUID `json:"payment_method_id" binding:"required"`
-	CategoryID      uuid.UUID `json:"category_id" binding:"required"`
-	Description     string    `json:"description"`
-	TransactionDate time.Time `json:"transaction_date" binding:"required"`
+	Amount            float64            `json:"amount" binding:"required,gt=0"`
+	Fee               float64            `json:"fee"`
+	Status            string             `json:"status,omitempty"`    // defaults to pending
+	Direction         string             `json:"direction,omitempty"` // defaults to expense
+	PaymentMethodID   uuid.UUID          `json:"payment_method_id" binding:"required"`
+	CategoryID        uuid.UUID          `json:"category_id"` // set by categorization rules when empty
+	DefaultCategoryID uuid.UUID          `json:"-"`           // used when neither CategoryID nor a rule sets the category
+	AccountID         *uuid.UUID         `json:"account_id"`  // defaults to the account linked to the payment method
+	Description       string             `json:"description"`
+	Payee             string             `json:"payee"` // set by categorization rules when empty
+	Tags              []string           `json:"tags"`  // tags of matching categorization rules are added
+	TransactionDate   time.Time          `json:"transaction_date" binding:"required"`
+	Tax               *PaymentTaxRequest `json:"tax"`   // no tax when nil
+	Force             bool               `json:"force"` // create even if it looks like a duplicate
 }
 
 type UpdatePaymentRequest struct {
-	Amount          float64   `json:"amount" binding:"required,gt=0"`
-	Status          string    `json:"status" binding:"required,oneof=pending completed failed refunded"`
-	PaymentMethodID uuid.UUID `json:"payment_method_id" binding:"required"`
-	CategoryID      uuid.UUID `json:"category_id" binding:"required"`
-	Description     string    `json:"description"`
-	TransactionDate time.Time `json:"transaction_date" binding:"required"`
+	Amount          float64            `json:"amount" binding:"required,gt=0"`
+	Fee             *float64           `json:"fee"` // unchanged when nil
+	Status          string             `json:"status" binding:"required,oneof=pending completed failed refunded"`
+	Direction       string             `json:"direction,omitempty"` // unchanged when empty
+	PaymentMethodID uuid.UUID          `json:"payment_method_id" binding:"required"`
+	CategoryID      uuid.UUID          `json:"category_id" binding:"required"`
+	AccountID       *uuid.UUID         `json:"account_id"` // unchanged when nil
+	Description     string             `json:"description"`
+	Payee           *string            `json:"payee"` // unchanged when nil
+	Tags            []string           `json:"tags"`  // unchanged when nil
+	TransactionDate time.Time          `json:"transaction_date" binding:"required"`
+	Tax             *PaymentTaxRequest `json:"tax"` // unchanged when nil, with the tax amount following the amount
+}
+
+// ImportPaymentsRequest carries parsed statement entries to record as payments
+type ImportPaymentsRequest struct {
+	Entries         []statements.Entry
+	PaymentMethodID uuid.UUID
+	CategoryID      uuid.UUID  // category of entries no categorization rule categorizes
+	AccountID       *uuid.UUID // account the statement belongs to
+	Currency        string     // currency of the statement; must match the account currency when set
+	Force           bool       // import entries that look like duplicates
+}
+
+// ImportSkippedEntry describes a statement entry that was not imported
+type ImportSkippedEntry struct {
+	Line        int              `json:"line"`
+	Reason      string           `json:"reason"`
+	DuplicateOf []uuid.UUID      `json:"duplicate_of,omitempty"`
+	Entry       statements.Entry `json:"entry"`
+}
+
+type ImportPaymentsResponse struct {
+	Imported []models.Payment     `json:"imported"`
+	Skipped  []ImportSkippedEntry `json:"skipped"`
 }
 
 type PaymentListResponse struct {
@@ -44,35 +137,283 @@ type PaymentListResponse struct {
 }
 
 func (s *PaymentService) Create(userID uuid.UUID, req *CreatePaymentRequest) (*models.Payment, error) {
+	var created *models.Payment
+	err := s.outboxService.Transaction(func(tx *gorm.DB) error {
+		var err error
+		created, err = s.create(tx, userID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// transaction runs fn in a transaction and relays the payment events it
+// records once it commits
+func (s *PaymentService) transaction(fn func(tx *gorm.DB) error) error {
+	return s.outboxService.Transaction(fn)
+}
+
+// create records a payment in tx
+func (s *PaymentService) create(tx *gorm.DB, userID uuid.UUID, req *CreatePaymentRequest) (*models.Payment, error) {
+	categorization, err := s.categorizationService.Categorize(userID, req.Description, req.Amount, req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryID := req.CategoryID
+	if categoryID == uuid.Nil && categorization.CategoryID != nil {
+		categoryID = *categorization.CategoryID
+	}
+	if categoryID == uuid.Nil {
+		categoryID = req.DefaultCategoryID
+	}
+	if categoryID == uuid.Nil {
+		return nil, ErrCategoryRequired
+	}
+
+	payee := strings.TrimSpace(req.Payee)
+	if payee == "" {
+		payee = categorization.Payee
+	}
+
+	if !req.Force {
+		candidates, err := s.findDuplicates(tx, userID, uuid.Nil, req.Amount, paymentDirection(req.Direction), req.PaymentMethodID, categoryID, req.Description, req.TransactionDate)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			return nil, newDuplicatePaymentError(candidates)
+		}
+	}
+
+	accountID, err := s.accountService.ResolveForPayment(userID, req.AccountID, req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "pending"
+	}
+
 	payment := &models.Payment{
 		UserID:          userID,
 		Amount:          req.Amount,
-		Status:          "pending",
+		Fee:             req.Fee,
+		Status:          status,
+		Direction:       paymentDirection(req.Direction),
 		PaymentMethodID: req.PaymentMethodID,
-		CategoryID:      req.CategoryID,
+		CategoryID:      categoryID,
+		AccountID:       accountID,
 		Description:     req.Description,
+		Payee:           payee,
+		Tags:            normalizeTags(slices.Concat(req.Tags, categorization.Tags)),
 		TransactionDate: req.TransactionDate,
 	}
+	if req.Tax != nil {
+		if err := s.taxService.ApplyTax(payment, req.Tax); err != nil {
+			return nil, err
+		}
+	}
 
-	if err := s.paymentRepo.Create(payment); err != nil {
+	overLimit, err := s.limitService.Check(tx, payment, nil)
+	if err != nil {
+		return nil, err
+	}
+	payment.OverLimit = overLimit
+
+	assessment, err := s.riskService.Assess(tx, payment)
+	if err != nil {
+		return nil, err
+	}
+	payment.RiskScore = assessment.Score
+	payment.RiskMatches = assessment.Matches
+	if assessment.Hold && (payment.Status == "pending" || payment.Status == "completed") {
+		payment.HeldStatus = payment.Status
+		payment.Status = "review"
+	}
+
+	paymentRepo := s.paymentRepo.WithTx(tx)
+	if err := paymentRepo.Create(payment); err != nil {
 		return nil, err
 	}
 
 	// Reload with relations
-	return s.paymentRepo.FindByID(payment.ID)
+	created, err := paymentRepo.FindByID(payment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ledgerService.PostPayment(tx, created); err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(tx, created, models.EventPaymentCreated, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// paymentDirection returns the direction to store for a requested direction,
+// which defaults to expense
+func paymentDirection(direction string) string {
+	if direction == "" {
+		return models.PaymentDirectionExpense
+	}
+	return direction
+}
+
+// PaymentStatusChangedEvent is the payload of payment.status_changed events
+type PaymentStatusChangedEvent struct {
+	Payment        *models.Payment `json:"payment"`
+	PreviousStatus string          `json:"previous_status"`
+}
+
+// PaymentUpdatedEvent is the payload of payment.updated events: the fields of
+// the payment, plus whether it was over a spending limit before the update
+type PaymentUpdatedEvent struct {
+	*models.Payment
+	PreviousOverLimit bool `json:"previous_over_limit"`
+}
+
+// recordEvent writes a payment event to the outbox in tx, so it is only
+// relayed if the change that caused it commits
+func (s *PaymentService) recordEvent(tx *gorm.DB, payment *models.Payment, eventType string, data interface{}) error {
+	return s.outboxService.Record(tx, models.AggregatePayment, payment.ID, payment.UserID, eventType, data)
+}
+
+// Import records the entries of a bank statement as payments: debits as
+// expenses and credits as income. Reversals are skipped because they undo an
+// entry rather than move money, and entries in another currency than the
+// account's because their amounts would be booked at face value. The import
+// runs in one transaction, so an error leaves none of the entries recorded.
+func (s *PaymentService) Import(userID uuid.UUID, req *ImportPaymentsRequest) (*ImportPaymentsResponse, error) {
+	account, err := s.accountService.resolveAccountForPayment(userID, req.AccountID, req.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := models.DefaultCurrency
+	var accountID *uuid.UUID
+	if account != nil {
+		currency = account.Currency
+		accountID = &account.ID
+	}
+	if req.Currency != "" && !strings.EqualFold(req.Currency, currency) {
+		return nil, fmt.Errorf("%w: %s is not %s", ErrImportCurrency, strings.ToUpper(req.Currency), currency)
+	}
+
+	result := &ImportPaymentsResponse{
+		Imported: []models.Payment{},
+		Skipped:  []ImportSkippedEntry{},
+	}
+
+	err = s.outboxService.Transaction(func(tx *gorm.DB) error {
+		for i, entry := range req.Entries {
+			skip := func(reason string) {
+				result.Skipped = append(result.Skipped, ImportSkippedEntry{Line: i + 1, Reason: reason, Entry: entry})
+			}
+
+			switch {
+			case entry.Reversal:
+				skip("reversal entry")
+				continue
+			case entry.Currency != "" && !strings.EqualFold(entry.Currency, currency):
+				skip(fmt.Sprintf("currency %s does not match the account currency %s", entry.Currency, currency))
+				continue
+			}
+
+			status := "completed"
+			if entry.Pending {
+				status = "pending"
+			}
+
+			description := entry.Description
+			if description == "" {
+				description = entry.Counterpart
+			}
+
+			direction := models.PaymentDirectionExpense
+			if !entry.IsDebit() {
+				direction = models.PaymentDirectionIncome
+			}
+
+			// Each entry gets a savepoint, so a skipped entry does not abort
+			// the import
+			var payment *models.Payment
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				var err error
+				payment, err = s.create(tx, userID, &CreatePaymentRequest{
+					Amount:            math.Abs(entry.Amount),
+					Status:            status,
+					Direction:         direction,
+					PaymentMethodID:   req.PaymentMethodID,
+					DefaultCategoryID: req.CategoryID,
+					AccountID:         accountID,
+					Description:       description,
+					TransactionDate:   entry.BookingDate,
+					Force:             req.Force,
+				})
+				return err
+			})
+			var duplicateErr *DuplicatePaymentError
+			if errors.As(err, &duplicateErr) {
+				result.Skipped = append(result.Skipped, ImportSkippedEntry{
+					Line:        i + 1,
+					Reason:      duplicateErr.Error(),
+					DuplicateOf: duplicateErr.CandidateIDs,
+					Entry:       entry,
+				})
+				continue
+			}
+			var limitErr *SpendingLimitError
+			if errors.As(err, &limitErr) {
+				skip(limitErr.Error())
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			result.Imported = append(result.Imported, *payment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
 }
 
 func (s *PaymentService) GetByID(id uuid.UUID) (*models.Payment, error) {
 	return s.paymentRepo.FindByID(id)
 }
 
-func (s *PaymentService) GetAll(userID uuid.UUID, page, limit int, status, search string, minAmount, maxAmount *float64, startDate, endDate *time.Time) (*PaymentListResponse, error) {
+// GetOwned returns a payment of the user
+func (s *PaymentService) GetOwned(userID, id uuid.UUID) (*models.Payment, error) {
+	payment, err := s.paymentRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	if payment.UserID != userID {
+		return nil, ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+func (s *PaymentService) GetAll(userID uuid.UUID, page, limit int, status, direction, search string, minAmount, maxAmount *float64, startDate, endDate *time.Time) (*PaymentListResponse, error) {
 	offset := (page - 1) * limit
 
 	filter := repositories.PaymentFilter{
 		Limit:     limit,
 		Offset:    offset,
 		Status:    status,
+		Direction: direction,
 		Search:    search,
 		MinAmount: minAmount,
 		MaxAmount: maxAmount,
@@ -98,38 +439,271 @@ func (s *PaymentService) Update(id uuid.UUID, req *UpdatePaymentRequest) (*model
 	if err != nil {
 		return nil, err
 	}
+	if err := checkUpdatable(payment, req); err != nil {
+		return nil, err
+	}
+
+	accountID := payment.AccountID
+	if req.AccountID != nil {
+		if accountID, err = s.accountService.ResolveForPayment(payment.UserID, req.AccountID, req.PaymentMethodID); err != nil {
+			return nil, err
+		}
+	}
+
+	previous := *payment
 
+	payment.AccountID = accountID
+	payment.Account = nil
 	payment.Amount = req.Amount
+	if req.Fee != nil {
+		payment.Fee = *req.Fee
+	}
 	payment.Status = req.Status
+	if req.Direction != "" {
+		payment.Direction = req.Direction
+	}
 	payment.PaymentMethodID = req.PaymentMethodID
 	payment.CategoryID = req.CategoryID
 	payment.Description = req.Description
+	if req.Payee != nil {
+		payment.Payee = strings.TrimSpace(*req.Payee)
+	}
+	if req.Tags != nil {
+		payment.Tags = normalizeTags(req.Tags)
+	}
 	payment.TransactionDate = req.TransactionDate
+	if req.Tax != nil {
+		if err := s.taxService.ApplyTax(payment, req.Tax); err != nil {
+			return nil, err
+		}
+	} else {
+		recomputeTax(payment, previous.Amount)
+	}
 
-	if err := s.paymentRepo.Update(payment); err != nil {
+	var updated *models.Payment
+	err = s.outboxService.Transaction(func(tx *gorm.DB) error {
+		paymentRepo := s.paymentRepo.WithTx(tx)
+
+		// A provider callback may have settled the payment since it was read
+		current, err := paymentRepo.LockByID(id)
+		if err != nil {
+			return err
+		}
+		if err := checkUpdatable(current, req); err != nil {
+			return err
+		}
+
+		overLimit, err := s.limitService.Check(tx, payment, &previous)
+		if err != nil {
+			return err
+		}
+		payment.OverLimit = overLimit
+
+		if err := paymentRepo.Update(payment); err != nil {
+			return err
+		}
+
+		updated, err = paymentRepo.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.ledgerService.PostPayment(tx, updated); err != nil {
+			return err
+		}
+
+		if err := s.recordEvent(tx, updated, models.EventPaymentUpdated, PaymentUpdatedEvent{
+			Payment:           updated,
+			PreviousOverLimit: previous.OverLimit,
+		}); err != nil {
+			return err
+		}
+		return s.recordStatusChange(tx, updated, previous.Status)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return s.paymentRepo.FindByID(id)
+	return updated, nil
 }
 
-func (s *PaymentService) Delete(id uuid.UUID) error {
-	return s.paymentRepo.Delete(id)
+// checkUpdatable reports whether req may be applied to payment. Payments held
+// for review cannot be changed, and the status and amount of a payment that
+// was charged through a provider are the provider's.
+func checkUpdatable(payment *models.Payment, req *UpdatePaymentRequest) error {
+	if payment.Status == "review" {
+		return ErrPaymentInReview
+	}
+	if payment.ProviderReference != "" &&
+		(req.Status != payment.Status || toCents(req.Amount) != toCents(payment.Amount)) {
+		return ErrPaymentCharged
+	}
+	return nil
 }
 
-func (s *PaymentService) GetStatistics(userID uuid.UUID) (map[string]interface{}, error) {
-	return s.paymentRepo.GetStatistics(userID)
+// recordStatusChange records a payment.status_changed event if the status changed
+func (s *PaymentService) recordStatusChange(tx *gorm.DB, payment *models.Payment, previousStatus string) error {
+	if payment.Status == previousStatus {
+		return nil
+	}
+	return s.recordEvent(tx, payment, models.EventPaymentStatusChanged, PaymentStatusChangedEvent{
+		Payment:        payment,
+		PreviousStatus: previousStatus,
+	})
 }
 
-func (s *PaymentService) GetMonthlyStats(userID uuid.UUID, year int) ([]models.MonthlyStats, error) {
-	return s.paymentRepo.GetMonthlyEarnings(userID, year)
+// statusTransition returns the status a payment moves to from its current
+// state, or an error when it cannot move
+type statusTransition func(current *models.Payment) (string, error)
+
+// toStatus is the transition to a fixed status
+func toStatus(status string) statusTransition {
+	return func(*models.Payment) (string, error) {
+		return status, nil
+	}
 }
 
-func (s *PaymentService) Export(userID uuid.UUID, status, search string, minAmount, maxAmount *float64, startDate, endDate *time.Time) ([]byte, error) {
+// applyStatus moves a payment to the status transition picks, storing any
+// extra fields in the same update, and records a status change event when
+// the status changed. The payment is locked while transition looks at it, so
+// concurrent changes, such as repeated provider callbacks, see each other's
+// result.
+func (s *PaymentService) applyStatus(id uuid.UUID, transition statusTransition, fields map[string]interface{}) (*models.Payment, error) {
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+
+	var updated *models.Payment
+	err := s.outboxService.Transaction(func(tx *gorm.DB) error {
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		current, err := paymentRepo.LockByID(id)
+		if err != nil {
+			return err
+		}
+		previousStatus := current.Status
+
+		if fields["status"], err = transition(current); err != nil {
+			return err
+		}
+		if err := paymentRepo.UpdateFields(id, fields); err != nil {
+			return err
+		}
+
+		updated, err = paymentRepo.FindByID(id)
+		if err != nil {
+			return err
+		}
+
+		if err := s.ledgerService.PostPayment(tx, updated); err != nil {
+			return err
+		}
+
+		return s.recordStatusChange(tx, updated, previousStatus)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// GetInReview returns the payments of all users that are held for review
+func (s *PaymentService) GetInReview() ([]models.Payment, error) {
+	return s.paymentRepo.FindAllByStatus("review")
+}
+
+// ApproveReview releases a payment held for review with the status it was created with
+func (s *PaymentService) ApproveReview(id uuid.UUID) (*models.Payment, error) {
+	return s.applyStatus(id, fromReview(func(current *models.Payment) (string, error) {
+		if current.HeldStatus == "" {
+			return "pending", nil
+		}
+		return current.HeldStatus, nil
+	}), map[string]interface{}{"held_status": ""})
+}
+
+// RejectReview fails a payment held for review
+func (s *PaymentService) RejectReview(id uuid.UUID) (*models.Payment, error) {
+	return s.applyStatus(id, fromReview(toStatus("failed")), map[string]interface{}{"held_status": ""})
+}
+
+// fromReview allows transition only for payments held for review
+func fromReview(transition statusTransition) statusTransition {
+	return func(current *models.Payment) (string, error) {
+		if current.Status != "review" {
+			return "", ErrPaymentNotInReview
+		}
+		return transition(current)
+	}
+}
+
+// ApplyCategorization applies the user's active categorization rules, or only
+// the given rule, to existing payments and returns the changes made
+func (s *PaymentService) ApplyCategorization(userID uuid.UUID, ruleID *uuid.UUID) ([]CategorizationChange, error) {
+	changes, err := s.categorizationService.Preview(userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.outboxService.Transaction(func(tx *gorm.DB) error {
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		for _, change := range changes {
+			if err := paymentRepo.UpdateCategorization(change.PaymentID, change.NewCategoryID, change.NewPayee, change.NewTags); err != nil {
+				return err
+			}
+
+			// A new category moves the amount to another expense or income account
+			updated, err := paymentRepo.FindByID(change.PaymentID)
+			if err != nil {
+				return err
+			}
+			if err := s.ledgerService.PostPayment(tx, updated); err != nil {
+				return err
+			}
+			// Limits are not rechecked, so the payment stays as far over them as it was
+			if err := s.recordEvent(tx, updated, models.EventPaymentUpdated, PaymentUpdatedEvent{
+				Payment:           updated,
+				PreviousOverLimit: updated.OverLimit,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (s *PaymentService) Delete(id uuid.UUID) error {
+	payment, err := s.paymentRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	return s.outboxService.Transaction(func(tx *gorm.DB) error {
+		if err := s.paymentRepo.WithTx(tx).Delete(id); err != nil {
+			return err
+		}
+		if err := s.ledgerService.ReversePayment(tx, payment); err != nil {
+			return err
+		}
+		return s.recordEvent(tx, payment, models.EventPaymentDeleted, payment)
+	})
+}
+
+// Export renders the user's payments as CSV, with transaction dates in the given location
+func (s *PaymentService) Export(userID uuid.UUID, status, direction, search string, minAmount, maxAmount *float64, startDate, endDate *time.Time, loc *time.Location) ([]byte, error) {
 	// Limit 0 means fetch all
 	filter := repositories.PaymentFilter{
 		Limit:     0,
 		Status:    status,
+		Direction: direction,
 		Search:    search,
 		MinAmount: minAmount,
 		MaxAmount: maxAmount,
@@ -147,20 +721,30 @@ func (s *PaymentService) Export(userID uuid.UUID, status, search string, minAmou
 	w := csv.NewWriter(b)
 
 	// Write header
-	header := []string{"Transaction Date", "Description", "Amount", "Category", "Payment Method", "Status"}
+	header := []string{"Transaction Date", "Description", "Payee", "Direction", "Amount", "Category", "Tags", "Payment Method", "Status", "Tax Code", "Tax Amount", "Tax Invoice Number"}
 	if err := w.Write(header); err != nil {
 		return nil, err
 	}
 
 	// Write rows
 	for _, p := range payments {
+		taxCode := ""
+		if p.TaxCode != nil {
+			taxCode = p.TaxCode.Code
+		}
 		row := []string{
-			p.TransactionDate.Format("2006-01-02 15:04"),
+			p.TransactionDate.In(loc).Format("2006-01-02 15:04"),
 			p.Description,
+			p.Payee,
+			p.Direction,
 			fmt.Sprintf("%.2f", p.Amount),
 			p.Category.Name,
+			strings.Join(p.Tags, ", "),
 			p.PaymentMethod.Name,
 			p.Status,
+			taxCode,
+			fmt.Sprintf("%.2f", p.TaxAmount),
+			p.TaxInvoiceNumber,
 		}
 		if err := w.Write(row); err != nil {
 			return nil, err
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/realtime"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// RealtimeService pushes payment events relayed from the outbox to the open
+// sessions of the payment's owner through a broker
+type RealtimeService struct {
+	broker realtime.Broker
+}
+
+func NewRealtimeService(broker realtime.Broker) *RealtimeService {
+	return &RealtimeService{broker: broker}
+}
+
+// Subscribe returns a channel of the user's events and a function that
+// unsubscribes it. The channel is closed when the subscriber is dropped.
+func (s *RealtimeService) Subscribe(userID uuid.UUID) (<-chan realtime.Event, func()) {
+	return s.broker.Subscribe(userID)
+}
+
+// HandlePaymentEvent publishes a payment event with its outbox payload
+func (s *RealtimeService) HandlePaymentEvent(event *models.OutboxEvent) error {
+	return s.broker.Publish(event.UserID, realtime.Event{
+		ID:   event.ID.String(),
+		Type: event.EventType,
+		Data: json.RawMessage(event.Payload),
+	})
+}
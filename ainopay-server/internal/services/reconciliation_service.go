@@ -0,0 +1,376 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"ainopay-server/internal/statements"
+	"ainopay-server/internal/utils"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Weights of the individual signals in a match score. Amount is a hard
+// filter, so every candidate starts at amountWeight.
+const (
+	amountWeight      = 0.5
+	dateWeight        = 0.2
+	descriptionWeight = 0.3
+
+	defaultDateWindowDays = 3
+	maxDateWindowDays     = 31
+)
+
+var (
+	ErrSessionNotFound     = errors.New("reconciliation session not found")
+	ErrSessionClosed       = errors.New("reconciliation session is already completed")
+	ErrLineNotFound        = errors.New("statement line not found")
+	ErrLineAlreadyResolved = errors.New("statement line is already matched")
+	ErrLineNothingToMatch  = errors.New("statement line has no proposed payment; provide a payment_id")
+	ErrPaymentReconciled   = errors.New("payment is already reconciled")
+	ErrEmptyStatement      = errors.New("statement contains no lines")
+)
+
+type ReconciliationService struct {
+	reconciliationRepo *repositories.ReconciliationRepository
+	paymentRepo        *repositories.PaymentRepository
+	paymentService     *PaymentService
+}
+
+func NewReconciliationService(
+	reconciliationRepo *repositories.ReconciliationRepository,
+	paymentRepo *repositories.PaymentRepository,
+	paymentService *PaymentService,
+) *ReconciliationService {
+	return &ReconciliationService{
+		reconciliationRepo: reconciliationRepo,
+		paymentRepo:        paymentRepo,
+		paymentService:     paymentService,
+	}
+}
+
+type CreateReconciliationRequest struct {
+	Name           string
+	DateWindowDays int
+	Entries        []statements.Entry
+}
+
+type CreatePaymentFromLineRequest struct {
+	PaymentMethodID uuid.UUID `json:"payment_method_id"`
+	CategoryID      uuid.UUID `json:"category_id"`
+	Description     string    `json:"description"`
+}
+
+// CreateSession stores an uploaded statement and proposes a payment for each line
+func (s *ReconciliationService) CreateSession(userID uuid.UUID, req *CreateReconciliationRequest) (*models.ReconciliationSession, error) {
+	if len(req.Entries) == 0 {
+		return nil, ErrEmptyStatement
+	}
+
+	window := req.DateWindowDays
+	if window <= 0 {
+		window = defaultDateWindowDays
+	}
+	if window > maxDateWindowDays {
+		window = maxDateWindowDays
+	}
+
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("Statement %s", time.Now().Format("2006-01-02 15:04"))
+	}
+
+	session := &models.ReconciliationSession{
+		UserID:         userID,
+		Name:           name,
+		Status:         models.ReconciliationStatusOpen,
+		DateWindowDays: window,
+	}
+
+	claimed := map[uuid.UUID]bool{}
+	for i, entry := range req.Entries {
+		line := models.StatementLine{
+			LineNumber:      i + 1,
+			TransactionDate: entry.BookingDate,
+			Amount:          entry.Amount,
+			Currency:        entry.Currency,
+			Description:     entry.Description,
+			Reference:       entry.Reference,
+			Status:          models.StatementLineUnmatched,
+		}
+		if line.Reference == "" {
+			line.Reference = entry.BankRef
+		}
+
+		match, score, err := s.findBestMatch(userID, &line, window, claimed)
+		if err != nil {
+			return nil, err
+		}
+		if match != nil {
+			claimed[match.ID] = true
+			line.PaymentID = &match.ID
+			line.MatchScore = score
+			line.Status = models.StatementLineProposed
+		}
+
+		session.Lines = append(session.Lines, line)
+	}
+
+	if err := s.reconciliationRepo.CreateSession(session); err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationRepo.FindSessionByID(session.ID)
+}
+
+// findBestMatch looks for an unreconciled, unclaimed payment with the same
+// amount, direction and currency inside the date window and ranks candidates
+// by date proximity and description similarity
+func (s *ReconciliationService) findBestMatch(userID uuid.UUID, line *models.StatementLine, windowDays int, claimed map[uuid.UUID]bool) (*models.Payment, float64, error) {
+	amount := math.Abs(line.Amount)
+
+	candidates, err := s.reconciliationRepo.FindMatchCandidates(userID, repositories.MatchCandidateFilter{
+		MinAmount:     amount - 0.005,
+		MaxAmount:     amount + 0.005,
+		StartDate:     line.TransactionDate.AddDate(0, 0, -windowDays),
+		EndDate:       line.TransactionDate.AddDate(0, 0, windowDays+1).Add(-time.Second),
+		Income:        line.Amount > 0,
+		Currency:      strings.ToUpper(line.Currency),
+		ExcludeLineID: line.ID,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var best *models.Payment
+	bestScore := 0.0
+	for i := range candidates {
+		candidate := &candidates[i]
+		// Payments proposed for earlier lines of the same statement
+		if claimed[candidate.ID] {
+			continue
+		}
+
+		score := matchScore(line, candidate, windowDays)
+		if score > bestScore {
+			best = candidate
+			bestScore = score
+		}
+	}
+
+	return best, bestScore, nil
+}
+
+// matchScore combines amount, date proximity and description similarity into [0, 1]
+func matchScore(line *models.StatementLine, payment *models.Payment, windowDays int) float64 {
+	days := math.Abs(models.StartOfDay(line.TransactionDate).Sub(models.StartOfDay(payment.TransactionDate)).Hours() / 24)
+	dateScore := 1 - days/float64(windowDays+1)
+	if dateScore < 0 {
+		dateScore = 0
+	}
+
+	descScore := utils.DescriptionSimilarity(line.Description, payment.Description)
+
+	return amountWeight + dateWeight*dateScore + descriptionWeight*descScore
+}
+
+func (s *ReconciliationService) GetSessions(userID uuid.UUID) ([]models.ReconciliationSession, error) {
+	return s.reconciliationRepo.FindSessionsByUserID(userID)
+}
+
+func (s *ReconciliationService) GetSession(userID, id uuid.UUID) (*models.ReconciliationSession, error) {
+	session, err := s.reconciliationRepo.FindSessionByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	if session.UserID != userID {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// openLine loads a line of an open session owned by the user and locks it
+// until tx ends
+func (s *ReconciliationService) openLine(tx *gorm.DB, userID, sessionID, lineID uuid.UUID) (*models.ReconciliationSession, *models.StatementLine, error) {
+	session, err := s.GetSession(userID, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.Status != models.ReconciliationStatusOpen {
+		return nil, nil, ErrSessionClosed
+	}
+
+	line, err := s.reconciliationRepo.WithTx(tx).LockLine(sessionID, lineID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrLineNotFound
+		}
+		return nil, nil, err
+	}
+	return session, line, nil
+}
+
+// ConfirmLine accepts the proposed match, or matches the line to paymentID
+// when one is given, and marks the payment as reconciled. The line and the
+// payment are locked, so a payment cannot be reconciled against two lines by
+// concurrent requests.
+func (s *ReconciliationService) ConfirmLine(userID, sessionID, lineID uuid.UUID, paymentID *uuid.UUID) (*models.ReconciliationSession, error) {
+	err := s.reconciliationRepo.Transaction(func(tx *gorm.DB) error {
+		session, line, err := s.openLine(tx, userID, sessionID, lineID)
+		if err != nil {
+			return err
+		}
+		if line.Status == models.StatementLineConfirmed || line.Status == models.StatementLineCreated {
+			return ErrLineAlreadyResolved
+		}
+
+		proposed := paymentID == nil
+		if proposed {
+			if line.Status != models.StatementLineProposed || line.PaymentID == nil {
+				return ErrLineNothingToMatch
+			}
+			paymentID = line.PaymentID
+		}
+
+		paymentRepo := s.paymentRepo.WithTx(tx)
+		payment, err := paymentRepo.LockByID(*paymentID)
+		if err != nil || payment.UserID != userID {
+			return ErrPaymentNotFound
+		}
+		if payment.Reconciled {
+			return ErrPaymentReconciled
+		}
+		if taken, err := s.reconciliationRepo.WithTx(tx).IsPaymentClaimed(payment.ID, line.ID); err != nil {
+			return err
+		} else if taken {
+			return ErrPaymentReconciled
+		}
+		if !proposed && (line.PaymentID == nil || *line.PaymentID != payment.ID) {
+			line.MatchScore = matchScore(line, payment, session.DateWindowDays)
+		}
+
+		line.PaymentID = &payment.ID
+		line.Status = models.StatementLineConfirmed
+
+		if err := s.reconciliationRepo.WithTx(tx).UpdateLine(line); err != nil {
+			return err
+		}
+		return paymentRepo.SetReconciled(payment.ID, true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationRepo.FindSessionByID(sessionID)
+}
+
+// RejectLine discards the match of a line so it can be matched manually or
+// turned into a new payment
+func (s *ReconciliationService) RejectLine(userID, sessionID, lineID uuid.UUID) (*models.ReconciliationSession, error) {
+	err := s.reconciliationRepo.Transaction(func(tx *gorm.DB) error {
+		_, line, err := s.openLine(tx, userID, sessionID, lineID)
+		if err != nil {
+			return err
+		}
+		if line.Status == models.StatementLineCreated {
+			return ErrLineAlreadyResolved
+		}
+
+		if line.Status == models.StatementLineConfirmed && line.PaymentID != nil {
+			if err := s.paymentRepo.WithTx(tx).SetReconciled(*line.PaymentID, false); err != nil {
+				return err
+			}
+		}
+
+		line.PaymentID = nil
+		line.MatchScore = 0
+		line.Status = models.StatementLineRejected
+
+		return s.reconciliationRepo.WithTx(tx).UpdateLine(line)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationRepo.FindSessionByID(sessionID)
+}
+
+// CreatePaymentFromLine records a completed payment for a statement line that
+// has no counterpart in the recorded payments. The payment is created in the
+// same transaction that resolves the line, which stays locked meanwhile.
+func (s *ReconciliationService) CreatePaymentFromLine(userID, sessionID, lineID uuid.UUID, req *CreatePaymentFromLineRequest) (*models.ReconciliationSession, error) {
+	err := s.paymentService.transaction(func(tx *gorm.DB) error {
+		_, line, err := s.openLine(tx, userID, sessionID, lineID)
+		if err != nil {
+			return err
+		}
+		if line.Status == models.StatementLineConfirmed || line.Status == models.StatementLineCreated {
+			return ErrLineAlreadyResolved
+		}
+
+		description := req.Description
+		if description == "" {
+			description = line.Description
+		}
+
+		direction := models.PaymentDirectionExpense
+		if line.Amount > 0 {
+			direction = models.PaymentDirectionIncome
+		}
+
+		payment, err := s.paymentService.create(tx, userID, &CreatePaymentRequest{
+			Amount:          math.Abs(line.Amount),
+			Status:          "completed",
+			Direction:       direction,
+			PaymentMethodID: req.PaymentMethodID,
+			CategoryID:      req.CategoryID,
+			Description:     description,
+			TransactionDate: line.TransactionDate,
+			// The user has already reviewed this line against the recorded payments
+			Force: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := s.paymentRepo.WithTx(tx).SetReconciled(payment.ID, true); err != nil {
+			return err
+		}
+
+		line.PaymentID = &payment.ID
+		line.MatchScore = 1
+		line.Status = models.StatementLineCreated
+
+		return s.reconciliationRepo.WithTx(tx).UpdateLine(line)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationRepo.FindSessionByID(sessionID)
+}
+
+// Complete closes the session. Lines that were never confirmed stay as they are.
+func (s *ReconciliationService) Complete(userID, sessionID uuid.UUID) (*models.ReconciliationSession, error) {
+	session, err := s.GetSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.ReconciliationStatusOpen {
+		return nil, ErrSessionClosed
+	}
+
+	if err := s.reconciliationRepo.UpdateSessionStatus(sessionID, models.ReconciliationStatusCompleted); err != nil {
+		return nil, err
+	}
+
+	return s.reconciliationRepo.FindSessionByID(sessionID)
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Methods of projecting spending that is not scheduled or recurring
+const (
+	ForecastMethodSeasonalNaive = "seasonal_naive" // same month last year, with a year of history
+	ForecastMethodMovingAverage = "moving_average" // average of the last three months
+	ForecastMethodNone          = "none"           // no spending history
+)
+
+const (
+	forecastHistoryMonths   = 12
+	forecastAverageMonths   = 3
+	forecastConfidence      = 0.8
+	forecastZ               = 1.2816 // two-sided 80% normal quantile
+	recurringLookbackMonths = 6
+	recurringMinMonths      = 3
+	recurringMaxSpread      = 0.2 // largest (max - min) / median of the amounts of a series
+)
+
+// ForecastPoint is the expected spending at the end of a period and the
+// band it falls in with the forecast's confidence
+type ForecastPoint struct {
+	Date      string  `json:"date"`      // last day of the period, YYYY-MM-DD
+	Actual    float64 `json:"actual"`    // completed spending so far
+	Scheduled float64 `json:"scheduled"` // pending and in-review payments dated in the period
+	Recurring float64 `json:"recurring"` // recurring payments expected but not recorded yet
+	Projected float64 `json:"projected"` // projection of other spending
+	Total     float64 `json:"total"`
+	Low       float64 `json:"low"`
+	High      float64 `json:"high"`
+
+	variance float64 // of the projection
+}
+
+type CategoryForecast struct {
+	CategoryID   uuid.UUID     `json:"category_id"`
+	CategoryName string        `json:"category_name"`
+	Method       string        `json:"method"`
+	MonthEnd     ForecastPoint `json:"month_end"`
+	YearEnd      ForecastPoint `json:"year_end"`
+}
+
+// RecurringPayment is a series of similar monthly payments found in the
+// user's history
+type RecurringPayment struct {
+	CategoryID uuid.UUID `json:"category_id"`
+	Name       string    `json:"name"` // payee, or description when there is none
+	Amount     float64   `json:"amount"`
+	LastDate   time.Time `json:"last_date"`
+}
+
+// SpendingForecast projects the user's expenses to the end of the month and
+// of the year
+type SpendingForecast struct {
+	AsOf       time.Time          `json:"as_of"`
+	Confidence float64            `json:"confidence"` // chance that spending ends between low and high
+	MonthEnd   ForecastPoint      `json:"month_end"`
+	YearEnd    ForecastPoint      `json:"year_end"`
+	Categories []CategoryForecast `json:"categories"`
+	Recurring  []RecurringPayment `json:"recurring"`
+}
+
+// categoryForecast collects the payments of one category
+type categoryForecast struct {
+	forecast CategoryForecast
+	first    *time.Time                     // first completed expense ever
+	monthly  [forecastHistoryMonths]float64 // other completed spending of the past months, oldest first
+}
+
+// recurringSeries is the payments of one category and payee or description
+type recurringSeries struct {
+	RecurringPayment
+	lookback map[int]bool // past months, counted back from the current month, with a completed payment
+	amounts  []float64    // of the completed payments in the lookback months, oldest first
+	months   map[int]bool // months from the current month on with a payment
+}
+
+// recurringKey groups the payments of a series
+func recurringKey(payment *models.Payment) (string, string) {
+	name := strings.TrimSpace(payment.Payee)
+	if name == "" {
+		name = strings.TrimSpace(payment.Description)
+	}
+	return payment.CategoryID.String() + "|" + strings.ToLower(name), name
+}
+
+// monthIndex returns the number of months from the month of from to the
+// month of t, both in loc
+func monthIndex(from, t time.Time, loc *time.Location) int {
+	t = t.In(loc)
+	return (t.Year()-from.Year())*12 + int(t.Month()) - int(from.Month())
+}
+
+// GetSpendingForecast projects the user's expenses per category to the end
+// of the current month and year in loc. Scheduled payments and recurring
+// series are added as they are; other spending is projected with a seasonal
+// naive forecast when the category has a year of history, else with a moving
+// average.
+func (s *ReportService) GetSpendingForecast(userID uuid.UUID, loc *time.Location, now time.Time) (*SpendingForecast, error) {
+	now = now.In(loc)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	historyStart := monthStart.AddDate(0, -forecastHistoryMonths, 0)
+	yearEnd := time.Date(now.Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+
+	payments, err := s.paymentRepo.FindExpenses(userID, historyStart, yearEnd)
+	if err != nil {
+		return nil, err
+	}
+	firstDates, err := s.paymentRepo.FirstExpenseDates(userID)
+	if err != nil {
+		return nil, err
+	}
+	return buildSpendingForecast(payments, firstDates, now, loc), nil
+}
+
+// buildSpendingForecast forecasts from the expenses since the start of the
+// history and the first expense date of each category
+func buildSpendingForecast(payments []models.Payment, firstDates map[uuid.UUID]time.Time, now time.Time, loc *time.Location) *SpendingForecast {
+	now = now.In(loc)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	yearEnd := time.Date(now.Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+	historyStart := monthStart.AddDate(0, -forecastHistoryMonths, 0)
+	remainingMonths := monthIndex(monthStart, yearEnd, loc) - 1 // after the current month
+
+	// Find the recurring series among the payments of the last months
+	series := map[string]*recurringSeries{}
+	for i := range payments {
+		payment := &payments[i]
+		month := monthIndex(monthStart, payment.TransactionDate, loc)
+		if month < -recurringLookbackMonths {
+			continue
+		}
+
+		key, name := recurringKey(payment)
+		entry, ok := series[key]
+		if !ok {
+			entry = &recurringSeries{
+				RecurringPayment: RecurringPayment{CategoryID: payment.CategoryID, Name: name},
+				lookback:         map[int]bool{},
+				months:           map[int]bool{},
+			}
+			series[key] = entry
+		}
+		if month >= 0 {
+			entry.months[month] = true
+		} else if payment.Status == "completed" {
+			entry.lookback[month] = true
+			entry.amounts = append(entry.amounts, payment.Amount)
+			entry.LastDate = payment.TransactionDate
+		}
+	}
+
+	recurring := map[string]*recurringSeries{}
+	for key, entry := range series {
+		if len(entry.lookback) < recurringMinMonths {
+			continue
+		}
+		sorted := append([]float64(nil), entry.amounts...)
+		sort.Float64s(sorted)
+		median := sorted[len(sorted)/2]
+		if median <= 0 || (sorted[len(sorted)-1]-sorted[0])/median > recurringMaxSpread {
+			continue
+		}
+		latest := entry.amounts[max(0, len(entry.amounts)-3):]
+		sort.Float64s(latest)
+		entry.Amount = latest[len(latest)/2]
+		recurring[key] = entry
+	}
+
+	// Sum the payments of every category
+	categories := map[uuid.UUID]*categoryForecast{}
+	category := func(payment *models.Payment) *categoryForecast {
+		c, ok := categories[payment.CategoryID]
+		if !ok {
+			c = &categoryForecast{forecast: CategoryForecast{CategoryID: payment.CategoryID, CategoryName: payment.Category.Name}}
+			if first, ok := firstDates[payment.CategoryID]; ok {
+				c.first = &first
+			}
+			categories[payment.CategoryID] = c
+		}
+		return c
+	}
+
+	yearStart := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, loc)
+	for i := range payments {
+		payment := &payments[i]
+		c := category(payment)
+		month := monthIndex(monthStart, payment.TransactionDate, loc)
+		key, _ := recurringKey(payment)
+		_, isRecurring := recurring[key]
+
+		if payment.Status != "completed" {
+			if !payment.TransactionDate.Before(yearStart) {
+				c.forecast.YearEnd.Scheduled += payment.Amount
+			}
+			if month == 0 {
+				c.forecast.MonthEnd.Scheduled += payment.Amount
+			}
+			continue
+		}
+
+		if !payment.TransactionDate.Before(yearStart) {
+			c.forecast.YearEnd.Actual += payment.Amount
+		}
+		if month == 0 {
+			c.forecast.MonthEnd.Actual += payment.Amount
+		}
+		if month < 0 && !isRecurring {
+			c.monthly[forecastHistoryMonths+month] += payment.Amount
+		}
+	}
+
+	// Expect the recurring payments that have not been recorded yet
+	result := &SpendingForecast{AsOf: now, Confidence: forecastConfidence, Recurring: []RecurringPayment{}}
+	for _, entry := range recurring {
+		c := categories[entry.CategoryID]
+		for month := 0; month <= remainingMonths; month++ {
+			if entry.months[month] {
+				continue
+			}
+			c.forecast.YearEnd.Recurring += entry.Amount
+			if month == 0 {
+				c.forecast.MonthEnd.Recurring += entry.Amount
+			}
+		}
+		result.Recurring = append(result.Recurring, entry.RecurringPayment)
+	}
+	sort.Slice(result.Recurring, func(i, j int) bool { return result.Recurring[i].Amount > result.Recurring[j].Amount })
+
+	// Project the rest of the spending
+	remaining := float64(monthEnd.Sub(now)) / float64(monthEnd.Sub(monthStart))
+	for _, c := range categories {
+		var forecast func(month int) float64
+		var history []float64
+		switch {
+		case c.first != nil && !c.first.After(historyStart):
+			c.forecast.Method = ForecastMethodSeasonalNaive
+			history = c.monthly[:]
+			forecast = func(month int) float64 { return c.monthly[month] }
+		case c.first != nil && c.first.Before(monthStart):
+			c.forecast.Method = ForecastMethodMovingAverage
+			months := min(forecastHistoryMonths, -monthIndex(monthStart, *c.first, loc))
+			history = c.monthly[forecastHistoryMonths-months:]
+			average := mean(history[max(0, len(history)-forecastAverageMonths):])
+			forecast = func(int) float64 { return average }
+		default:
+			c.forecast.Method = ForecastMethodNone
+			forecast = func(int) float64 { return 0 }
+		}
+
+		variance := 0.0
+		if len(history) > 1 {
+			variance = stddev(history) * stddev(history)
+		} else if len(history) == 1 {
+			variance = history[0] * history[0]
+		}
+
+		c.forecast.MonthEnd.Projected = forecast(0) * remaining
+		c.forecast.MonthEnd.variance = variance * remaining
+		c.forecast.YearEnd.Projected = c.forecast.MonthEnd.Projected
+		for month := 1; month <= remainingMonths; month++ {
+			c.forecast.YearEnd.Projected += forecast(month)
+		}
+		c.forecast.YearEnd.variance = variance * (remaining + float64(remainingMonths))
+	}
+
+	result.MonthEnd.Date = monthEnd.AddDate(0, 0, -1).Format("2006-01-02")
+	result.YearEnd.Date = yearEnd.AddDate(0, 0, -1).Format("2006-01-02")
+	result.Categories = make([]CategoryForecast, 0, len(categories))
+	for _, c := range categories {
+		c.forecast.MonthEnd.Date = result.MonthEnd.Date
+		c.forecast.YearEnd.Date = result.YearEnd.Date
+		addForecast(&result.MonthEnd, &c.forecast.MonthEnd)
+		addForecast(&result.YearEnd, &c.forecast.YearEnd)
+		finishForecast(&c.forecast.MonthEnd)
+		finishForecast(&c.forecast.YearEnd)
+		result.Categories = append(result.Categories, c.forecast)
+	}
+	finishForecast(&result.MonthEnd)
+	finishForecast(&result.YearEnd)
+
+	sort.Slice(result.Categories, func(i, j int) bool {
+		return result.Categories[i].YearEnd.Total > result.Categories[j].YearEnd.Total
+	})
+	return result
+}
+
+// addForecast adds a category's forecast to the total, assuming the
+// categories vary independently
+func addForecast(total, point *ForecastPoint) {
+	total.Actual += point.Actual
+	total.Scheduled += point.Scheduled
+	total.Recurring += point.Recurring
+	total.Projected += point.Projected
+	total.variance += point.variance
+}
+
+// finishForecast rounds a forecast and sets its total and band. The band
+// never goes below the spending already recorded.
+func finishForecast(point *ForecastPoint) {
+	point.Actual = fromCents(toCents(point.Actual))
+	point.Scheduled = fromCents(toCents(point.Scheduled))
+	point.Recurring = fromCents(toCents(point.Recurring))
+	point.Projected = fromCents(toCents(point.Projected))
+	point.Total = fromCents(toCents(point.Actual) + toCents(point.Scheduled) + toCents(point.Recurring) + toCents(point.Projected))
+
+	margin := forecastZ * math.Sqrt(point.variance)
+	point.Low = fromCents(toCents(math.Max(point.Total-margin, point.Actual+point.Scheduled)))
+	point.High = fromCents(toCents(point.Total + margin))
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidBreakdown   = errors.New("breakdown must be by category, payment-method or status")
+	ErrInvalidStatsPeriod = errors.New("invalid statistics period")
+)
+
+// Groupings of breakdown reports, as used in report URLs
+var breakdownGroupings = map[string]struct {
+	column, label string
+}{
+	"category":       {"category", "Category"},
+	"payment-method": {"payment_method", "Payment Method"},
+	"status":         {"status", "Status"},
+}
+
+// ReportService aggregates the user's payments into reports. Dashboard
+// figures are read from the daily summaries when they line up with the
+// summary days.
+type ReportService struct {
+	paymentRepo *repositories.PaymentRepository
+	summaryRepo *repositories.PaymentSummaryRepository
+	userRepo    *repositories.UserRepository
+}
+
+func NewReportService(paymentRepo *repositories.PaymentRepository, summaryRepo *repositories.PaymentSummaryRepository, userRepo *repositories.UserRepository) *ReportService {
+	return &ReportService{paymentRepo: paymentRepo, summaryRepo: summaryRepo, userRepo: userRepo}
+}
+
+// useSummaries reports whether the daily summaries, whose days are in the
+// user's timezone, can answer a query grouped in loc with the given bounds
+func (s *ReportService) useSummaries(userID uuid.UUID, loc *time.Location, bounds ...*time.Time) bool {
+	timezone, err := s.userRepo.FindTimezone(userID)
+	if err != nil || timezone != loc.String() {
+		return false
+	}
+	for _, bound := range bounds {
+		if bound != nil && !bound.Equal(models.StartOfDay(bound.In(loc))) {
+			return false
+		}
+	}
+	return true
+}
+
+// BreakdownRequest selects the payments of a breakdown report. Category and
+// payment method breakdowns default to completed payments.
+type BreakdownRequest struct {
+	GroupBy   string // category, payment-method or status
+	StartDate *time.Time
+	EndDate   *time.Time
+	Direction string // defaults to expense
+	Status    string
+}
+
+type BreakdownReport struct {
+	GroupBy   string                `json:"group_by"`
+	StartDate *time.Time            `json:"start_date"`
+	EndDate   *time.Time            `json:"end_date"`
+	Direction string                `json:"direction"`
+	Status    string                `json:"status,omitempty"`
+	Count     int64                 `json:"count"`
+	Total     float64               `json:"total"`
+	Rows      []models.BreakdownRow `json:"rows"`
+}
+
+// GetBreakdown aggregates the user's payments by category, payment method or
+// status with the count, total, average and share of the total of each group
+func (s *ReportService) GetBreakdown(userID uuid.UUID, req *BreakdownRequest) (*BreakdownReport, error) {
+	grouping, ok := breakdownGroupings[req.GroupBy]
+	if !ok {
+		return nil, ErrInvalidBreakdown
+	}
+
+	report := &BreakdownReport{
+		GroupBy:   req.GroupBy,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Direction: paymentDirection(req.Direction),
+		Status:    req.Status,
+	}
+	if report.Status == "" && req.GroupBy != "status" {
+		report.Status = "completed"
+	}
+
+	rows, err := s.paymentRepo.Breakdown(userID, grouping.column, repositories.BreakdownFilter{
+		StartDate: report.StartDate,
+		EndDate:   report.EndDate,
+		Direction: report.Direction,
+		Status:    report.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for i := range rows {
+		rows[i].Average = fromCents(toCents(rows[i].Average))
+		report.Count += rows[i].Count
+		total += toCents(rows[i].Total)
+	}
+	for i := range rows {
+		if total != 0 {
+			rows[i].Share = math.Round(float64(toCents(rows[i].Total))/float64(total)*10000) / 10000
+		}
+	}
+
+	report.Total = fromCents(total)
+	report.Rows = rows
+	if report.Rows == nil {
+		report.Rows = []models.BreakdownRow{}
+	}
+	return report, nil
+}
+
+// ExportBreakdown renders a breakdown report as CSV
+func (s *ReportService) ExportBreakdown(userID uuid.UUID, req *BreakdownRequest) ([]byte, error) {
+	report, err := s.GetBreakdown(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+
+	header := []string{breakdownGroupings[req.GroupBy].label, "Count", "Total", "Average", "Share (%)"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, row := range report.Rows {
+		record := []string{
+			row.Label,
+			strconv.FormatInt(row.Count, 10),
+			fmt.Sprintf("%.2f", row.Total),
+			fmt.Sprintf("%.2f", row.Average),
+			fmt.Sprintf("%.2f", row.Share*100),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Write([]string{"Total", strconv.FormatInt(report.Count, 10), fmt.Sprintf("%.2f", report.Total), "", "100.00"}); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// GetMonthlyStats returns the user's completed expenses of each month of a
+// year in the given location
+func (s *ReportService) GetMonthlyStats(userID uuid.UUID, year int, loc *time.Location) ([]models.MonthlyStats, error) {
+	if s.useSummaries(userID, loc) {
+		return s.summaryRepo.GetMonthlyExpenses(userID, year)
+	}
+	return s.paymentRepo.GetMonthlyExpenses(userID, year, loc)
+}
+
+// GetCashFlow returns the monthly inflow and outflow of the user's money from
+// the month of start through the month of end in the given location,
+// including months without payments
+func (s *ReportService) GetCashFlow(userID uuid.UUID, start, end time.Time, loc *time.Location) ([]models.CashFlowPoint, error) {
+	start, end = start.In(loc), end.In(loc)
+	first := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, loc)
+	last := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, loc)
+
+	next := last.AddDate(0, 1, 0)
+	var points []models.CashFlowPoint
+	var err error
+	if s.useSummaries(userID, loc) {
+		points, err = s.summaryRepo.GetCashFlow(userID, first, next)
+	} else {
+		points, err = s.paymentRepo.GetCashFlow(userID, first, next, loc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byMonth := make(map[string]models.CashFlowPoint, len(points))
+	for _, point := range points {
+		byMonth[point.Month] = point
+	}
+
+	series := []models.CashFlowPoint{}
+	for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		point := byMonth[key]
+		point.Month = key
+		point.Net = point.Inflow - point.Outflow
+		series = append(series, point)
+	}
+	return series, nil
+}
+
+// Preset periods of dashboard statistics
+const (
+	StatsPresetThisMonth   = "this_month"
+	StatsPresetLastMonth   = "last_month"
+	StatsPresetThisQuarter = "this_quarter"
+	StatsPresetLastQuarter = "last_quarter"
+	StatsPresetYearToDate  = "ytd"
+	StatsPresetLastYear    = "last_year"
+)
+
+// StatsRequest selects the period of dashboard statistics: a preset, or a
+// from/to date range with either end open. Without either the statistics
+// cover all time.
+type StatsRequest struct {
+	Preset   string
+	From     *time.Time // date
+	To       *time.Time // date, inclusive
+	Compare  bool       // compare against the previous period
+	Location *time.Location
+}
+
+// StatsPeriod is the date range dashboard statistics cover
+type StatsPeriod struct {
+	Preset string `json:"preset,omitempty"`
+	From   string `json:"from,omitempty"` // YYYY-MM-DD
+	To     string `json:"to,omitempty"`   // YYYY-MM-DD, inclusive
+
+	start, end *time.Time // end exclusive
+	loc        *time.Location
+}
+
+// StatChange is the change of a statistic from the previous period
+type StatChange struct {
+	Absolute float64  `json:"absolute"`
+	Percent  *float64 `json:"percent"` // null when the previous value is zero
+}
+
+type StatsChanges struct {
+	TotalPayments  StatChange `json:"total_payments"`
+	CompletedCount StatChange `json:"completed_count"`
+	PendingCount   StatChange `json:"pending_count"`
+	TotalIncome    StatChange `json:"total_income"`
+	TotalExpense   StatChange `json:"total_expense"`
+	TotalFees      StatChange `json:"total_fees"`
+	Net            StatChange `json:"net"`
+}
+
+// StatsComparison compares dashboard statistics with those of the previous
+// period of the same length
+type StatsComparison struct {
+	Period   StatsPeriod              `json:"period"`
+	Previous models.PaymentStatistics `json:"previous"`
+	Changes  StatsChanges             `json:"changes"`
+}
+
+// DashboardStats are the statistics of a period, optionally compared with
+// the previous period
+type DashboardStats struct {
+	models.PaymentStatistics
+	Period     StatsPeriod      `json:"period"`
+	Comparison *StatsComparison `json:"comparison,omitempty"`
+}
+
+// GetDashboardStats returns the statistics of the requested period. When
+// comparing, calendar presets are compared with the same part of the
+// previous month, quarter or year, and date ranges with the range of the
+// same length right before.
+func (s *ReportService) GetDashboardStats(userID uuid.UUID, req *StatsRequest) (*DashboardStats, error) {
+	period, previous, err := resolveStatsPeriod(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	statistics := s.paymentRepo.GetStatistics
+	if s.useSummaries(userID, period.loc, period.start, period.end) {
+		statistics = s.summaryRepo.GetStatistics
+	}
+
+	current, err := statistics(userID, period.start, period.end)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &DashboardStats{PaymentStatistics: *current, Period: *period}
+	if !req.Compare || previous == nil {
+		return stats, nil
+	}
+
+	prev, err := statistics(userID, previous.start, previous.end)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Comparison = &StatsComparison{
+		Period:   *previous,
+		Previous: *prev,
+		Changes: StatsChanges{
+			TotalPayments:  statChange(float64(current.TotalPayments), float64(prev.TotalPayments)),
+			CompletedCount: statChange(float64(current.CompletedCount), float64(prev.CompletedCount)),
+			PendingCount:   statChange(float64(current.PendingCount), float64(prev.PendingCount)),
+			TotalIncome:    statChange(current.TotalIncome, prev.TotalIncome),
+			TotalExpense:   statChange(current.TotalExpense, prev.TotalExpense),
+			TotalFees:      statChange(current.TotalFees, prev.TotalFees),
+			Net:            statChange(current.Net, prev.Net),
+		},
+	}
+	return stats, nil
+}
+
+func statChange(current, previous float64) StatChange {
+	change := StatChange{Absolute: fromCents(toCents(current) - toCents(previous))}
+	if previous != 0 {
+		percent := math.Round(change.Absolute/math.Abs(previous)*10000) / 100
+		change.Percent = &percent
+	}
+	return change
+}
+
+// resolveStatsPeriod returns the requested period and the period it is
+// compared with, which is nil when the period has no start
+func resolveStatsPeriod(req *StatsRequest, now time.Time) (*StatsPeriod, *StatsPeriod, error) {
+	loc := req.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	today := models.StartOfDay(now.In(loc))
+	tomorrow := today.AddDate(0, 0, 1)
+	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+	quarterStart := monthStart.AddDate(0, -int(today.Month()-1)%3, 0)
+	yearStart := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, loc)
+
+	if req.Preset != "" {
+		if req.From != nil || req.To != nil {
+			return nil, nil, fmt.Errorf("%w: use either a preset or from/to", ErrInvalidStatsPeriod)
+		}
+
+		var start, end time.Time
+		var months int // length of the preset, by which it is shifted to compare
+		switch req.Preset {
+		case StatsPresetThisMonth:
+			start, end, months = monthStart, tomorrow, 1
+		case StatsPresetLastMonth:
+			start, end, months = monthStart.AddDate(0, -1, 0), monthStart, 1
+		case StatsPresetThisQuarter:
+			start, end, months = quarterStart, tomorrow, 3
+		case StatsPresetLastQuarter:
+			start, end, months = quarterStart.AddDate(0, -3, 0), quarterStart, 3
+		case StatsPresetYearToDate:
+			start, end, months = yearStart, tomorrow, 12
+		case StatsPresetLastYear:
+			start, end, months = yearStart.AddDate(-1, 0, 0), yearStart, 12
+		default:
+			return nil, nil, fmt.Errorf("%w: unknown preset %q", ErrInvalidStatsPeriod, req.Preset)
+		}
+
+		period := newStatsPeriod(req.Preset, &start, &end, loc)
+		prevStart := start.AddDate(0, -months, 0)
+		prevEnd := end.AddDate(0, -months, 0)
+		if end.Day() != 1 {
+			// A period to date ends with the same day of the previous period,
+			// or its last day when that month is shorter
+			prevEnd = shiftMonths(end.AddDate(0, 0, -1), -months).AddDate(0, 0, 1)
+		}
+		return period, newStatsPeriod("", &prevStart, &prevEnd, loc), nil
+	}
+
+	var start, end *time.Time
+	if req.From != nil {
+		from := models.StartOfDay(req.From.In(loc))
+		start = &from
+	}
+	if req.To != nil {
+		to := models.StartOfDay(req.To.In(loc)).AddDate(0, 0, 1)
+		end = &to
+	}
+	if start != nil && end == nil {
+		end = &tomorrow
+	}
+	if start != nil && !start.Before(*end) {
+		return nil, nil, fmt.Errorf("%w: from must not be after to", ErrInvalidStatsPeriod)
+	}
+
+	period := newStatsPeriod("", start, end, loc)
+	if start == nil {
+		return period, nil, nil
+	}
+	days := int(math.Round(end.Sub(*start).Hours() / 24))
+	prevStart := start.AddDate(0, 0, -days)
+	return period, newStatsPeriod("", &prevStart, start, loc), nil
+}
+
+func newStatsPeriod(preset string, start, end *time.Time, loc *time.Location) *StatsPeriod {
+	period := &StatsPeriod{Preset: preset, start: start, end: end, loc: loc}
+	if start != nil {
+		period.From = start.Format("2006-01-02")
+	}
+	if end != nil {
+		period.To = end.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	return period
+}
+
+// shiftMonths moves a date by the given number of months, keeping it in the
+// target month when that month is shorter
+func shiftMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, months, 0)
+	last := first.AddDate(0, 1, -1)
+	if t.Day() > last.Day() {
+		return last
+	}
+	return first.AddDate(0, 0, t.Day()-1)
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	reportSchedulerInterval = time.Minute
+	reportSchedulerBatch    = 50
+	reportSendHour          = 7 // hour of the day, in the user's timezone, summaries are sent after their period ends
+	reportSendLease         = 5 * time.Minute
+	reportRetryBackoff      = 30 * time.Minute
+)
+
+var (
+	ErrReportSubscriptionNotFound = errors.New("report subscription not found")
+	ErrReportSubscriptionExists   = errors.New("a report subscription with this frequency already exists")
+	ErrInvalidReportFrequency     = errors.New("frequency must be weekly or monthly")
+)
+
+// ReportSubscriptionService manages the users' summary email subscriptions
+// and sends the summaries once their periods end
+type ReportSubscriptionService struct {
+	subscriptionRepo *repositories.ReportSubscriptionRepository
+	userRepo         *repositories.UserRepository
+	reportService    *ReportService
+	emailService     *EmailService
+}
+
+func NewReportSubscriptionService(
+	subscriptionRepo *repositories.ReportSubscriptionRepository,
+	userRepo *repositories.UserRepository,
+	reportService *ReportService,
+	emailService *EmailService,
+) *ReportSubscriptionService {
+	return &ReportSubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
+		reportService:    reportService,
+		emailService:     emailService,
+	}
+}
+
+type ReportSubscriptionRequest struct {
+	Frequency string
+	IsActive  *bool
+}
+
+func validReportFrequency(frequency string) bool {
+	return frequency == models.ReportFrequencyWeekly || frequency == models.ReportFrequencyMonthly
+}
+
+// userLocation returns the timezone of a user, or UTC when it cannot be loaded
+func userLocation(user *models.User) *time.Location {
+	loc, err := models.LoadTimezone(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// schedule sets a subscription to send the period containing now at the send
+// hour of the day it ends
+func schedule(subscription *models.ReportSubscription, now time.Time, loc *time.Location) {
+	subscription.PeriodEnd = subscription.NextPeriodEnd(now, loc)
+	end := subscription.PeriodEnd.In(loc)
+	subscription.NextRunAt = time.Date(end.Year(), end.Month(), end.Day(), reportSendHour, 0, 0, 0, loc)
+}
+
+func (s *ReportSubscriptionService) GetAll(userID uuid.UUID) ([]models.ReportSubscription, error) {
+	return s.subscriptionRepo.FindByUserID(userID)
+}
+
+// GetOwned returns a subscription of the user
+func (s *ReportSubscriptionService) GetOwned(userID, id uuid.UUID) (*models.ReportSubscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && subscription.UserID != userID) {
+		return nil, ErrReportSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// Create subscribes the user to summaries, starting with the current period
+func (s *ReportSubscriptionService) Create(userID uuid.UUID, req *ReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	if !validReportFrequency(req.Frequency) {
+		return nil, ErrInvalidReportFrequency
+	}
+	if err := s.checkFrequency(userID, req.Frequency, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.ReportSubscription{UserID: userID, Frequency: req.Frequency, IsActive: true}
+	schedule(subscription, time.Now(), userLocation(user))
+	if err := s.subscriptionRepo.Create(subscription); err != nil {
+		return nil, err
+	}
+	if req.IsActive != nil && !*req.IsActive {
+		subscription.IsActive = false
+		if err := s.subscriptionRepo.Update(subscription); err != nil {
+			return nil, err
+		}
+	}
+	return subscription, nil
+}
+
+// Update changes the frequency of a subscription or pauses it. Subscriptions
+// whose frequency changes or that are resumed start again with the current
+// period.
+func (s *ReportSubscriptionService) Update(userID, id uuid.UUID, req *ReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	if !validReportFrequency(req.Frequency) {
+		return nil, ErrInvalidReportFrequency
+	}
+
+	subscription, err := s.GetOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkFrequency(userID, req.Frequency, id); err != nil {
+		return nil, err
+	}
+
+	resumed := req.IsActive != nil && *req.IsActive && !subscription.IsActive
+	if req.Frequency != subscription.Frequency || resumed {
+		user, err := s.userRepo.FindByID(userID)
+		if err != nil {
+			return nil, err
+		}
+		subscription.Frequency = req.Frequency
+		schedule(subscription, time.Now(), userLocation(user))
+	}
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+
+	if err := s.subscriptionRepo.Update(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *ReportSubscriptionService) Delete(userID, id uuid.UUID) error {
+	if _, err := s.GetOwned(userID, id); err != nil {
+		return err
+	}
+	return s.subscriptionRepo.Delete(id)
+}
+
+// checkFrequency fails when the user already has another subscription with
+// the frequency
+func (s *ReportSubscriptionService) checkFrequency(userID uuid.UUID, frequency string, excludeID uuid.UUID) error {
+	exists, err := s.subscriptionRepo.ExistsForFrequency(userID, frequency, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrReportSubscriptionExists
+	}
+	return nil
+}
+
+// Preview renders the summary email of the user's last complete week or
+// month, with dates in loc
+func (s *ReportSubscriptionService) Preview(userID uuid.UUID, frequency string, loc *time.Location) (*SummaryEmail, error) {
+	if !validReportFrequency(frequency) {
+		return nil, ErrInvalidReportFrequency
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.ReportSubscription{Frequency: frequency}
+	end := subscription.PeriodStart(subscription.NextPeriodEnd(time.Now(), loc), loc)
+	return s.render(user, subscription, end, loc)
+}
+
+// render renders the summary email of a subscription's period ending at end
+func (s *ReportSubscriptionService) render(user *models.User, subscription *models.ReportSubscription, end time.Time, loc *time.Location) (*SummaryEmail, error) {
+	start := subscription.PeriodStart(end, loc)
+	summary, err := s.reportService.GetPeriodSummary(user.ID, subscription.Frequency, start, end.In(loc), loc)
+	if err != nil {
+		return nil, err
+	}
+	return renderSummaryEmail(user, summary, loc)
+}
+
+// StartScheduler sends due summaries in the background
+func (s *ReportSubscriptionService) StartScheduler() {
+	ticker := time.NewTicker(reportSchedulerInterval)
+	go func() {
+		for range ticker.C {
+			if err := s.ProcessDue(); err != nil {
+				log.Printf("Error sending summary emails: %v", err)
+			}
+		}
+	}()
+}
+
+// ProcessDue sends the summary of every subscription whose next run is due
+func (s *ReportSubscriptionService) ProcessDue() error {
+	subscriptions, err := s.subscriptionRepo.FindDue(time.Now(), reportSchedulerBatch)
+	if err != nil {
+		return err
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		claimed, err := s.subscriptionRepo.Claim(subscription, reportSendLease)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.run(subscription); err != nil {
+			log.Printf("Error recording summary email run %s: %v", subscription.ID, err)
+		}
+	}
+	return nil
+}
+
+// run sends the summary of a claimed subscription and schedules the next
+// one, or a retry when sending failed. Periods missed while the scheduler
+// was not running are skipped, except for the most recent one.
+func (s *ReportSubscriptionService) run(subscription *models.ReportSubscription) error {
+	now := time.Now()
+
+	user, err := s.userRepo.FindByID(subscription.UserID)
+	if err != nil {
+		subscription.NextRunAt = now.Add(reportRetryBackoff)
+		subscription.LastError = err.Error()
+		return s.subscriptionRepo.RecordRun(subscription)
+	}
+	loc := userLocation(user)
+
+	latest := subscription.PeriodStart(subscription.NextPeriodEnd(now, loc), loc)
+	if subscription.PeriodEnd.Before(latest) {
+		subscription.PeriodEnd = latest
+	}
+
+	email, err := s.render(user, subscription, subscription.PeriodEnd, loc)
+	if err == nil {
+		err = s.emailService.SendSummaryEmail(user.Email, email)
+	}
+	if err != nil {
+		log.Printf("Failed to send %s summary email of subscription %s: %v", subscription.Frequency, subscription.ID, err)
+		subscription.NextRunAt = now.Add(reportRetryBackoff)
+		subscription.LastError = err.Error()
+		return s.subscriptionRepo.RecordRun(subscription)
+	}
+
+	schedule(subscription, now, loc)
+	subscription.LastSentAt = &now
+	subscription.LastError = ""
+	return s.subscriptionRepo.RecordRun(subscription)
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"bytes"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	summaryTopCategories = 5
+	summaryPendingLimit  = 5
+)
+
+// PeriodSummary is the content of a summary email: the totals of a week or
+// month, its largest expense categories and the payments still pending
+type PeriodSummary struct {
+	Frequency     string                   `json:"frequency"`
+	Title         string                   `json:"title"` // e.g. "6 - 12 Oct 2026" or "October 2026"
+	From          string                   `json:"from"`  // YYYY-MM-DD
+	To            string                   `json:"to"`    // YYYY-MM-DD, inclusive
+	Totals        models.PaymentStatistics `json:"totals"`
+	TopCategories []models.BreakdownRow    `json:"top_categories"`
+	PendingCount  int64                    `json:"pending_count"`
+	Pending       []models.Payment         `json:"pending"` // most recent first
+}
+
+// SummaryEmail is a rendered summary email
+type SummaryEmail struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// GetPeriodSummary summarizes the user's payments from start to end (end
+// exclusive), both midnights in loc, together with all pending payments
+func (s *ReportService) GetPeriodSummary(userID uuid.UUID, frequency string, start, end time.Time, loc *time.Location) (*PeriodSummary, error) {
+	statistics := s.paymentRepo.GetStatistics
+	if s.useSummaries(userID, loc, &start, &end) {
+		statistics = s.summaryRepo.GetStatistics
+	}
+	totals, err := statistics(userID, &start, &end)
+	if err != nil {
+		return nil, err
+	}
+
+	last := end.Add(-time.Microsecond)
+	breakdown, err := s.GetBreakdown(userID, &BreakdownRequest{GroupBy: "category", StartDate: &start, EndDate: &last})
+	if err != nil {
+		return nil, err
+	}
+
+	pending, pendingCount, err := s.paymentRepo.FindAll(userID, repositories.PaymentFilter{Status: "pending", Limit: summaryPendingLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &PeriodSummary{
+		Frequency:     frequency,
+		Title:         summaryTitle(frequency, start, end.AddDate(0, 0, -1)),
+		From:          start.Format("2006-01-02"),
+		To:            end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Totals:        *totals,
+		TopCategories: breakdown.Rows[:min(len(breakdown.Rows), summaryTopCategories)],
+		PendingCount:  pendingCount,
+		Pending:       pending,
+	}
+	if summary.Pending == nil {
+		summary.Pending = []models.Payment{}
+	}
+	return summary, nil
+}
+
+// summaryTitle names the period of a summary from its first to its last day
+func summaryTitle(frequency string, first, last time.Time) string {
+	if frequency == models.ReportFrequencyMonthly {
+		return first.Format("January 2006")
+	}
+	if first.Year() != last.Year() {
+		return first.Format("2 Jan 2006") + " - " + last.Format("2 Jan 2006")
+	}
+	if first.Month() != last.Month() {
+		return first.Format("2 Jan") + " - " + last.Format("2 Jan 2006")
+	}
+	return first.Format("2") + " - " + last.Format("2 Jan 2006")
+}
+
+// formatMoney formats an amount with two decimals and thousands separators
+func formatMoney(amount float64) string {
+	cents := toCents(amount)
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	whole := strconv.FormatInt(cents/100, 10)
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String() + "." + strconv.FormatInt(cents%100+100, 10)[1:]
+}
+
+var summaryFuncs = map[string]interface{}{
+	"money":   formatMoney,
+	"percent": func(share float64) string { return strconv.FormatFloat(share*100, 'f', 1, 64) + "%" },
+	"date":    func(t time.Time, loc *time.Location) string { return t.In(loc).Format("2 Jan 2006") },
+}
+
+const summaryHTML = `<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #1f2937; max-width: 600px; margin: 0 auto;">
+  <h2>Your {{.Summary.Frequency}} summary</h2>
+  <p>Hi {{.Name}}, here is how {{.Summary.Title}} went.</p>
+
+  <table style="width: 100%; border-collapse: collapse;">
+    <tr><td>Income</td><td style="text-align: right;">{{money .Summary.Totals.TotalIncome}}</td></tr>
+    <tr><td>Expenses</td><td style="text-align: right;">{{money .Summary.Totals.TotalExpense}}</td></tr>
+    <tr><td>Fees</td><td style="text-align: right;">{{money .Summary.Totals.TotalFees}}</td></tr>
+    <tr style="font-weight: bold; border-top: 1px solid #d1d5db;"><td>Net</td><td style="text-align: right;">{{money .Summary.Totals.Net}}</td></tr>
+  </table>
+  <p>{{.Summary.Totals.CompletedCount}} completed of {{.Summary.Totals.TotalPayments}} payments.</p>
+
+  <h3>Top categories</h3>
+  {{- if .Summary.TopCategories}}
+  <table style="width: 100%; border-collapse: collapse;">
+    {{- range .Summary.TopCategories}}
+    <tr><td>{{.Label}}</td><td style="text-align: right;">{{money .Total}}</td><td style="text-align: right; color: #6b7280;">{{percent .Share}}</td></tr>
+    {{- end}}
+  </table>
+  {{- else}}
+  <p>No expenses in this period.</p>
+  {{- end}}
+
+  <h3>Pending payments</h3>
+  {{- if .Summary.Pending}}
+  <table style="width: 100%; border-collapse: collapse;">
+    {{- range .Summary.Pending}}
+    <tr><td>{{date .TransactionDate $.Location}}</td><td>{{.Description}}</td><td style="text-align: right;">{{money .Amount}}</td></tr>
+    {{- end}}
+  </table>
+  {{- if .More}}
+  <p>and {{.More}} more.</p>
+  {{- end}}
+  {{- else}}
+  <p>No pending payments.</p>
+  {{- end}}
+</body>
+</html>
+`
+
+const summaryText = `Your {{.Summary.Frequency}} summary
+
+Hi {{.Name}}, here is how {{.Summary.Title}} went.
+
+Income:   {{money .Summary.Totals.TotalIncome}}
+Expenses: {{money .Summary.Totals.TotalExpense}}
+Fees:     {{money .Summary.Totals.TotalFees}}
+Net:      {{money .Summary.Totals.Net}}
+{{.Summary.Totals.CompletedCount}} completed of {{.Summary.Totals.TotalPayments}} payments.
+
+Top categories
+{{- range .Summary.TopCategories}}
+- {{.Label}}: {{money .Total}} ({{percent .Share}})
+{{- else}}
+No expenses in this period.
+{{- end}}
+
+Pending payments
+{{- range .Summary.Pending}}
+- {{date .TransactionDate $.Location}} {{.Description}}: {{money .Amount}}
+{{- else}}
+No pending payments.
+{{- end}}
+{{- if .More}}
+and {{.More}} more.
+{{- end}}
+`
+
+var (
+	summaryHTMLTemplate = htmltemplate.Must(htmltemplate.New("summary").Funcs(summaryFuncs).Parse(summaryHTML))
+	summaryTextTemplate = texttemplate.Must(texttemplate.New("summary").Funcs(summaryFuncs).Parse(summaryText))
+)
+
+// renderSummaryEmail renders the HTML and plain-text email of a summary for
+// the user, with dates in loc
+func renderSummaryEmail(user *models.User, summary *PeriodSummary, loc *time.Location) (*SummaryEmail, error) {
+	data := struct {
+		Name     string
+		Summary  *PeriodSummary
+		More     int64
+		Location *time.Location
+	}{
+		Name:     user.FullName,
+		Summary:  summary,
+		More:     summary.PendingCount - int64(len(summary.Pending)),
+		Location: loc,
+	}
+
+	var html, text bytes.Buffer
+	if err := summaryHTMLTemplate.Execute(&html, data); err != nil {
+		return nil, err
+	}
+	if err := summaryTextTemplate.Execute(&text, data); err != nil {
+		return nil, err
+	}
+
+	return &SummaryEmail{
+		Subject: "Your " + summary.Frequency + " summary: " + summary.Title,
+		HTML:    html.String(),
+		Text:    text.String(),
+	}, nil
+}
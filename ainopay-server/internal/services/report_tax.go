@@ -0,0 +1,150 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidTaxSummary = errors.New("interval must be month, quarter or year and direction income or expense")
+
+// TaxSummaryRequest selects the payments of a tax summary
+type TaxSummaryRequest struct {
+	From      time.Time // date
+	To        time.Time // date, inclusive
+	Interval  string    // month, quarter or year; defaults to month
+	Direction string    // income or expense; both when empty
+	Location  *time.Location
+}
+
+// TaxTotals sums the tax of a tax summary. Output tax is collected on
+// income, input tax paid on expenses; the difference is payable, or
+// refundable when negative.
+type TaxTotals struct {
+	OutputTax float64 `json:"output_tax"`
+	InputTax  float64 `json:"input_tax"`
+	Payable   float64 `json:"payable"`
+}
+
+// TaxSummary is the tax of the user's completed payments per period, tax
+// code and direction
+type TaxSummary struct {
+	From      string                 `json:"from"` // YYYY-MM-DD
+	To        string                 `json:"to"`   // YYYY-MM-DD, inclusive
+	Interval  string                 `json:"interval"`
+	Direction string                 `json:"direction,omitempty"`
+	Rows      []models.TaxSummaryRow `json:"rows"`
+	Totals    TaxTotals              `json:"totals"`
+}
+
+// GetTaxSummary totals the tax base, tax and gross amount of the user's
+// completed income and expenses with tax, per period, tax code and direction
+func (s *ReportService) GetTaxSummary(userID uuid.UUID, req *TaxSummaryRequest) (*TaxSummary, error) {
+	interval := req.Interval
+	if interval == "" {
+		interval = "month"
+	}
+	switch {
+	case interval != "month" && interval != "quarter" && interval != "year",
+		req.Direction != "" && req.Direction != models.PaymentDirectionIncome && req.Direction != models.PaymentDirectionExpense:
+		return nil, ErrInvalidTaxSummary
+	}
+
+	loc := req.Location
+	from := models.StartOfDay(req.From.In(loc))
+	end := models.StartOfDay(req.To.In(loc)).AddDate(0, 0, 1)
+
+	rows, err := s.paymentRepo.TaxSummary(userID, interval, from, end, req.Direction, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TaxSummary{
+		From:      from.Format("2006-01-02"),
+		To:        end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Interval:  interval,
+		Direction: req.Direction,
+		Rows:      rows,
+	}
+	if summary.Rows == nil {
+		summary.Rows = []models.TaxSummaryRow{}
+	}
+
+	var output, input int64
+	for i := range summary.Rows {
+		row := &summary.Rows[i]
+		row.Period = taxPeriod(row.PeriodStart, interval)
+		if row.Direction == models.PaymentDirectionIncome {
+			output += toCents(row.Tax)
+		} else {
+			input += toCents(row.Tax)
+		}
+	}
+	summary.Totals = TaxTotals{
+		OutputTax: fromCents(output),
+		InputTax:  fromCents(input),
+		Payable:   fromCents(output - input),
+	}
+	return summary, nil
+}
+
+// taxPeriod names the period of a tax summary row
+func taxPeriod(start time.Time, interval string) string {
+	switch interval {
+	case "year":
+		return start.Format("2006")
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())+2)/3)
+	default:
+		return start.Format("2006-01")
+	}
+}
+
+// ExportTaxSummary renders a tax summary as CSV
+func (s *ReportService) ExportTaxSummary(userID uuid.UUID, req *TaxSummaryRequest) ([]byte, error) {
+	summary, err := s.GetTaxSummary(userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bytes.Buffer{}
+	w := csv.NewWriter(b)
+
+	header := []string{"Period", "Tax Code", "Tax Name", "Direction", "Count", "Net", "Tax", "Gross"}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, row := range summary.Rows {
+		record := []string{
+			row.Period,
+			row.TaxCode,
+			row.TaxName,
+			row.Direction,
+			strconv.FormatInt(row.Count, 10),
+			fmt.Sprintf("%.2f", row.Net),
+			fmt.Sprintf("%.2f", row.Tax),
+			fmt.Sprintf("%.2f", row.Gross),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	totals := [][]string{
+		{"Output Tax", "", "", "", "", "", fmt.Sprintf("%.2f", summary.Totals.OutputTax), ""},
+		{"Input Tax", "", "", "", "", "", fmt.Sprintf("%.2f", summary.Totals.InputTax), ""},
+		{"Payable", "", "", "", "", "", fmt.Sprintf("%.2f", summary.Totals.Payable), ""},
+	}
+	if err := w.WriteAll(totals); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"ainopay-server/internal/config"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRiskRuleNotFound = errors.New("risk rule not found")
+	ErrInvalidRiskRule  = errors.New("invalid risk rule")
+)
+
+// RiskService scores new payments with the risk rules admins define. Payments
+// scoring at least the review score are held for review.
+type RiskService struct {
+	ruleRepo    *repositories.RiskRuleRepository
+	userRepo    *repositories.UserRepository
+	reviewScore int
+}
+
+func NewRiskService(ruleRepo *repositories.RiskRuleRepository, userRepo *repositories.UserRepository, cfg *config.Config) *RiskService {
+	reviewScore, err := strconv.Atoi(cfg.Payment.RiskReviewScore)
+	if err != nil || reviewScore <= 0 {
+		reviewScore = 50
+	}
+
+	return &RiskService{ruleRepo: ruleRepo, userRepo: userRepo, reviewScore: reviewScore}
+}
+
+type RiskRuleRequest struct {
+	Name     string
+	Type     string
+	Params   models.RiskRuleParams
+	Score    int
+	IsActive *bool
+}
+
+// RiskAssessment is the outcome of scoring a payment
+type RiskAssessment struct {
+	Score   int
+	Matches []models.RiskMatch
+	Hold    bool // the payment must be reviewed before it proceeds
+}
+
+func validateRiskRule(req *RiskRuleRequest) error {
+	p := req.Params
+	validHour := func(h *int) bool { return h != nil && *h >= 0 && *h <= 23 }
+
+	switch req.Type {
+	case models.RiskRuleAmountAbove:
+		if p.Amount <= 0 {
+			return fmt.Errorf("%w: amount must be positive", ErrInvalidRiskRule)
+		}
+	case models.RiskRuleAmountOutlier:
+		if p.Multiplier <= 0 || p.MinHistory < 0 || p.LookbackDays < 0 {
+			return fmt.Errorf("%w: multiplier must be positive and min_history and lookback_days not negative", ErrInvalidRiskRule)
+		}
+	case models.RiskRuleVelocity:
+		if p.Count < 2 || p.WindowMinutes <= 0 {
+			return fmt.Errorf("%w: count must be at least 2 and window_minutes positive", ErrInvalidRiskRule)
+		}
+	case models.RiskRuleTimeOfDay:
+		if !validHour(p.StartHour) || !validHour(p.EndHour) || *p.StartHour == *p.EndHour {
+			return fmt.Errorf("%w: start_hour and end_hour must be different hours between 0 and 23", ErrInvalidRiskRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidRiskRule, req.Type)
+	}
+
+	if req.Score <= 0 {
+		return fmt.Errorf("%w: score must be positive", ErrInvalidRiskRule)
+	}
+	return nil
+}
+
+func (s *RiskService) GetRules() ([]models.RiskRule, error) {
+	return s.ruleRepo.FindAll()
+}
+
+func (s *RiskService) GetRule(id uuid.UUID) (*models.RiskRule, error) {
+	rule, err := s.ruleRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRiskRuleNotFound
+	}
+	return rule, err
+}
+
+func (s *RiskService) CreateRule(req *RiskRuleRequest) (*models.RiskRule, error) {
+	if err := validateRiskRule(req); err != nil {
+		return nil, err
+	}
+
+	rule := &models.RiskRule{IsActive: true}
+	applyRiskRuleRequest(rule, req)
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *RiskService) UpdateRule(id uuid.UUID, req *RiskRuleRequest) (*models.RiskRule, error) {
+	if err := validateRiskRule(req); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.GetRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyRiskRuleRequest(rule, req)
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *RiskService) DeleteRule(id uuid.UUID) error {
+	if _, err := s.GetRule(id); err != nil {
+		return err
+	}
+	return s.ruleRepo.Delete(id)
+}
+
+func applyRiskRuleRequest(rule *models.RiskRule, req *RiskRuleRequest) {
+	rule.Name = req.Name
+	rule.Type = req.Type
+	rule.Params = req.Params
+	rule.Score = req.Score
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+}
+
+// Assess scores a payment that is about to be created in tx against the
+// active risk rules
+func (s *RiskService) Assess(tx *gorm.DB, payment *models.Payment) (*RiskAssessment, error) {
+	repo := s.ruleRepo.WithTx(tx)
+	rules, err := repo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+
+	assessment := &RiskAssessment{}
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+		reason, err := s.evaluate(repo, rule, payment, now)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			continue
+		}
+
+		assessment.Score += rule.Score
+		assessment.Matches = append(assessment.Matches, models.RiskMatch{
+			RuleID: rule.ID,
+			Name:   rule.Name,
+			Type:   rule.Type,
+			Score:  rule.Score,
+			Reason: reason,
+		})
+	}
+
+	assessment.Hold = len(assessment.Matches) > 0 && assessment.Score >= s.reviewScore
+	return assessment, nil
+}
+
+// evaluate returns why a rule matches a payment, or an empty string if it does not
+func (s *RiskService) evaluate(repo *repositories.RiskRuleRepository, rule *models.RiskRule, payment *models.Payment, now time.Time) (string, error) {
+	p := rule.Params
+
+	switch rule.Type {
+	case models.RiskRuleAmountAbove:
+		if payment.Amount > p.Amount {
+			return fmt.Sprintf("amount %.2f is above %.2f", payment.Amount, p.Amount), nil
+		}
+
+	case models.RiskRuleAmountOutlier:
+		lookback := p.LookbackDays
+		if lookback == 0 {
+			lookback = 90
+		}
+		average, count, err := repo.CategoryHistory(payment.UserID, payment.CategoryID, payment.Direction, now.AddDate(0, 0, -lookback))
+		if err != nil {
+			return "", err
+		}
+		if count == 0 || count < int64(p.MinHistory) {
+			return "", nil
+		}
+		if payment.Amount > average*p.Multiplier {
+			return fmt.Sprintf("amount %.2f is %.1f times the category average of %.2f", payment.Amount, payment.Amount/average, average), nil
+		}
+
+	case models.RiskRuleVelocity:
+		window := time.Duration(p.WindowMinutes) * time.Minute
+		recent, err := repo.CountCreatedSince(payment.UserID, now.Add(-window))
+		if err != nil {
+			return "", err
+		}
+		if int(recent)+1 >= p.Count {
+			return fmt.Sprintf("%d payments created within %d minutes", recent+1, p.WindowMinutes), nil
+		}
+
+	case models.RiskRuleTimeOfDay:
+		if p.StartHour == nil || p.EndHour == nil {
+			return "", nil
+		}
+
+		// The hours are the user's local time
+		loc, err := findUserLocation(s.userRepo, payment.UserID)
+		if err != nil {
+			return "", err
+		}
+		t := payment.TransactionDate.In(loc)
+
+		// Dates without a time of day, from bank statements at midnight UTC or
+		// from clients at midnight in the user's timezone, are not checked
+		if utc := payment.TransactionDate.UTC(); utc.Equal(models.StartOfDay(utc)) || t.Equal(models.StartOfDay(t)) {
+			return "", nil
+		}
+		if hourInRange(t.Hour(), *p.StartHour, *p.EndHour) {
+			return fmt.Sprintf("transaction at %s is between %02d:00 and %02d:00", t.Format("15:04"), *p.StartHour, *p.EndHour), nil
+		}
+	}
+
+	return "", nil
+}
+
+// hourInRange reports whether hour is in [start, end), wrapping past midnight when end < start
+func hourInRange(hour, start, end int) bool {
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSpendingLimitNotFound = errors.New("spending limit not found")
+	ErrSpendingLimitLocked   = errors.New("spending limit was set by an admin and cannot be changed")
+	ErrInvalidSpendingLimit  = errors.New("period must be daily or monthly and action reject or flag")
+	ErrUserNotFound          = errors.New("user not found")
+)
+
+// SpendingLimitService manages spending limits and checks payments against them
+type SpendingLimitService struct {
+	limitRepo *repositories.SpendingLimitRepository
+	userRepo  *repositories.UserRepository
+}
+
+func NewSpendingLimitService(limitRepo *repositories.SpendingLimitRepository, userRepo *repositories.UserRepository) *SpendingLimitService {
+	return &SpendingLimitService{limitRepo: limitRepo, userRepo: userRepo}
+}
+
+type SpendingLimitRequest struct {
+	PaymentMethodID *uuid.UUID
+	CategoryID      *uuid.UUID
+	Period          string
+	Amount          float64
+	Action          string // defaults to reject
+}
+
+// SpendingLimitUsage is a limit with the spending in its current period
+type SpendingLimitUsage struct {
+	models.SpendingLimit
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Used        float64   `json:"used"`
+	Remaining   float64   `json:"remaining"`
+}
+
+// LimitViolation describes a spending limit a payment would exceed
+type LimitViolation struct {
+	LimitID         uuid.UUID  `json:"limit_id"`
+	Period          string     `json:"period"`
+	PaymentMethodID *uuid.UUID `json:"payment_method_id"`
+	CategoryID      *uuid.UUID `json:"category_id"`
+	PeriodStart     time.Time  `json:"period_start"`
+	Limit           float64    `json:"limit"`
+	Used            float64    `json:"used"`      // spent in the period without this payment
+	Remaining       float64    `json:"remaining"` // headroom left for this payment
+	Amount          float64    `json:"amount"`    // the payment's amount plus fee
+}
+
+// SpendingLimitError is returned when a payment would exceed spending limits
+// that reject instead of flag
+type SpendingLimitError struct {
+	Violations []LimitViolation
+}
+
+func (e *SpendingLimitError) Error() string {
+	return "payment would exceed a spending limit"
+}
+
+func validSpendingLimit(req *SpendingLimitRequest) bool {
+	switch req.Period {
+	case models.SpendingLimitDaily, models.SpendingLimitMonthly:
+	default:
+		return false
+	}
+	switch req.Action {
+	case "", models.SpendingLimitReject, models.SpendingLimitFlag:
+	default:
+		return false
+	}
+	return req.Amount > 0
+}
+
+func (s *SpendingLimitService) GetAll(userID uuid.UUID) ([]models.SpendingLimit, error) {
+	return s.limitRepo.FindByUserID(userID)
+}
+
+// GetOwned returns a limit of the user
+func (s *SpendingLimitService) GetOwned(userID, id uuid.UUID) (*models.SpendingLimit, error) {
+	limit, err := s.limitRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && limit.UserID != userID) {
+		return nil, ErrSpendingLimitNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+// Create adds a limit for a user. Limits created by an admin are locked for the user.
+func (s *SpendingLimitService) Create(userID uuid.UUID, byAdmin bool, req *SpendingLimitRequest) (*models.SpendingLimit, error) {
+	if !validSpendingLimit(req) {
+		return nil, ErrInvalidSpendingLimit
+	}
+	if byAdmin {
+		if _, err := s.userRepo.FindByID(userID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := &models.SpendingLimit{UserID: userID, SetByAdmin: byAdmin}
+	applySpendingLimitRequest(limit, req)
+	if err := s.limitRepo.Create(limit); err != nil {
+		return nil, err
+	}
+	return s.limitRepo.FindByID(limit.ID)
+}
+
+// Update changes a limit. Users cannot change limits set by an admin; a limit
+// an admin changes becomes locked for the user.
+func (s *SpendingLimitService) Update(userID, id uuid.UUID, byAdmin bool, req *SpendingLimitRequest) (*models.SpendingLimit, error) {
+	if !validSpendingLimit(req) {
+		return nil, ErrInvalidSpendingLimit
+	}
+
+	limit, err := s.GetOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit.SetByAdmin && !byAdmin {
+		return nil, ErrSpendingLimitLocked
+	}
+
+	applySpendingLimitRequest(limit, req)
+	limit.SetByAdmin = limit.SetByAdmin || byAdmin
+	if err := s.limitRepo.Update(limit); err != nil {
+		return nil, err
+	}
+	return s.limitRepo.FindByID(id)
+}
+
+// Delete removes a limit. Users cannot remove limits set by an admin.
+func (s *SpendingLimitService) Delete(userID, id uuid.UUID, byAdmin bool) error {
+	limit, err := s.GetOwned(userID, id)
+	if err != nil {
+		return err
+	}
+	if limit.SetByAdmin && !byAdmin {
+		return ErrSpendingLimitLocked
+	}
+	return s.limitRepo.Delete(id)
+}
+
+func applySpendingLimitRequest(limit *models.SpendingLimit, req *SpendingLimitRequest) {
+	limit.PaymentMethodID = req.PaymentMethodID
+	limit.PaymentMethod = nil
+	limit.CategoryID = req.CategoryID
+	limit.Category = nil
+	limit.Period = req.Period
+	limit.Amount = req.Amount
+	limit.Action = req.Action
+	if limit.Action == "" {
+		limit.Action = models.SpendingLimitReject
+	}
+}
+
+// GetUsage reports the spending against each of the user's limits in the
+// period containing date, or today when date is nil. Only the calendar date
+// of date is used; periods are days and months in the user's timezone.
+func (s *SpendingLimitService) GetUsage(userID uuid.UUID, date *time.Time) ([]SpendingLimitUsage, error) {
+	limits, err := s.limitRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := findUserLocation(s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	at := time.Now().In(loc)
+	if date != nil {
+		year, month, day := date.Date()
+		at = time.Date(year, month, day, 0, 0, 0, 0, loc)
+	}
+
+	usage := make([]SpendingLimitUsage, 0, len(limits))
+	for _, limit := range limits {
+		start, end := limit.PeriodBounds(at, loc)
+		used, err := s.limitRepo.Spending(&limit, start, end, uuid.Nil)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, SpendingLimitUsage{
+			SpendingLimit: limit,
+			PeriodStart:   start,
+			PeriodEnd:     end,
+			Used:          used,
+			Remaining:     fromCents(max(toCents(limit.Amount)-toCents(used), 0)),
+		})
+	}
+	return usage, nil
+}
+
+// Check checks a payment that is about to be saved in tx against the user's
+// limits. It returns a *SpendingLimitError if the payment exceeds limits that
+// reject, and reports whether it exceeds limits that only flag. Periods are
+// days and months in the user's timezone. For an update, previous is the
+// payment as stored; an edit that adds no spending to a limit's period is
+// flagged but never rejected.
+func (s *SpendingLimitService) Check(tx *gorm.DB, payment, previous *models.Payment) (bool, error) {
+	if !payment.CountsTowardLimits() {
+		return false, nil
+	}
+
+	repo := s.limitRepo.WithTx(tx)
+	limits, err := repo.LockByUserID(payment.UserID)
+	if err != nil || len(limits) == 0 {
+		return false, err
+	}
+
+	loc, err := findUserLocation(s.userRepo, payment.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	amount := toCents(payment.Amount) + toCents(payment.Fee)
+	overLimit := false
+	var violations []LimitViolation
+	for i := range limits {
+		limit := &limits[i]
+		if !limit.Applies(payment.PaymentMethodID, payment.CategoryID) {
+			continue
+		}
+
+		start, end := limit.PeriodBounds(payment.TransactionDate, loc)
+		used, err := repo.Spending(limit, start, end, payment.ID)
+		if err != nil {
+			return false, err
+		}
+
+		remaining := toCents(limit.Amount) - toCents(used)
+		if amount <= remaining {
+			continue
+		}
+		if limit.Action == models.SpendingLimitFlag || addsNoSpending(limit, previous, payment, start, loc) {
+			overLimit = true
+			continue
+		}
+
+		violations = append(violations, LimitViolation{
+			LimitID:         limit.ID,
+			Period:          limit.Period,
+			PaymentMethodID: limit.PaymentMethodID,
+			CategoryID:      limit.CategoryID,
+			PeriodStart:     start,
+			Limit:           limit.Amount,
+			Used:            used,
+			Remaining:       fromCents(max(remaining, 0)),
+			Amount:          fromCents(amount),
+		})
+	}
+
+	if len(violations) > 0 {
+		return false, &SpendingLimitError{Violations: violations}
+	}
+	return overLimit, nil
+}
+
+// addsNoSpending reports whether an updated payment already counted toward
+// the limit in the same period and did not grow
+func addsNoSpending(limit *models.SpendingLimit, previous, payment *models.Payment, periodStart time.Time, loc *time.Location) bool {
+	if previous == nil || !previous.CountsTowardLimits() || !limit.Applies(previous.PaymentMethodID, previous.CategoryID) {
+		return false
+	}
+	previousStart, _ := limit.PeriodBounds(previous.TransactionDate, loc)
+	return previousStart.Equal(periodStart) &&
+		toCents(previous.Amount)+toCents(previous.Fee) >= toCents(payment.Amount)+toCents(payment.Fee)
+}
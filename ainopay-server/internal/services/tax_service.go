@@ -0,0 +1,203 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTaxCodeNotFound = errors.New("tax code not found")
+	ErrTaxCodeExists   = errors.New("a tax code with this code already exists")
+	ErrTaxCodeInUse    = errors.New("tax code is used by payments; deactivate it instead")
+	ErrTaxCodeInactive = errors.New("tax code is not active")
+	ErrInvalidTax      = errors.New("invalid tax")
+)
+
+// TaxService manages the tax codes admins configure and computes the tax of
+// payments
+type TaxService struct {
+	taxCodeRepo *repositories.TaxCodeRepository
+}
+
+func NewTaxService(taxCodeRepo *repositories.TaxCodeRepository) *TaxService {
+	return &TaxService{taxCodeRepo: taxCodeRepo}
+}
+
+type TaxCodeRequest struct {
+	Code        string
+	Name        string
+	Rate        float64 // percent
+	Description string
+	IsActive    *bool
+}
+
+// PaymentTaxRequest sets the tax of a payment. Without a tax code, rate or
+// amount the payment has no tax.
+type PaymentTaxRequest struct {
+	TaxCodeID     *uuid.UUID `json:"tax_code_id"`
+	Rate          *float64   `json:"rate"`      // percent, defaults to the tax code's rate
+	Amount        *float64   `json:"amount"`    // computed from the rate when nil
+	Inclusive     *bool      `json:"inclusive"` // whether the payment amount includes the tax, defaults to true
+	InvoiceNumber string     `json:"invoice_number"`
+}
+
+// GetCodes returns the tax codes, only the active ones unless all is set
+func (s *TaxService) GetCodes(all bool) ([]models.TaxCode, error) {
+	return s.taxCodeRepo.FindAll(all)
+}
+
+func (s *TaxService) GetCode(id uuid.UUID) (*models.TaxCode, error) {
+	code, err := s.taxCodeRepo.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTaxCodeNotFound
+	}
+	return code, err
+}
+
+func (s *TaxService) CreateCode(req *TaxCodeRequest) (*models.TaxCode, error) {
+	if err := s.checkCode(uuid.Nil, req); err != nil {
+		return nil, err
+	}
+
+	code := &models.TaxCode{IsActive: true}
+	applyTaxCodeRequest(code, req)
+	if err := s.taxCodeRepo.Create(code); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// UpdateCode changes a tax code. Payments keep the rate and amount they were
+// recorded with.
+func (s *TaxService) UpdateCode(id uuid.UUID, req *TaxCodeRequest) (*models.TaxCode, error) {
+	code, err := s.GetCode(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCode(id, req); err != nil {
+		return nil, err
+	}
+
+	applyTaxCodeRequest(code, req)
+	if err := s.taxCodeRepo.Update(code); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// DeleteCode removes a tax code no payment uses
+func (s *TaxService) DeleteCode(id uuid.UUID) error {
+	if _, err := s.GetCode(id); err != nil {
+		return err
+	}
+
+	inUse, err := s.taxCodeRepo.InUse(id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrTaxCodeInUse
+	}
+	return s.taxCodeRepo.Delete(id)
+}
+
+// checkCode validates a tax code request for the tax code with the given ID,
+// or a new one when id is nil
+func (s *TaxService) checkCode(id uuid.UUID, req *TaxCodeRequest) error {
+	if strings.TrimSpace(req.Code) == "" || req.Rate < 0 || req.Rate > 100 {
+		return fmt.Errorf("%w: code is required and rate must be between 0 and 100", ErrInvalidTax)
+	}
+
+	existing, err := s.taxCodeRepo.FindByCode(strings.TrimSpace(req.Code))
+	if err == nil && existing.ID != id {
+		return ErrTaxCodeExists
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+func applyTaxCodeRequest(code *models.TaxCode, req *TaxCodeRequest) {
+	code.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	code.Name = req.Name
+	code.Rate = req.Rate
+	code.Description = req.Description
+	if req.IsActive != nil {
+		code.IsActive = *req.IsActive
+	}
+}
+
+// ApplyTax sets the tax fields of a payment from a request, taking the rate
+// from the tax code unless given and computing the amount from the rate
+// unless given. A payment may keep a tax code that has been deactivated
+// since, but not be given one.
+func (s *TaxService) ApplyTax(payment *models.Payment, req *PaymentTaxRequest) error {
+	var code *models.TaxCode
+	if req.TaxCodeID != nil {
+		var err error
+		if code, err = s.GetCode(*req.TaxCodeID); err != nil {
+			return err
+		}
+	}
+	return applyTax(payment, req, code)
+}
+
+// applyTax is ApplyTax with the tax code of the request, or nil when it has
+// none, already loaded
+func applyTax(payment *models.Payment, req *PaymentTaxRequest, code *models.TaxCode) error {
+	inclusive := req.Inclusive == nil || *req.Inclusive
+
+	var rate float64
+	if code != nil {
+		if !code.IsActive && (payment.TaxCodeID == nil || *payment.TaxCodeID != code.ID) {
+			return ErrTaxCodeInactive
+		}
+		rate = code.Rate
+	}
+	if req.Rate != nil {
+		rate = *req.Rate
+	}
+	if rate < 0 || rate > 100 {
+		return fmt.Errorf("%w: rate must be between 0 and 100", ErrInvalidTax)
+	}
+
+	amount := taxAmount(payment.Amount, rate, inclusive)
+	if req.Amount != nil {
+		amount = fromCents(toCents(*req.Amount))
+	}
+	if amount < 0 || (inclusive && amount > payment.Amount) {
+		return fmt.Errorf("%w: tax amount must not be negative or, when included, more than the payment amount", ErrInvalidTax)
+	}
+
+	payment.TaxCodeID = req.TaxCodeID
+	payment.TaxCode = nil
+	payment.TaxRate = rate
+	payment.TaxAmount = amount
+	payment.TaxInclusive = inclusive
+	payment.TaxInvoiceNumber = strings.TrimSpace(req.InvoiceNumber)
+	return nil
+}
+
+// recomputeTax updates the tax amount of a payment whose amount changed from
+// previousAmount, keeping an amount that was not computed from the rate
+func recomputeTax(payment *models.Payment, previousAmount float64) {
+	if payment.TaxAmount == taxAmount(previousAmount, payment.TaxRate, payment.TaxInclusive) {
+		payment.TaxAmount = taxAmount(payment.Amount, payment.TaxRate, payment.TaxInclusive)
+	}
+}
+
+// taxAmount is the tax at rate percent of an amount that includes the tax or
+// is the tax base, rounded to cents
+func taxAmount(amount, rate float64, inclusive bool) float64 {
+	if inclusive {
+		return fromCents(toCents(amount * rate / (100 + rate)))
+	}
+	return fromCents(toCents(amount * rate / 100))
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"ainopay-server/internal/models"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTaxAmount(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    float64
+		rate      float64
+		inclusive bool
+		want      float64
+	}{
+		{name: "exclusive", amount: 100000, rate: 11, want: 11000},
+		{name: "inclusive", amount: 111000, rate: 11, inclusive: true, want: 11000},
+		{name: "exclusive rounds down", amount: 10.04, rate: 11, want: 1.10},
+		{name: "exclusive rounds up", amount: 10.09, rate: 11, want: 1.11},
+		{name: "inclusive rounds down", amount: 10, rate: 11, inclusive: true, want: 0.99},
+		{name: "inclusive rounds up", amount: 15, rate: 11, inclusive: true, want: 1.49},
+		{name: "inclusive and exclusive differ", amount: 100, rate: 11, inclusive: true, want: 9.91},
+		{name: "zero rate", amount: 100, rate: 0, inclusive: true, want: 0},
+		{name: "full rate", amount: 100, rate: 100, inclusive: true, want: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taxAmount(tt.amount, tt.rate, tt.inclusive); got != tt.want {
+				t.Errorf("taxAmount(%v, %v, %v) = %v, want %v", tt.amount, tt.rate, tt.inclusive, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecomputeTax(t *testing.T) {
+	tests := []struct {
+		name           string
+		payment        models.Payment
+		previousAmount float64
+		want           float64
+	}{
+		{
+			name:           "computed exclusive amount follows the amount",
+			payment:        models.Payment{Amount: 200, TaxRate: 11, TaxAmount: 11},
+			previousAmount: 100,
+			want:           22,
+		},
+		{
+			name:           "computed inclusive amount follows the amount",
+			payment:        models.Payment{Amount: 222, TaxRate: 11, TaxAmount: 11, TaxInclusive: true},
+			previousAmount: 111,
+			want:           22,
+		},
+		{
+			name:           "manual amount survives an amount change",
+			payment:        models.Payment{Amount: 200, TaxRate: 11, TaxAmount: 12.5},
+			previousAmount: 100,
+			want:           12.5,
+		},
+		{
+			name:           "manual inclusive amount survives an amount change",
+			payment:        models.Payment{Amount: 222, TaxRate: 11, TaxAmount: 10, TaxInclusive: true},
+			previousAmount: 111,
+			want:           10,
+		},
+		{
+			name:           "no tax stays none",
+			payment:        models.Payment{Amount: 200},
+			previousAmount: 100,
+			want:           0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := tt.payment
+			recomputeTax(&payment, tt.previousAmount)
+			if payment.TaxAmount != tt.want {
+				t.Errorf("TaxAmount = %v, want %v", payment.TaxAmount, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyTax(t *testing.T) {
+	active := &models.TaxCode{ID: uuid.New(), Code: "PPN", Rate: 11, IsActive: true}
+	inactive := &models.TaxCode{ID: uuid.New(), Code: "OLD", Rate: 10, IsActive: false}
+	ptr := func(v float64) *float64 { return &v }
+	exclusive := false
+
+	tests := []struct {
+		name          string
+		payment       models.Payment
+		req           PaymentTaxRequest
+		code          *models.TaxCode
+		wantErr       error
+		wantRate      float64
+		wantAmount    float64
+		wantInclusive bool
+	}{
+		{
+			name:          "rate of the tax code, inclusive by default",
+			payment:       models.Payment{Amount: 111000},
+			req:           PaymentTaxRequest{TaxCodeID: &active.ID},
+			code:          active,
+			wantRate:      11,
+			wantAmount:    11000,
+			wantInclusive: true,
+		},
+		{
+			name:       "exclusive",
+			payment:    models.Payment{Amount: 100000},
+			req:        PaymentTaxRequest{TaxCodeID: &active.ID, Inclusive: &exclusive},
+			code:       active,
+			wantRate:   11,
+			wantAmount: 11000,
+		},
+		{
+			name:          "rate overrides the tax code",
+			payment:       models.Payment{Amount: 105},
+			req:           PaymentTaxRequest{TaxCodeID: &active.ID, Rate: ptr(5)},
+			code:          active,
+			wantRate:      5,
+			wantAmount:    5,
+			wantInclusive: true,
+		},
+		{
+			name:          "manual amount is rounded to cents",
+			payment:       models.Payment{Amount: 100},
+			req:           PaymentTaxRequest{Rate: ptr(11), Amount: ptr(9.876)},
+			wantRate:      11,
+			wantAmount:    9.88,
+			wantInclusive: true,
+		},
+		{
+			name:    "inactive code on a new payment",
+			payment: models.Payment{Amount: 100},
+			req:     PaymentTaxRequest{TaxCodeID: &inactive.ID},
+			code:    inactive,
+			wantErr: ErrTaxCodeInactive,
+		},
+		{
+			name:    "inactive code on a payment with another code",
+			payment: models.Payment{Amount: 100, TaxCodeID: &active.ID},
+			req:     PaymentTaxRequest{TaxCodeID: &inactive.ID},
+			code:    inactive,
+			wantErr: ErrTaxCodeInactive,
+		},
+		{
+			name:          "inactive code kept by an existing payment",
+			payment:       models.Payment{Amount: 110, TaxCodeID: &inactive.ID},
+			req:           PaymentTaxRequest{TaxCodeID: &inactive.ID},
+			code:          inactive,
+			wantRate:      10,
+			wantAmount:    10,
+			wantInclusive: true,
+		},
+		{
+			name:    "rate above 100",
+			payment: models.Payment{Amount: 100},
+			req:     PaymentTaxRequest{Rate: ptr(101)},
+			wantErr: ErrInvalidTax,
+		},
+		{
+			name:    "negative amount",
+			payment: models.Payment{Amount: 100},
+			req:     PaymentTaxRequest{Amount: ptr(-1)},
+			wantErr: ErrInvalidTax,
+		},
+		{
+			name:    "included amount above the payment amount",
+			payment: models.Payment{Amount: 100},
+			req:     PaymentTaxRequest{Amount: ptr(100.01)},
+			wantErr: ErrInvalidTax,
+		},
+		{
+			name:       "excluded amount above the payment amount",
+			payment:    models.Payment{Amount: 100},
+			req:        PaymentTaxRequest{Amount: ptr(150), Inclusive: &exclusive},
+			wantAmount: 150,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := tt.payment
+			err := applyTax(&payment, &tt.req, tt.code)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("applyTax() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyTax() error = %v", err)
+			}
+			if payment.TaxRate != tt.wantRate || payment.TaxAmount != tt.wantAmount || payment.TaxInclusive != tt.wantInclusive {
+				t.Errorf("tax = rate %v, amount %v, inclusive %v; want rate %v, amount %v, inclusive %v",
+					payment.TaxRate, payment.TaxAmount, payment.TaxInclusive, tt.wantRate, tt.wantAmount, tt.wantInclusive)
+			}
+		})
+	}
+}
@@ -0,0 +1,530 @@
+package services
+
+import (
+	"ainopay-server/internal/config"
+	"ainopay-server/internal/models"
+	"ainopay-server/internal/repositories"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookPollInterval   = 5 * time.Second
+	webhookBatchSize      = 50
+	webhookRequestTimeout = 10 * time.Second
+	webhookMaxBackoff     = 6 * time.Hour
+)
+
+var (
+	ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidEventType     = errors.New("invalid event type")
+	ErrInvalidWebhookURL    = errors.New("invalid webhook URL")
+	ErrWebhookAddressDenied = errors.New("webhook endpoint address is not allowed")
+)
+
+// carrierGradeNAT is the shared address space of RFC 6598, which net.IP does
+// not count as private
+var carrierGradeNAT = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+type WebhookService struct {
+	webhookRepo  *repositories.WebhookRepository
+	client       *http.Client
+	allowHTTP    bool // plain http endpoints are only accepted in debug mode
+	maxAttempts  int
+	retryBackoff time.Duration
+}
+
+func NewWebhookService(webhookRepo *repositories.WebhookRepository, cfg *config.Config) *WebhookService {
+	maxAttempts, err := strconv.Atoi(cfg.Webhook.MaxAttempts)
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+
+	backoff, err := time.ParseDuration(cfg.Webhook.RetryBackoff)
+	if err != nil || backoff <= 0 {
+		backoff = 30 * time.Second
+	}
+
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		client:       newWebhookClient(),
+		allowHTTP:    cfg.Server.IsDebug(),
+		maxAttempts:  maxAttempts,
+		retryBackoff: backoff,
+	}
+}
+
+// newWebhookClient returns the client deliveries are sent with. Subscribers
+// choose the URL, so the client refuses to connect to private, loopback and
+// link-local addresses. The check runs on the address being dialled, after
+// DNS resolution, so a hostname cannot be rebound to an internal address
+// between subscribing and delivery. Redirects are not followed and count as
+// failed deliveries.
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isDeniedWebhookIP(ip) {
+				return fmt.Errorf("%w: %s", ErrWebhookAddressDenied, host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookRequestTimeout,
+		Transport: &http.Transport{
+			// No proxy: the dialled address must be the subscriber's
+			Proxy: nil,
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, address)
+			},
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isDeniedWebhookIP reports whether deliveries to ip are refused: private,
+// loopback, link-local (including the 169.254.169.254 metadata endpoint),
+// multicast and unspecified addresses
+func isDeniedWebhookIP(ip net.IP) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		ip.IsUnspecified() || carrierGradeNAT.Contains(ip)
+}
+
+// validateURL checks that a subscription URL is https, or http in debug mode,
+// and does not name a denied address or localhost. Hostnames are checked
+// again after resolution when a delivery is sent.
+func (s *WebhookService) validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+
+	switch {
+	case u.Scheme == "https":
+	case u.Scheme == "http" && s.allowHTTP:
+	default:
+		return fmt.Errorf("%w: the scheme must be https", ErrInvalidWebhookURL)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("%w: missing host", ErrInvalidWebhookURL)
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressDenied, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && isDeniedWebhookIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressDenied, host)
+	}
+	return nil
+}
+
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url"`
+	EventTypes  []string `json:"event_types"`
+	Description string   `json:"description"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// WebhookSubscriptionResponse includes the signing secret, which is only
+// shown when a subscription is created
+type WebhookSubscriptionResponse struct {
+	models.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Total      int64                    `json:"total"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+}
+
+// WebhookEvent is the JSON body posted to subscribers
+type WebhookEvent struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+func normalizeEventTypes(eventTypes []string) (string, error) {
+	var normalized []string
+	for _, t := range eventTypes {
+		t = strings.TrimSpace(t)
+		valid := false
+		for _, known := range models.WebhookEventTypes {
+			if t == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", fmt.Errorf("%w: %q", ErrInvalidEventType, t)
+		}
+		normalized = append(normalized, t)
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// SignWebhookPayload computes the signature sent in the X-AinoPay-Signature
+// header: HMAC-SHA256 over "<timestamp>.<body>" keyed with the subscription secret
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) CreateSubscription(userID uuid.UUID, req *WebhookSubscriptionRequest) (*WebhookSubscriptionResponse, error) {
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.WebhookSubscription{
+		UserID:      userID,
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := s.webhookRepo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+
+	return &WebhookSubscriptionResponse{WebhookSubscription: *subscription, Secret: secret}, nil
+}
+
+func (s *WebhookService) GetSubscriptions(userID uuid.UUID) ([]models.WebhookSubscription, error) {
+	return s.webhookRepo.FindSubscriptionsByUserID(userID)
+}
+
+func (s *WebhookService) GetSubscription(userID, id uuid.UUID) (*models.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.FindSubscriptionByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+	if subscription.UserID != userID {
+		return nil, ErrSubscriptionNotFound
+	}
+	return subscription, nil
+}
+
+func (s *WebhookService) UpdateSubscription(userID, id uuid.UUID, req *WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	subscription, err := s.GetSubscription(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.URL = req.URL
+	subscription.EventTypes = eventTypes
+	subscription.Description = req.Description
+	if req.IsActive != nil {
+		subscription.IsActive = *req.IsActive
+	}
+
+	if err := s.webhookRepo.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *WebhookService) DeleteSubscription(userID, id uuid.UUID) error {
+	if _, err := s.GetSubscription(userID, id); err != nil {
+		return err
+	}
+	return s.webhookRepo.DeleteSubscription(id)
+}
+
+// HandleOutboxEvent queues webhook deliveries for a payment event relayed
+// from the outbox. The webhook event ID is the outbox event ID.
+func (s *WebhookService) HandleOutboxEvent(event *models.OutboxEvent) error {
+	return s.Publish(WebhookEvent{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt.UTC(),
+		Data:      json.RawMessage(event.Payload),
+	}, event.UserID)
+}
+
+// Publish queues an event for every active subscription of the user that
+// accepts its type. Delivery happens asynchronously in the worker. Publishing
+// the same event again only queues it for subscriptions that missed it.
+func (s *WebhookService) Publish(event WebhookEvent, userID uuid.UUID) error {
+	subscriptions, err := s.webhookRepo.FindActiveSubscriptions(userID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, subscription := range subscriptions {
+		if !subscription.Accepts(event.Type) {
+			continue
+		}
+		queued, err := s.webhookRepo.DeliveryExists(subscription.ID, event.ID)
+		if err != nil {
+			return err
+		}
+		if queued {
+			continue
+		}
+		delivery := &models.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			UserID:         userID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			Status:         models.DeliveryStatusPending,
+			NextAttemptAt:  &now,
+		}
+		if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) GetDeliveries(userID uuid.UUID, page, limit int, status string, subscriptionID *uuid.UUID) (*WebhookDeliveryListResponse, error) {
+	deliveries, total, err := s.webhookRepo.FindDeliveries(userID, repositories.DeliveryFilter{
+		Limit:          limit,
+		Offset:         (page - 1) * limit,
+		Status:         status,
+		SubscriptionID: subscriptionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookDeliveryListResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	}, nil
+}
+
+// GetDelivery returns a delivery with all of its attempts
+func (s *WebhookService) GetDelivery(userID, id uuid.UUID) (*models.WebhookDelivery, error) {
+	delivery, err := s.webhookRepo.FindDeliveryByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+	if delivery.UserID != userID {
+		return nil, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// Redeliver queues a delivery again with a fresh set of retries, whatever its
+// current status
+func (s *WebhookService) Redeliver(userID, id uuid.UUID) (*models.WebhookDelivery, error) {
+	if _, err := s.GetDelivery(userID, id); err != nil {
+		return nil, err
+	}
+	if err := s.webhookRepo.Requeue(id); err != nil {
+		return nil, err
+	}
+	return s.webhookRepo.FindDeliveryByID(id)
+}
+
+// StartWorker delivers due webhooks in the background
+func (s *WebhookService) StartWorker() {
+	ticker := time.NewTicker(webhookPollInterval)
+	go func() {
+		for range ticker.C {
+			if err := s.ProcessDue(); err != nil {
+				log.Printf("Error processing webhook deliveries: %v", err)
+			}
+		}
+	}()
+}
+
+// ProcessDue attempts every delivery whose next attempt is due
+func (s *WebhookService) ProcessDue() error {
+	deliveries, err := s.webhookRepo.FindDue(time.Now(), webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		claimed, err := s.webhookRepo.Claim(delivery, webhookRequestTimeout*3)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if err := s.attempt(delivery); err != nil {
+			log.Printf("Error recording webhook delivery %s: %v", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// attempt sends a delivery once and schedules the next retry on failure
+func (s *WebhookService) attempt(delivery *models.WebhookDelivery) error {
+	subscription, err := s.webhookRepo.FindSubscriptionByID(delivery.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	previous, err := s.webhookRepo.CountAttempts(delivery.ID)
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	statusCode, sendErr := s.send(subscription, delivery)
+
+	attempt := &models.WebhookDeliveryAttempt{
+		DeliveryID:     delivery.ID,
+		AttemptNumber:  int(previous) + 1,
+		ResponseStatus: statusCode,
+		DurationMs:     time.Since(started).Milliseconds(),
+	}
+
+	delivery.AttemptCount++
+	delivery.ResponseStatus = statusCode
+
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = models.DeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+	} else {
+		attempt.Error = sendErr.Error()
+		delivery.LastError = sendErr.Error()
+		if delivery.AttemptCount >= s.maxAttempts {
+			delivery.Status = models.DeliveryStatusDead
+			delivery.NextAttemptAt = nil
+		} else {
+			next := time.Now().Add(s.backoff(delivery.AttemptCount))
+			delivery.Status = models.DeliveryStatusFailed
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	return s.webhookRepo.RecordAttempt(delivery, attempt)
+}
+
+// backoff doubles the retry delay after every failed attempt
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.retryBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
+
+// send posts the payload to the subscriber. Any non-2xx response is a failure.
+func (s *WebhookService) send(subscription *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	if !subscription.IsActive {
+		return 0, errors.New("subscription is disabled")
+	}
+
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AinoPay-Webhooks/1.0")
+	req.Header.Set("X-AinoPay-Event", delivery.EventType)
+	req.Header.Set("X-AinoPay-Delivery", delivery.ID.String())
+	req.Header.Set("X-AinoPay-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-AinoPay-Signature", SignWebhookPayload(subscription.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestIsDeniedWebhookIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		denied bool
+	}{
+		{ip: "10.0.0.1", denied: true},
+		{ip: "10.255.255.255", denied: true},
+		{ip: "172.16.0.1", denied: true},
+		{ip: "172.31.255.254", denied: true},
+		{ip: "172.32.0.1", denied: false},
+		{ip: "192.168.1.1", denied: true},
+		{ip: "127.0.0.1", denied: true},
+		{ip: "127.255.0.1", denied: true},
+		{ip: "169.254.169.254", denied: true},
+		{ip: "100.64.0.1", denied: true},
+		{ip: "100.127.255.254", denied: true},
+		{ip: "100.128.0.1", denied: false},
+		{ip: "0.0.0.0", denied: true},
+		{ip: "224.0.0.1", denied: true},
+		{ip: "8.8.8.8", denied: false},
+		{ip: "::1", denied: true},
+		{ip: "::", denied: true},
+		{ip: "fc00::1", denied: true},
+		{ip: "fd12:3456:789a::1", denied: true},
+		{ip: "fe80::1", denied: true},
+		{ip: "::ffff:127.0.0.1", denied: true},
+		{ip: "::ffff:10.0.0.1", denied: true},
+		{ip: "::ffff:8.8.8.8", denied: false},
+		{ip: "2001:4860:4860::8888", denied: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			ip := net.ParseIP(tt.ip)
+			if ip == nil {
+				t.Fatalf("invalid IP %q", tt.ip)
+			}
+			if got := isDeniedWebhookIP(ip); got != tt.denied {
+				t.Errorf("isDeniedWebhookIP(%s) = %v, want %v", tt.ip, got, tt.denied)
+			}
+		})
+	}
+}
+
+func TestWebhookValidateURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		allowHTTP bool
+		wantErr   error
+	}{
+		{name: "https", url: "https://hooks.example.com/ainopay"},
+		{name: "https with port", url: "https://hooks.example.com:8443/ainopay"},
+		{name: "public IP", url: "https://8.8.8.8/hook"},
+		{name: "http in debug mode", url: "http://hooks.example.com/ainopay", allowHTTP: true},
+		{name: "http in release mode", url: "http://hooks.example.com/ainopay", wantErr: ErrInvalidWebhookURL},
+		{name: "other scheme", url: "ftp://hooks.example.com/ainopay", wantErr: ErrInvalidWebhookURL},
+		{name: "missing host", url: "https:///ainopay", wantErr: ErrInvalidWebhookURL},
+		{name: "unparsable", url: "https://hooks.example.com/%zz", wantErr: ErrInvalidWebhookURL},
+		{name: "localhost", url: "https://localhost/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "localhost upper case", url: "https://LOCALHOST:8080/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "localhost subdomain", url: "https://api.localhost/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "10/8", url: "https://10.1.2.3/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "172.16/12", url: "https://172.20.0.5/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "192.168/16", url: "https://192.168.0.10/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "127/8", url: "https://127.0.0.1:9090/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "metadata service", url: "http://169.254.169.254/latest/meta-data", allowHTTP: true, wantErr: ErrWebhookAddressDenied},
+		{name: "100.64/10", url: "https://100.64.1.1/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "IPv6 loopback", url: "https://[::1]/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "IPv6 unique local", url: "https://[fc00::1]:8443/hook", wantErr: ErrWebhookAddressDenied},
+		{name: "IPv4-mapped loopback", url: "https://[::ffff:127.0.0.1]/hook", wantErr: ErrWebhookAddressDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &WebhookService{allowHTTP: tt.allowHTTP}
+			err := s.validateURL(tt.url)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("validateURL(%q) error = %v, want nil", tt.url, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateURL(%q) error = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package statements
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// camt.053 (BankToCustomerStatement) document, limited to the elements we use.
+// Namespaces are ignored so every camt.053.001.xx version is accepted.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID       string        `xml:"Id"`
+	IBAN     string        `xml:"Acct>Id>IBAN"`
+	OtherID  string        `xml:"Acct>Id>Othr>Id"`
+	Currency string        `xml:"Acct>Ccy"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+type camtBalance struct {
+	Code        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtStatus is a plain code up to camt.053.001.02 and a choice element afterwards
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Code  string `xml:"Cd"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+type camtEntry struct {
+	Reference      string          `xml:"NtryRef"`
+	Amount         camtAmount      `xml:"Amt"`
+	CreditDebit    string          `xml:"CdtDbtInd"`
+	Reversal       bool            `xml:"RvslInd"`
+	Status         camtStatus      `xml:"Sts"`
+	BookingDate    camtDate        `xml:"BookgDt"`
+	ValueDate      camtDate        `xml:"ValDt"`
+	ServicerRef    string          `xml:"AcctSvcrRef"`
+	Transactions   []camtTxDetails `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string          `xml:"AddtlNtryInf"`
+}
+
+type camtTxDetails struct {
+	EndToEndID   string   `xml:"Refs>EndToEndId"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+	CreditorName string   `xml:"RltdPties>Cdtr>Nm"`
+	CreditorPty  string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+	DebtorName   string   `xml:"RltdPties>Dbtr>Nm"`
+	DebtorPty    string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+	AdditionalTx string   `xml:"AddtlTxInf"`
+}
+
+// ParseCamt053 parses an ISO 20022 camt.053 statement file
+func ParseCamt053(r io.Reader) ([]Statement, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid camt.053 document: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("invalid camt.053 document: no statements found")
+	}
+
+	statements := make([]Statement, 0, len(doc.Statements))
+	for _, stmt := range doc.Statements {
+		statement := Statement{
+			ID:       stmt.ID,
+			Account:  firstNonEmpty(stmt.IBAN, stmt.OtherID),
+			Currency: stmt.Currency,
+		}
+
+		for _, bal := range stmt.Balances {
+			amount, err := parseCamtAmount(bal.Amount.Value, bal.CreditDebit)
+			if err != nil {
+				return nil, fmt.Errorf("statement %s: balance: %w", stmt.ID, err)
+			}
+			switch bal.Code {
+			case "OPBD", "PRCD":
+				statement.OpeningBalance = &amount
+			case "CLBD":
+				statement.ClosingBalance = &amount
+			}
+			if statement.Currency == "" {
+				statement.Currency = bal.Amount.Currency
+			}
+		}
+
+		for i, ntry := range stmt.Entries {
+			entry, err := ntry.toEntry(statement.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("statement %s: entry %d: %w", stmt.ID, i+1, err)
+			}
+			statement.Entries = append(statement.Entries, entry)
+		}
+
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
+}
+
+func (n *camtEntry) toEntry(defaultCurrency string) (Entry, error) {
+	indicator := strings.TrimSpace(n.CreditDebit)
+	if indicator != Credit && indicator != Debit {
+		return Entry{}, fmt.Errorf("invalid credit/debit indicator %q", n.CreditDebit)
+	}
+
+	amount, err := parseCamtAmount(n.Amount.Value, indicator)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	bookingDate, err := n.BookingDate.parse()
+	if err != nil {
+		return Entry{}, fmt.Errorf("booking date: %w", err)
+	}
+	valueDate, err := n.ValueDate.parse()
+	if err != nil {
+		return Entry{}, fmt.Errorf("value date: %w", err)
+	}
+	if bookingDate.IsZero() {
+		bookingDate = valueDate
+	}
+	if valueDate.IsZero() {
+		valueDate = bookingDate
+	}
+	if bookingDate.IsZero() {
+		return Entry{}, fmt.Errorf("entry has no booking or value date")
+	}
+
+	status := firstNonEmpty(n.Status.Code, n.Status.Value)
+
+	entry := Entry{
+		BookingDate: bookingDate,
+		ValueDate:   valueDate,
+		Amount:      amount,
+		Currency:    firstNonEmpty(n.Amount.Currency, defaultCurrency),
+		CreditDebit: indicator,
+		Reversal:    n.Reversal,
+		Pending:     status == "PDNG",
+		BankRef:     n.ServicerRef,
+		Reference:   n.Reference,
+	}
+
+	var remittance []string
+	for _, tx := range n.Transactions {
+		remittance = append(remittance, tx.Unstructured...)
+		if tx.AdditionalTx != "" {
+			remittance = append(remittance, tx.AdditionalTx)
+		}
+		if entry.Reference == "" && tx.EndToEndID != "" && tx.EndToEndID != "NOTPROVIDED" {
+			entry.Reference = tx.EndToEndID
+		}
+		if entry.Counterpart == "" {
+			// The counterparty of a debit is the creditor and vice versa
+			if indicator == Debit {
+				entry.Counterpart = firstNonEmpty(tx.CreditorName, tx.CreditorPty)
+			} else {
+				entry.Counterpart = firstNonEmpty(tx.DebtorName, tx.DebtorPty)
+			}
+		}
+	}
+	if len(remittance) == 0 && n.AdditionalInfo != "" {
+		remittance = append(remittance, n.AdditionalInfo)
+	}
+	entry.Description = strings.Join(strings.Fields(strings.Join(remittance, " ")), " ")
+	if entry.Description == "" {
+		entry.Description = entry.Counterpart
+	}
+
+	return entry, nil
+}
+
+func (d camtDate) parse() (time.Time, error) {
+	switch {
+	case d.Date != "":
+		return time.Parse("2006-01-02", strings.TrimSpace(d.Date))
+	case d.DateTime != "":
+		value := strings.TrimSpace(d.DateTime)
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02T15:04:05", value)
+	default:
+		return time.Time{}, nil
+	}
+}
+
+func parseCamtAmount(value, indicator string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	if indicator == Debit {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
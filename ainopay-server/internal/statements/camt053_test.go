@@ -0,0 +1,103 @@
+package statements
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCamt053(t *testing.T) {
+	reversalTime := time.Date(2024, 3, 4, 14, 30, 0, 0, time.FixedZone("", 3600))
+
+	tests := []struct {
+		name    string
+		fixture string
+		want    []Statement
+		wantErr string
+	}{
+		{
+			name:    "multi-currency statements with a reversal and a pending entry",
+			fixture: "camt053_multi_currency.xml",
+			want: []Statement{
+				{
+					ID:             "EUR-2024-03-04",
+					Account:        "DE89370400440532013000",
+					Currency:       "EUR",
+					OpeningBalance: balance(1000),
+					ClosingBalance: balance(1225.50),
+					Entries: []Entry{
+						{
+							BookingDate: date("2024-03-04"),
+							ValueDate:   date("2024-03-04"),
+							Amount:      250,
+							Currency:    "EUR",
+							CreditDebit: Credit,
+							Description: "Invoice 1001 March",
+							Reference:   "E1",
+							BankRef:     "BANKREF-1",
+							Counterpart: "Acme GmbH",
+						},
+						{
+							// Without remittance information the counterparty describes the entry
+							BookingDate: date("2024-03-04"),
+							ValueDate:   date("2024-03-05"),
+							Amount:      -49.50,
+							Currency:    "EUR",
+							CreditDebit: Debit,
+							Description: "Telco AG",
+							Counterpart: "Telco AG",
+						},
+						{
+							BookingDate: reversalTime,
+							ValueDate:   reversalTime,
+							Amount:      25,
+							Currency:    "EUR",
+							CreditDebit: Credit,
+							Reversal:    true,
+							Description: "Reversal of card payment",
+							Reference:   "E3",
+						},
+					},
+				},
+				{
+					// The currency comes from the balance when the account has none
+					ID:             "USD-2024-03-04",
+					Account:        "987654321",
+					Currency:       "USD",
+					OpeningBalance: balance(-300),
+					Entries: []Entry{
+						{
+							BookingDate: date("2024-03-06"),
+							ValueDate:   date("2024-03-06"),
+							Amount:      -75.25,
+							Currency:    "USD",
+							CreditDebit: Debit,
+							Pending:     true,
+							Description: "Cloud hosting",
+							Reference:   "U1",
+						},
+					},
+				},
+			},
+		},
+		{name: "malformed XML", fixture: "camt053_malformed.xml", wantErr: "invalid camt.053 document"},
+		{name: "invalid credit/debit indicator", fixture: "camt053_invalid_indicator.xml", wantErr: `statement BAD-IND: entry 1: invalid credit/debit indicator "XXXX"`},
+		{name: "no statements", fixture: "camt053_no_statements.xml", wantErr: "no statements found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCamt053(openFixture(t, tt.fixture))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseCamt053() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCamt053() error = %v", err)
+			}
+			assertStatements(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package statements
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseCSV reads a statement exported as CSV. The first row must be a header
+// naming the date, description and amount columns; reference and currency are
+// optional. Dates use YYYY-MM-DD and negative amounts denote money leaving the
+// account.
+func ParseCSV(r io.Reader) ([]Statement, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrNoEntries
+		}
+		return nil, fmt.Errorf("invalid statement header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "description", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("statement is missing the %q column", required)
+		}
+	}
+
+	statement := Statement{}
+	for row := 2; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row, err)
+		}
+
+		field := func(name string) string {
+			col, ok := columns[name]
+			if ok && col < len(record) {
+				return strings.TrimSpace(record[col])
+			}
+			return ""
+		}
+
+		date, err := time.Parse("2006-01-02", field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid date %q", row, field("date"))
+		}
+		amount, err := strconv.ParseFloat(field("amount"), 64)
+		if err != nil || amount == 0 {
+			return nil, fmt.Errorf("line %d: invalid amount %q", row, field("amount"))
+		}
+
+		entry := Entry{
+			BookingDate: date,
+			ValueDate:   date,
+			Amount:      amount,
+			Currency:    strings.ToUpper(field("currency")),
+			CreditDebit: Credit,
+			Description: field("description"),
+			Reference:   field("reference"),
+		}
+		if amount < 0 {
+			entry.CreditDebit = Debit
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+
+	return []Statement{statement}, nil
+}
@@ -0,0 +1,89 @@
+package statements
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		input   string
+		want    []Entry
+		wantErr string
+	}{
+		{
+			name:    "multi-currency statement with a refund",
+			fixture: "statement.csv",
+			want: []Entry{
+				{
+					BookingDate: date("2024-03-04"),
+					ValueDate:   date("2024-03-04"),
+					Amount:      250,
+					Currency:    "EUR",
+					CreditDebit: Credit,
+					Description: "Invoice 1001 Acme GmbH",
+					Reference:   "INV-1001",
+				},
+				{
+					BookingDate: date("2024-03-04"),
+					ValueDate:   date("2024-03-04"),
+					Amount:      -49.50,
+					Currency:    "EUR",
+					CreditDebit: Debit,
+					Description: "Telco direct debit",
+				},
+				{
+					BookingDate: date("2024-03-05"),
+					ValueDate:   date("2024-03-05"),
+					Amount:      -75.25,
+					Currency:    "USD",
+					CreditDebit: Debit,
+					Description: "Cloud hosting",
+					Reference:   "HOST-3",
+				},
+				{
+					BookingDate: date("2024-03-06"),
+					ValueDate:   date("2024-03-06"),
+					Amount:      75.25,
+					Currency:    "USD",
+					CreditDebit: Credit,
+					Description: "Refund hosting",
+					Reference:   "HOST-3",
+				},
+			},
+		},
+		{name: "missing column", fixture: "csv_missing_column.csv", wantErr: `statement is missing the "description" column`},
+		{name: "invalid date", fixture: "csv_invalid_date.csv", wantErr: `line 3: invalid date "04/03/2024"`},
+		{name: "invalid amount", fixture: "csv_invalid_amount.csv", wantErr: `line 2: invalid amount "abc"`},
+		{name: "zero amount", fixture: "csv_zero_amount.csv", wantErr: `line 2: invalid amount "0"`},
+		{name: "unterminated quote", fixture: "csv_bad_quote.csv", wantErr: "line 2:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(openFixture(t, tt.fixture))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseCSV() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCSV() error = %v", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("got %d statements, want 1", len(got))
+			}
+			assertEntries(t, got[0].Entries, tt.want)
+		})
+	}
+}
+
+func TestParseCSVEmpty(t *testing.T) {
+	if _, err := ParseCSV(strings.NewReader("")); !errors.Is(err, ErrNoEntries) {
+		t.Errorf("ParseCSV() error = %v, want %v", err, ErrNoEntries)
+	}
+}
@@ -0,0 +1,253 @@
+package statements
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// :NN: or :NNa: at the start of a line opens a new field
+	mt940TagPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):(.*)$`)
+
+	// :61: value date, optional entry date, mark, optional funds code,
+	// amount, transaction type, customer reference and optional bank reference
+	mt940LinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([A-Z][A-Z0-9]{3})([^/\n]*)(?://([^\n]*))?(?:\n(.*))?$`)
+
+	// :60F:/:62F: mark, date, currency and amount
+	mt940BalancePattern = regexp.MustCompile(`^([CD])(\d{6})([A-Z]{3})(\d+,\d*)`)
+
+	// Structured :86: subfields such as ?20 (remittance) and ?32 (counterparty)
+	mt940SubfieldPattern = regexp.MustCompile(`\?(\d{2})`)
+)
+
+type mt940Field struct {
+	tag   string
+	value string
+}
+
+// ParseMT940 parses a SWIFT MT940 customer statement file. Files may hold
+// several statements, optionally wrapped in SWIFT FIN blocks.
+func ParseMT940(r io.Reader) ([]Statement, error) {
+	fields, err := readMT940Fields(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []Statement
+	var current *Statement
+	var last *Entry
+
+	for _, field := range fields {
+		switch field.tag {
+		case "20":
+			statements = append(statements, Statement{ID: strings.TrimSpace(field.value)})
+			current = &statements[len(statements)-1]
+			last = nil
+		case "25":
+			if current == nil {
+				return nil, fmt.Errorf("invalid MT940: :25: before :20:")
+			}
+			current.Account = strings.TrimSpace(field.value)
+		case "60F", "60M":
+			if current == nil {
+				return nil, fmt.Errorf("invalid MT940: :%s: before :20:", field.tag)
+			}
+			amount, currency, err := parseMT940Balance(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("statement %s: opening balance: %w", current.ID, err)
+			}
+			current.Currency = currency
+			if current.OpeningBalance == nil {
+				current.OpeningBalance = &amount
+			}
+		case "62F", "62M":
+			if current == nil {
+				return nil, fmt.Errorf("invalid MT940: :%s: before :20:", field.tag)
+			}
+			amount, _, err := parseMT940Balance(field.value)
+			if err != nil {
+				return nil, fmt.Errorf("statement %s: closing balance: %w", current.ID, err)
+			}
+			current.ClosingBalance = &amount
+		case "61":
+			if current == nil {
+				return nil, fmt.Errorf("invalid MT940: :61: before :20:")
+			}
+			entry, err := parseMT940Line(field.value, current.Currency)
+			if err != nil {
+				return nil, fmt.Errorf("statement %s: entry %d: %w", current.ID, len(current.Entries)+1, err)
+			}
+			current.Entries = append(current.Entries, entry)
+			last = &current.Entries[len(current.Entries)-1]
+		case "86":
+			// Information to account owner belongs to the preceding :61:
+			if last != nil {
+				description, counterparty := parseMT940Information(field.value)
+				// Keep the :61: supplementary details when :86: has no remittance text
+				if description != "" {
+					last.Description = description
+				}
+				if counterparty != "" {
+					last.Counterpart = counterparty
+				}
+				last = nil
+			}
+		}
+	}
+
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("invalid MT940: no statements found")
+	}
+	return statements, nil
+}
+
+// readMT940Fields splits the file into tagged fields, joining continuation
+// lines and dropping the SWIFT block wrappers
+func readMT940Fields(r io.Reader) ([]mt940Field, error) {
+	var fields []mt940Field
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r ")
+
+		if strings.HasPrefix(line, "{") {
+			// Header blocks; the text block {4: may carry the first field inline
+			if idx := strings.Index(line, "{4:"); idx >= 0 {
+				line = line[idx+3:]
+			} else {
+				continue
+			}
+		}
+		if line == "" || line == "-" || line == "-}" || strings.HasPrefix(line, "-}") {
+			continue
+		}
+
+		if m := mt940TagPattern.FindStringSubmatch(line); m != nil {
+			fields = append(fields, mt940Field{tag: m[1], value: m[2]})
+			continue
+		}
+		if len(fields) > 0 {
+			fields[len(fields)-1].value += "\n" + line
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func parseMT940Line(value, currency string) (Entry, error) {
+	m := mt940LinePattern.FindStringSubmatch(value)
+	if m == nil {
+		return Entry{}, fmt.Errorf("invalid :61: statement line %q", strings.SplitN(value, "\n", 2)[0])
+	}
+
+	valueDate, err := time.Parse("060102", m[1])
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid value date %q", m[1])
+	}
+
+	bookingDate := valueDate
+	if m[2] != "" {
+		bookingDate, err = time.Parse("20060102", fmt.Sprintf("%04d%s", valueDate.Year(), m[2]))
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid entry date %q", m[2])
+		}
+		// The entry date carries no year; bookings around new year can fall
+		// in the neighbouring year of the value date
+		if bookingDate.Sub(valueDate) > 180*24*time.Hour {
+			bookingDate = bookingDate.AddDate(-1, 0, 0)
+		} else if valueDate.Sub(bookingDate) > 180*24*time.Hour {
+			bookingDate = bookingDate.AddDate(1, 0, 0)
+		}
+	}
+
+	amount, err := parseMT940Amount(m[5])
+	if err != nil {
+		return Entry{}, err
+	}
+
+	// RC reverses a credit (money leaves the account), RD reverses a debit
+	entry := Entry{
+		BookingDate: bookingDate,
+		ValueDate:   valueDate,
+		Currency:    currency,
+		Reversal:    m[3] == "RC" || m[3] == "RD",
+		Reference:   strings.TrimSpace(m[7]),
+		BankRef:     strings.TrimSpace(m[8]),
+	}
+	if entry.Reference == "NONREF" {
+		entry.Reference = ""
+	}
+
+	switch m[3] {
+	case "D", "RC":
+		entry.CreditDebit = Debit
+		entry.Amount = -amount
+	default:
+		entry.CreditDebit = Credit
+		entry.Amount = amount
+	}
+
+	// Supplementary details on the second line serve as a fallback description
+	entry.Description = strings.TrimSpace(m[9])
+
+	return entry, nil
+}
+
+func parseMT940Balance(value string) (float64, string, error) {
+	m := mt940BalancePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, "", fmt.Errorf("invalid balance %q", value)
+	}
+	amount, err := parseMT940Amount(m[4])
+	if err != nil {
+		return 0, "", err
+	}
+	if m[1] == "D" {
+		amount = -amount
+	}
+	return amount, m[3], nil
+}
+
+// parseMT940Amount parses amounts that use a comma as decimal separator
+func parseMT940Amount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+// parseMT940Information returns the description and counterparty of a :86:
+// field. Structured fields (?20-?29 remittance, ?32/?33 name) are decoded;
+// anything else is used as free text.
+func parseMT940Information(value string) (string, string) {
+	joined := strings.ReplaceAll(value, "\n", "")
+	if !strings.Contains(joined, "?2") {
+		return strings.Join(strings.Fields(value), " "), ""
+	}
+
+	var remittance, name []string
+	indexes := mt940SubfieldPattern.FindAllStringSubmatchIndex(joined, -1)
+	for i, idx := range indexes {
+		end := len(joined)
+		if i+1 < len(indexes) {
+			end = indexes[i+1][0]
+		}
+		code := joined[idx[2]:idx[3]]
+		text := strings.TrimSpace(joined[idx[1]:end])
+		switch {
+		case code >= "20" && code <= "29", code >= "60" && code <= "63":
+			remittance = append(remittance, text)
+		case code == "32" || code == "33":
+			name = append(name, text)
+		}
+	}
+
+	return strings.Join(strings.Fields(strings.Join(remittance, " ")), " "), strings.Join(name, "")
+}
@@ -0,0 +1,117 @@
+package statements
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMT940(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		input   string
+		want    []Statement
+		wantErr string
+	}{
+		{
+			name:    "multi-currency statements with reversals",
+			fixture: "mt940_multi_currency.sta",
+			want: []Statement{
+				{
+					ID:             "STMT-EUR-0304",
+					Account:        "DE89370400440532013000",
+					Currency:       "EUR",
+					OpeningBalance: balance(1000),
+					ClosingBalance: balance(1175.50),
+					Entries: []Entry{
+						{
+							// Structured :86: with remittance and counterparty subfields
+							BookingDate: date("2024-03-04"),
+							ValueDate:   date("2024-03-04"),
+							Amount:      250,
+							Currency:    "EUR",
+							CreditDebit: Credit,
+							Description: "Invoice 1001 March",
+							Reference:   "INV-1001",
+							BankRef:     "BANKREF-1",
+							Counterpart: "Acme GmbH",
+						},
+						{
+							// An empty :86: keeps the :61: supplementary details
+							BookingDate: date("2024-03-04"),
+							ValueDate:   date("2024-03-04"),
+							Amount:      -49.50,
+							Currency:    "EUR",
+							CreditDebit: Debit,
+							Description: "Telco direct debit",
+						},
+						{
+							// RC reverses a credit, so money leaves the account
+							BookingDate: date("2024-03-04"),
+							ValueDate:   date("2024-03-04"),
+							Amount:      -25,
+							Currency:    "EUR",
+							CreditDebit: Debit,
+							Reversal:    true,
+							Description: "Reversal of card payment",
+							Reference:   "CARD-77",
+						},
+					},
+				},
+				{
+					ID:             "STMT-USD-0304",
+					Account:        "987654321",
+					Currency:       "USD",
+					OpeningBalance: balance(-300),
+					ClosingBalance: balance(-234.75),
+					Entries: []Entry{
+						{
+							// RD reverses a debit; the entry date falls in the previous year
+							BookingDate: date("2023-12-29"),
+							ValueDate:   date("2024-01-03"),
+							Amount:      75.25,
+							Currency:    "USD",
+							CreditDebit: Credit,
+							Reversal:    true,
+							Description: "Reversed hosting charge",
+							Reference:   "HOSTING",
+						},
+						{
+							BookingDate: date("2024-03-04"),
+							ValueDate:   date("2024-03-04"),
+							Amount:      -10,
+							Currency:    "USD",
+							CreditDebit: Debit,
+						},
+					},
+				},
+			},
+		},
+		{name: "invalid statement line", fixture: "mt940_invalid_line.sta", wantErr: "statement STMT-BAD: entry 1: invalid :61: statement line"},
+		{name: "field before :20:", fixture: "mt940_missing_reference.sta", wantErr: ":25: before :20:"},
+		{name: "invalid balance", fixture: "mt940_invalid_balance.sta", wantErr: "statement STMT-BAD-BAL: opening balance: invalid balance"},
+		{name: "no statements", input: "just some text\n", wantErr: "no statements found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Statement
+			var err error
+			if tt.fixture != "" {
+				got, err = ParseMT940(openFixture(t, tt.fixture))
+			} else {
+				got, err = ParseMT940(strings.NewReader(tt.input))
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseMT940() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMT940() error = %v", err)
+			}
+			assertStatements(t, got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package statements parses bank statements delivered in the formats our banks
+// use (ISO 20022 camt.053, SWIFT MT940 and plain CSV) into a normalized list of
+// entries.
+package statements
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Supported statement formats
+const (
+	FormatCamt053 = "camt053"
+	FormatMT940   = "mt940"
+	FormatCSV     = "csv"
+)
+
+// Credit/debit indicators, using the ISO 20022 codes
+const (
+	Credit = "CRDT"
+	Debit  = "DBIT"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown statement format")
+	ErrNoEntries     = errors.New("statement contains no entries")
+)
+
+// Entry is a single booked (or pending) line of a bank statement.
+// Amount is signed: positive for money received, negative for money paid out.
+// Reversal marks a booking that cancels an earlier entry; its sign already
+// reflects the direction of the reversal itself.
+type Entry struct {
+	BookingDate time.Time `json:"booking_date"`
+	ValueDate   time.Time `json:"value_date"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	CreditDebit string    `json:"credit_debit"` // CRDT, DBIT
+	Reversal    bool      `json:"reversal"`
+	Pending     bool      `json:"pending"`
+	Description string    `json:"description"`
+	Reference   string    `json:"reference"`
+	BankRef     string    `json:"bank_reference,omitempty"`
+	Counterpart string    `json:"counterparty,omitempty"`
+}
+
+// IsDebit reports whether money left the account
+func (e *Entry) IsDebit() bool {
+	return e.CreditDebit == Debit
+}
+
+// Statement is one account statement together with its entries
+type Statement struct {
+	ID             string   `json:"id"`
+	Account        string   `json:"account"`
+	Currency       string   `json:"currency"`
+	OpeningBalance *float64 `json:"opening_balance,omitempty"`
+	ClosingBalance *float64 `json:"closing_balance,omitempty"`
+	Entries        []Entry  `json:"entries"`
+}
+
+// Parse reads statements in the given format. An empty format is detected
+// from the content.
+func Parse(format string, r io.Reader) ([]Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		format = DetectFormat(data)
+	}
+
+	var statements []Statement
+	switch strings.ToLower(format) {
+	case FormatCamt053:
+		statements, err = ParseCamt053(bytes.NewReader(data))
+	case FormatMT940:
+		statements, err = ParseMT940(bytes.NewReader(data))
+	case FormatCSV:
+		statements, err = ParseCSV(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(Entries(statements)) == 0 {
+		return nil, ErrNoEntries
+	}
+	return statements, nil
+}
+
+// DetectFormat guesses the statement format from its content
+func DetectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return FormatCamt053
+	case bytes.Contains(trimmed, []byte(":20:")) && bytes.Contains(trimmed, []byte(":61:")):
+		return FormatMT940
+	default:
+		return FormatCSV
+	}
+}
+
+// Entries flattens the entries of all statements
+func Entries(statements []Statement) []Entry {
+	var entries []Entry
+	for _, s := range statements {
+		entries = append(entries, s.Entries...)
+	}
+	return entries
+}
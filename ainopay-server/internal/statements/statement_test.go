@@ -0,0 +1,151 @@
+package statements
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openFixture opens a statement file of testdata
+func openFixture(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("open fixture: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func date(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func balance(amount float64) *float64 {
+	return &amount
+}
+
+// assertStatements compares statements field by field, dates by instant
+func assertStatements(t *testing.T, got, want []Statement) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d statements, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.ID != w.ID || g.Account != w.Account || g.Currency != w.Currency {
+			t.Errorf("statement %d: got id %q account %q currency %q, want %q %q %q", i, g.ID, g.Account, g.Currency, w.ID, w.Account, w.Currency)
+		}
+		assertBalance(t, "statement "+w.ID+" opening balance", g.OpeningBalance, w.OpeningBalance)
+		assertBalance(t, "statement "+w.ID+" closing balance", g.ClosingBalance, w.ClosingBalance)
+		assertEntries(t, g.Entries, w.Entries)
+	}
+}
+
+func assertBalance(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s: got %v, want %v", name, got, want)
+	case *got != *want:
+		t.Errorf("%s: got %v, want %v", name, *got, *want)
+	}
+}
+
+func assertEntries(t *testing.T, got, want []Entry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if !g.BookingDate.Equal(w.BookingDate) || !g.ValueDate.Equal(w.ValueDate) {
+			t.Errorf("entry %d: got booking %v value %v, want %v %v", i+1, g.BookingDate, g.ValueDate, w.BookingDate, w.ValueDate)
+		}
+		g.BookingDate, g.ValueDate = w.BookingDate, w.ValueDate
+		if g != w {
+			t.Errorf("entry %d:\n got  %+v\n want %+v", i+1, g, w)
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{"camt053_multi_currency.xml", FormatCamt053},
+		{"mt940_multi_currency.sta", FormatMT940},
+		{"statement.csv", FormatCSV},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := DetectFormat(data); got != tt.want {
+				t.Errorf("DetectFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		fixture     string
+		input       string
+		wantEntries int
+		wantErr     error
+		wantErrText string
+	}{
+		{name: "detects camt.053", fixture: "camt053_multi_currency.xml", wantEntries: 4},
+		{name: "detects MT940", fixture: "mt940_multi_currency.sta", wantEntries: 5},
+		{name: "detects CSV", fixture: "statement.csv", wantEntries: 4},
+		{name: "explicit format is case insensitive", format: "MT940", fixture: "mt940_multi_currency.sta", wantEntries: 5},
+		{name: "unknown format", format: "ofx", input: "anything", wantErr: ErrUnknownFormat},
+		{name: "no entries", input: "date,description,amount\n", wantErr: ErrNoEntries},
+		{name: "malformed camt.053", fixture: "camt053_malformed.xml", wantErrText: "invalid camt.053 document"},
+		{name: "wrong explicit format", format: FormatCamt053, fixture: "statement.csv", wantErrText: "invalid camt.053 document"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var statements []Statement
+			var err error
+			if tt.fixture != "" {
+				statements, err = Parse(tt.format, openFixture(t, tt.fixture))
+			} else {
+				statements, err = Parse(tt.format, strings.NewReader(tt.input))
+			}
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.wantErrText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Fatalf("Parse() error = %v, want it to contain %q", err, tt.wantErrText)
+				}
+			case err != nil:
+				t.Fatalf("Parse() error = %v", err)
+			default:
+				if got := len(Entries(statements)); got != tt.wantEntries {
+					t.Errorf("got %d entries, want %d", got, tt.wantEntries)
+				}
+			}
+		})
+	}
+}
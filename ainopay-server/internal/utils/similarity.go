@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeDescription lowercases a description and collapses everything that
+// is not a letter or digit into single spaces
+func NormalizeDescription(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// DescriptionSimilarity returns the Sørensen–Dice coefficient of the character
+// bigrams of two normalized descriptions, from 0 (nothing shared) to 1 (equal).
+// Bigrams tolerate the truncation and reference noise typical of bank statements.
+func DescriptionSimilarity(a, b string) float64 {
+	a, b = NormalizeDescription(a), NormalizeDescription(b)
+	if a == "" && b == "" {
+		return 1
+	}
+	if a == b {
+		return 1
+	}
+
+	aBigrams := bigrams(a)
+	bBigrams := bigrams(b)
+	if len(aBigrams) == 0 || len(bBigrams) == 0 {
+		return 0
+	}
+
+	counts := make(map[string]int, len(aBigrams))
+	for _, bg := range aBigrams {
+		counts[bg]++
+	}
+
+	shared := 0
+	for _, bg := range bBigrams {
+		if counts[bg] > 0 {
+			counts[bg]--
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(aBigrams)+len(bBigrams))
+}
+
+func bigrams(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 2 {
+		return nil
+	}
+	result := make([]string, 0, len(runes)-1)
+	for i := 0; i < len(runes)-1; i++ {
+		result = append(result, string(runes[i:i+2]))
+	}
+	return result
+}